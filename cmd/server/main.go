@@ -12,6 +12,7 @@ import (
 	"github.com/hascho/go-incident-dashboard-api/internal/db"
 	"github.com/hascho/go-incident-dashboard-api/internal/handler"
 	"github.com/hascho/go-incident-dashboard-api/internal/middleware"
+	"github.com/hascho/go-incident-dashboard-api/internal/probe"
 	"github.com/hascho/go-incident-dashboard-api/internal/queue"
 	"github.com/hascho/go-incident-dashboard-api/internal/repository"
 	"github.com/hascho/go-incident-dashboard-api/internal/service"
@@ -40,18 +41,171 @@ func main() {
 	r.Use(gin.Recovery())
 	r.Use(middleware.RequestID())
 	r.Use(middleware.LoggerMiddleware(logger))
+	r.Use(middleware.Actor())
 
 	incidentRepo := repository.NewIncidentRepository(dbConn)
 	jobRepo := repository.NewJobRepository(dbConn)
+	transitionRepo := repository.NewTransitionRepository(dbConn)
+	eventRepo := repository.NewEventRepository(dbConn)
+	attemptRepo := repository.NewAttemptRepository(dbConn)
+	webhookRepo := repository.NewWebhookRepository(dbConn)
+	routingRepo := repository.NewRoutingRuleRepository(dbConn)
+	escalationRepo := repository.NewEscalationRepository(dbConn)
+	scheduleRepo := repository.NewScheduleRepository(dbConn)
+	responderRepo := repository.NewResponderRepository(dbConn)
+	teamRepo := repository.NewTeamRepository(dbConn)
+	userRepo := repository.NewUserRepository(dbConn)
+	alertRepo := repository.NewAlertRepository(dbConn)
+	integrationRepo := repository.NewIntegrationRepository(dbConn)
+	heartbeatRepo := repository.NewHeartbeatRepository(dbConn)
+	syntheticCheckRepo := repository.NewSyntheticCheckRepository(dbConn)
+	transactor := repository.NewTransactor(dbConn)
+
+	notificationTarget := repository.JobTarget{
+		Channel:     getEnv("NOTIFICATION_CHANNEL", "log"),
+		Destination: os.Getenv("NOTIFICATION_DESTINATION"),
+	}
+
+	groupingWindow, err := time.ParseDuration(getEnv("INCIDENT_GROUPING_WINDOW", "30m"))
+	if err != nil || groupingWindow < 0 {
+		logger.Fatal().Err(err).Msg("INCIDENT_GROUPING_WINDOW must be a non-negative duration such as 30m")
+	}
 
-	incidentService := service.NewIncidentService(incidentRepo, logger, jobRepo, taskQueue)
+	incidentService := service.NewIncidentService(incidentRepo, logger, jobRepo, transitionRepo, eventRepo, webhookRepo, routingRepo, escalationRepo, scheduleRepo, teamRepo, transactor, taskQueue, notificationTarget, groupingWindow)
 	incidentHandler := handler.NewIncidentHandler(incidentService)
 
+	timelineService := service.NewTimelineService(incidentRepo, eventRepo, logger)
+	timelineHandler := handler.NewTimelineHandler(timelineService)
+
+	jobService := service.NewJobService(jobRepo, incidentRepo, attemptRepo, transactor, taskQueue, logger)
+	jobHandler := handler.NewJobHandler(jobService)
+
+	webhookService := service.NewWebhookService(webhookRepo, logger)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+
+	routingService := service.NewRoutingService(routingRepo, teamRepo, logger)
+	routingHandler := handler.NewRoutingHandler(routingService)
+
+	escalationService := service.NewEscalationService(escalationRepo, incidentRepo, jobRepo, eventRepo, webhookRepo, scheduleRepo, teamRepo, transactor, taskQueue, logger)
+	escalationHandler := handler.NewEscalationHandler(escalationService)
+
+	scheduleService := service.NewScheduleService(scheduleRepo, teamRepo, logger)
+	scheduleHandler := handler.NewScheduleHandler(scheduleService)
+
+	responderService := service.NewResponderService(responderRepo, incidentRepo, jobRepo, eventRepo, webhookRepo, transactor, taskQueue, logger)
+	responderHandler := handler.NewResponderHandler(responderService)
+
+	teamService := service.NewTeamService(teamRepo, userRepo, logger)
+	teamHandler := handler.NewTeamHandler(teamService)
+
+	userService := service.NewUserService(userRepo, logger)
+	userHandler := handler.NewUserHandler(userService)
+
+	alertRules, err := service.ParseAlertRules(os.Getenv("ALERTMANAGER_RULES"))
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid ALERTMANAGER_RULES")
+	}
+	alertService := service.NewAlertService(alertRepo, incidentRepo, integrationRepo, incidentService, transactor, alertRules, os.Getenv("ALERTMANAGER_DEFAULT_TEAM"), logger)
+	alertHandler := handler.NewAlertHandler(alertService)
+
+	integrationService := service.NewIntegrationService(integrationRepo, teamRepo, logger)
+	integrationHandler := handler.NewIntegrationHandler(integrationService)
+
+	// overdue heartbeats are checked by cmd/worker; the API only records pings
+	heartbeatService := service.NewHeartbeatService(heartbeatRepo, teamRepo, incidentService, transactor, logger)
+	heartbeatHandler := handler.NewHeartbeatHandler(heartbeatService)
+
+	// synthetic checks are run by cmd/worker; the API only manages them
+	syntheticCheckService := service.NewSyntheticCheckService(syntheticCheckRepo, teamRepo, incidentService, transactor, probe.NewProber(), logger)
+	syntheticCheckHandler := handler.NewSyntheticCheckHandler(syntheticCheckService)
+
 	r.GET("/incidents", incidentHandler.GetAllIncidents)
+	r.GET("/incidents/stats/mtta", incidentHandler.GetMTTAStats)
+	r.GET("/incidents/search", incidentHandler.SearchIncidents)
 	r.GET("/incidents/:id", incidentHandler.GetIncidentByID)
 	r.POST("/incidents", incidentHandler.CreateIncident)
 	r.PATCH("/incidents/:id", incidentHandler.PatchIncident)
 	r.DELETE("/incidents/:id", incidentHandler.DeleteIncident)
+	r.POST("/incidents/:id/acknowledge", incidentHandler.AcknowledgeIncident)
+	r.GET("/incidents/:id/timeline", timelineHandler.GetTimeline)
+	r.POST("/incidents/:id/notes", timelineHandler.AddNote)
+	r.GET("/incidents/:id/notifications", jobHandler.GetIncidentNotifications)
+	r.GET("/incidents/:id/responders", responderHandler.GetResponders)
+	r.POST("/incidents/:id/responders", responderHandler.AssignResponder)
+	r.DELETE("/incidents/:id/responders/:responder_id", responderHandler.RemoveResponder)
+
+	r.GET("/webhooks", webhookHandler.GetAllWebhooks)
+	r.GET("/webhooks/:id", webhookHandler.GetWebhookByID)
+	r.POST("/webhooks", webhookHandler.CreateWebhook)
+	r.DELETE("/webhooks/:id", webhookHandler.DeleteWebhook)
+
+	r.GET("/routing-rules", routingHandler.GetAllRoutingRules)
+	r.GET("/routing-rules/:id", routingHandler.GetRoutingRuleByID)
+	r.POST("/routing-rules", routingHandler.CreateRoutingRule)
+	r.PUT("/routing-rules/:id", routingHandler.UpdateRoutingRule)
+	r.DELETE("/routing-rules/:id", routingHandler.DeleteRoutingRule)
+
+	r.GET("/escalation-policies", escalationHandler.GetAllEscalationPolicies)
+	r.GET("/escalation-policies/:id", escalationHandler.GetEscalationPolicyByID)
+	r.POST("/escalation-policies", escalationHandler.CreateEscalationPolicy)
+	r.PUT("/escalation-policies/:id", escalationHandler.UpdateEscalationPolicy)
+	r.DELETE("/escalation-policies/:id", escalationHandler.DeleteEscalationPolicy)
+
+	r.GET("/schedules", scheduleHandler.GetAllSchedules)
+	r.GET("/schedules/:id", scheduleHandler.GetScheduleByID)
+	r.POST("/schedules", scheduleHandler.CreateSchedule)
+	r.PUT("/schedules/:id", scheduleHandler.UpdateSchedule)
+	r.DELETE("/schedules/:id", scheduleHandler.DeleteSchedule)
+	r.GET("/schedules/:id/oncall", scheduleHandler.GetOnCall)
+	r.GET("/schedules/:id/overrides", scheduleHandler.GetOverrides)
+	r.POST("/schedules/:id/overrides", scheduleHandler.CreateOverride)
+	r.DELETE("/schedules/:id/overrides/:override_id", scheduleHandler.DeleteOverride)
+
+	r.GET("/teams", teamHandler.GetAllTeams)
+	r.GET("/teams/:id", teamHandler.GetTeamByID)
+	r.POST("/teams", teamHandler.CreateTeam)
+	r.PUT("/teams/:id", teamHandler.UpdateTeam)
+	r.DELETE("/teams/:id", teamHandler.DeleteTeam)
+	r.GET("/teams/:id/members", teamHandler.GetMembers)
+	r.POST("/teams/:id/members", teamHandler.AddMember)
+	r.DELETE("/teams/:id/members/:user_id", teamHandler.RemoveMember)
+
+	r.GET("/users", userHandler.GetAllUsers)
+	r.GET("/users/:id", userHandler.GetUserByID)
+	r.POST("/users", userHandler.CreateUser)
+	r.PUT("/users/:id", userHandler.UpdateUser)
+	r.DELETE("/users/:id", userHandler.DeleteUser)
+
+	r.GET("/integrations", integrationHandler.GetAllIntegrations)
+	r.GET("/integrations/:id", integrationHandler.GetIntegrationByID)
+	r.POST("/integrations", integrationHandler.CreateIntegration)
+	r.PUT("/integrations/:id", integrationHandler.UpdateIntegration)
+	r.DELETE("/integrations/:id", integrationHandler.DeleteIntegration)
+	r.POST("/integrations/:id/token", integrationHandler.RotateToken)
+	r.POST("/integrations/:id/events", alertHandler.IngestEvent)
+	r.POST("/integrations/alertmanager", alertHandler.IngestAlertmanager)
+
+	r.GET("/heartbeats", heartbeatHandler.GetAllHeartbeats)
+	r.GET("/heartbeats/:id", heartbeatHandler.GetHeartbeatByID)
+	r.POST("/heartbeats", heartbeatHandler.CreateHeartbeat)
+	r.PUT("/heartbeats/:id", heartbeatHandler.UpdateHeartbeat)
+	r.DELETE("/heartbeats/:id", heartbeatHandler.DeleteHeartbeat)
+	r.POST("/heartbeats/:id/ping", heartbeatHandler.Ping)
+
+	r.GET("/synthetic-checks", syntheticCheckHandler.GetAllSyntheticChecks)
+	r.GET("/synthetic-checks/:id", syntheticCheckHandler.GetSyntheticCheckByID)
+	r.POST("/synthetic-checks", syntheticCheckHandler.CreateSyntheticCheck)
+	r.PUT("/synthetic-checks/:id", syntheticCheckHandler.UpdateSyntheticCheck)
+	r.DELETE("/synthetic-checks/:id", syntheticCheckHandler.DeleteSyntheticCheck)
+	r.GET("/synthetic-checks/:id/results", syntheticCheckHandler.GetResults)
+
+	admin := r.Group("/admin")
+	admin.GET("/jobs/dead-letter", jobHandler.ListDeadLetteredJobs)
+	admin.GET("/jobs/:id", jobHandler.GetJob)
+	admin.POST("/jobs/requeue", jobHandler.RequeueJobs)
+	admin.POST("/jobs/discard", jobHandler.DiscardJobs)
+	admin.POST("/jobs/:id/requeue", jobHandler.RequeueJob)
+	admin.POST("/jobs/:id/discard", jobHandler.DiscardJob)
 
 	r.GET("/", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "API is operational"})
@@ -86,3 +240,11 @@ func main() {
 
 	logger.Info().Msg("Server exiting.")
 }
+
+// getEnv returns the value of an environment variable, or fallback when it is unset
+func getEnv(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -2,14 +2,20 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/hascho/go-incident-dashboard-api/internal/db"
+	"github.com/hascho/go-incident-dashboard-api/internal/notifier"
+	"github.com/hascho/go-incident-dashboard-api/internal/probe"
 	"github.com/hascho/go-incident-dashboard-api/internal/queue"
 	"github.com/hascho/go-incident-dashboard-api/internal/repository"
+	"github.com/hascho/go-incident-dashboard-api/internal/service"
 	"github.com/hascho/go-incident-dashboard-api/internal/worker"
 	"github.com/rs/zerolog"
 )
@@ -30,8 +36,47 @@ func main() {
 
 	jobRepo := repository.NewJobRepository(dbConn)
 	incidentRepo := repository.NewIncidentRepository(dbConn)
+	eventRepo := repository.NewEventRepository(dbConn)
+	attemptRepo := repository.NewAttemptRepository(dbConn)
+	webhookRepo := repository.NewWebhookRepository(dbConn)
+	escalationRepo := repository.NewEscalationRepository(dbConn)
+	scheduleRepo := repository.NewScheduleRepository(dbConn)
+	transitionRepo := repository.NewTransitionRepository(dbConn)
+	routingRepo := repository.NewRoutingRuleRepository(dbConn)
+	teamRepo := repository.NewTeamRepository(dbConn)
+	heartbeatRepo := repository.NewHeartbeatRepository(dbConn)
+	syntheticCheckRepo := repository.NewSyntheticCheckRepository(dbConn)
+	transactor := repository.NewTransactor(dbConn)
 
-	notificationWorker := worker.NewNotificationWorker(jobRepo, incidentRepo, taskQueue, logger, "worker-01")
+	dashboardURL := getEnv("DASHBOARD_URL", "http://localhost:8080")
+	notifiers := notifier.NewRegistry(
+		notifier.NewLogNotifier(logger),
+		notifier.NewSlackNotifier(os.Getenv("SLACK_WEBHOOK_URL"), dashboardURL),
+		notifier.NewEmailNotifier(emailConfig(dashboardURL, logger)),
+		notifier.NewWebhookNotifier(webhookRepo),
+		notifier.NewPagerDutyNotifier(incidentRepo, os.Getenv("PAGERDUTY_EVENTS_URL"), os.Getenv("PAGERDUTY_ROUTING_KEY"), dashboardURL),
+	)
+
+	notificationWorker := worker.NewNotificationWorker(jobRepo, incidentRepo, eventRepo, attemptRepo, notifiers, taskQueue, logger, workerID(), retryPolicies(logger))
+
+	escalationService := service.NewEscalationService(escalationRepo, incidentRepo, jobRepo, eventRepo, webhookRepo, scheduleRepo, teamRepo, transactor, taskQueue, logger)
+	escalationScheduler := worker.NewEscalationScheduler(escalationService, logger)
+
+	// missed heartbeats and failing synthetic checks open incidents exactly as
+	// the API would, so the worker needs the API's fallback target and grouping window
+	notificationTarget := repository.JobTarget{
+		Channel:     getEnv("NOTIFICATION_CHANNEL", "log"),
+		Destination: os.Getenv("NOTIFICATION_DESTINATION"),
+	}
+	groupingWindow, err := time.ParseDuration(getEnv("INCIDENT_GROUPING_WINDOW", "30m"))
+	if err != nil || groupingWindow < 0 {
+		logger.Fatal().Err(err).Msg("INCIDENT_GROUPING_WINDOW must be a non-negative duration such as 30m")
+	}
+	incidentService := service.NewIncidentService(incidentRepo, logger, jobRepo, transitionRepo, eventRepo, webhookRepo, routingRepo, escalationRepo, scheduleRepo, teamRepo, transactor, taskQueue, notificationTarget, groupingWindow)
+	heartbeatService := service.NewHeartbeatService(heartbeatRepo, teamRepo, incidentService, transactor, logger)
+	heartbeatChecker := worker.NewHeartbeatChecker(heartbeatService, logger)
+	syntheticCheckService := service.NewSyntheticCheckService(syntheticCheckRepo, teamRepo, incidentService, transactor, probe.NewProber(), logger)
+	syntheticCheckScheduler := worker.NewSyntheticCheckScheduler(syntheticCheckService, logger)
 
 	// The Heartbeat (Polling Loop)
 	ctx, cancel := context.WithCancel(context.Background())
@@ -42,6 +87,9 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
 	go notificationWorker.Start(ctx)
+	go escalationScheduler.Start(ctx)
+	go heartbeatChecker.Start(ctx)
+	go syntheticCheckScheduler.Start(ctx)
 
 	// Wait for someone to kill the process (Ctrl+C)
 	<-quit
@@ -52,3 +100,91 @@ func main() {
 	time.Sleep(2 * time.Second)
 	logger.Info().Msg("Worker exited.")
 }
+
+// workerID identifies this replica in job leases. It must be unique per running
+// worker, so it defaults to hostname and PID unless WORKER_ID is set.
+func workerID() string {
+	if id := os.Getenv("WORKER_ID"); id != "" {
+		return id
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "worker"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// retryPolicies starts from the built-in policy per job type and applies any
+// overrides from RETRY_<TYPE>_MAX_ATTEMPTS, RETRY_<TYPE>_BASE_DELAY and
+// RETRY_<TYPE>_MAX_DELAY, e.g. RETRY_WEBHOOK_MAX_ATTEMPTS=20
+func retryPolicies(logger zerolog.Logger) map[string]worker.RetryPolicy {
+	policies := worker.DefaultRetryPolicies()
+	for jobType, policy := range policies {
+		prefix := "RETRY_" + strings.ToUpper(jobType) + "_"
+
+		if raw := os.Getenv(prefix + "MAX_ATTEMPTS"); raw != "" {
+			attempts, err := strconv.Atoi(raw)
+			if err != nil || attempts < 1 {
+				logger.Fatal().Err(err).Msg(prefix + "MAX_ATTEMPTS must be a positive number")
+			}
+			policy.MaxAttempts = attempts
+		}
+		for _, setting := range []struct {
+			name  string
+			delay *time.Duration
+		}{
+			{"BASE_DELAY", &policy.BaseDelay},
+			{"MAX_DELAY", &policy.MaxDelay},
+		} {
+			raw := os.Getenv(prefix + setting.name)
+			if raw == "" {
+				continue
+			}
+			delay, err := time.ParseDuration(raw)
+			if err != nil || delay <= 0 {
+				logger.Fatal().Err(err).Msg(prefix + setting.name + " must be a positive duration such as 30s")
+			}
+			*setting.delay = delay
+		}
+		if policy.MaxDelay < policy.BaseDelay {
+			logger.Fatal().Msg(prefix + "MAX_DELAY must not be shorter than " + prefix + "BASE_DELAY")
+		}
+
+		policies[jobType] = policy
+	}
+	return policies
+}
+
+// emailConfig reads the SMTP relay settings from the environment
+func emailConfig(dashboardURL string, logger zerolog.Logger) notifier.EmailConfig {
+	port, err := strconv.Atoi(getEnv("SMTP_PORT", "587"))
+	if err != nil {
+		logger.Fatal().Err(err).Msg("SMTP_PORT must be a number")
+	}
+
+	var to []string
+	for _, addr := range strings.Split(os.Getenv("SMTP_TO"), ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			to = append(to, addr)
+		}
+	}
+
+	return notifier.EmailConfig{
+		Host:         os.Getenv("SMTP_HOST"),
+		Port:         port,
+		Username:     os.Getenv("SMTP_USERNAME"),
+		Password:     os.Getenv("SMTP_PASSWORD"),
+		From:         getEnv("SMTP_FROM", "incidents@localhost"),
+		To:           to,
+		StartTLS:     getEnv("SMTP_STARTTLS", "true") == "true",
+		DashboardURL: dashboardURL,
+	}
+}
+
+// getEnv returns the value of an environment variable, or fallback when it is unset
+func getEnv(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Normalise the free-form statuses written before the lifecycle was enforced
+UPDATE incidents SET status = lower(trim(status));
+UPDATE incidents SET status = 'resolved' WHERE status IN ('done', 'fixed');
+UPDATE incidents SET status = 'open'
+WHERE status NOT IN ('open', 'acknowledged', 'investigating', 'mitigated', 'resolved', 'closed');
+
+ALTER TABLE incidents ADD CONSTRAINT incidents_status_check
+    CHECK (status IN ('open', 'acknowledged', 'investigating', 'mitigated', 'resolved', 'closed'));
+
+CREATE TABLE IF NOT EXISTS incident_status_transitions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    incident_id UUID NOT NULL REFERENCES incidents (id) ON DELETE CASCADE,
+    from_status TEXT NOT NULL,
+    to_status TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_incident_status_transitions_incident_id
+    ON incident_status_transitions (incident_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS incident_status_transitions;
+ALTER TABLE incidents DROP CONSTRAINT IF EXISTS incidents_status_check;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE notification_jobs
+    ADD COLUMN locked_by TEXT,
+    ADD COLUMN locked_until TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_notification_jobs_locked_until
+    ON notification_jobs (locked_until)
+    WHERE status = 'PROCESSING';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_notification_jobs_locked_until;
+ALTER TABLE notification_jobs
+    DROP COLUMN locked_by,
+    DROP COLUMN locked_until;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE notification_jobs
+    ADD COLUMN job_type VARCHAR(50) NOT NULL DEFAULT 'notification',
+    ADD COLUMN next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS idx_notification_jobs_runnable
+    ON notification_jobs (next_attempt_at)
+    WHERE status IN ('PENDING', 'FAILED');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_notification_jobs_runnable;
+ALTER TABLE notification_jobs
+    DROP COLUMN job_type,
+    DROP COLUMN next_attempt_at;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE notification_jobs ADD COLUMN channel VARCHAR(50) NOT NULL DEFAULT 'log';
+
+CREATE TABLE IF NOT EXISTS notification_attempts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    job_id UUID NOT NULL REFERENCES notification_jobs (id) ON DELETE CASCADE,
+    incident_id UUID NOT NULL,
+    worker_id TEXT NOT NULL,
+    channel VARCHAR(50) NOT NULL,
+    attempt INTEGER NOT NULL,
+    success BOOLEAN NOT NULL,
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL,
+    duration_ms BIGINT NOT NULL,
+    error_message TEXT NOT NULL DEFAULT '',
+    response_code INTEGER
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_attempts_incident_id ON notification_attempts (incident_id, started_at);
+CREATE INDEX IF NOT EXISTS idx_notification_attempts_job_id ON notification_attempts (job_id, started_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS notification_attempts;
+ALTER TABLE notification_jobs DROP COLUMN channel;
+-- +goose StatementEnd
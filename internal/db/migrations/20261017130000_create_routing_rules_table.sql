@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE incidents ADD COLUMN tags JSONB NOT NULL DEFAULT '[]';
+
+CREATE TABLE IF NOT EXISTS routing_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    team TEXT NOT NULL DEFAULT '',          -- empty matches every team
+    severity TEXT NOT NULL DEFAULT '',      -- empty matches every severity
+    title_pattern TEXT NOT NULL DEFAULT '', -- Go regular expression, empty matches every title
+    tags JSONB NOT NULL DEFAULT '[]',       -- the incident must carry all of them
+    events JSONB NOT NULL,
+    targets JSONB NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS routing_rules;
+ALTER TABLE incidents DROP COLUMN tags;
+-- +goose StatementEnd
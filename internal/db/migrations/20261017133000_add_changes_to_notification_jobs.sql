@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- fields changed by the update a job announces; empty for other events
+ALTER TABLE notification_jobs ADD COLUMN changes JSONB NOT NULL DEFAULT '[]';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE notification_jobs DROP COLUMN changes;
+-- +goose StatementEnd
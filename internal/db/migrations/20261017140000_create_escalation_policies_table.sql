@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS escalation_policies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    team TEXT NOT NULL,
+    severities JSONB NOT NULL DEFAULT '[]', -- empty escalates every severity
+    levels JSONB NOT NULL,                  -- ordered [{delay_minutes, targets}]
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- one policy per team
+CREATE UNIQUE INDEX IF NOT EXISTS idx_escalation_policies_team ON escalation_policies (lower(team));
+
+-- a row exists while an incident is unacknowledged and has levels left to page
+CREATE TABLE IF NOT EXISTS incident_escalations (
+    incident_id UUID PRIMARY KEY REFERENCES incidents (id) ON DELETE CASCADE,
+    policy_id UUID NOT NULL REFERENCES escalation_policies (id) ON DELETE CASCADE,
+    next_level INTEGER NOT NULL DEFAULT 0,
+    next_escalation_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_incident_escalations_due ON incident_escalations (next_escalation_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS incident_escalations;
+DROP TABLE IF EXISTS escalation_policies;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE incidents ADD COLUMN acknowledged_at TIMESTAMPTZ;
+ALTER TABLE incidents ADD COLUMN acknowledged_by TEXT;
+
+-- backfill from the first recorded acknowledgement of each incident
+UPDATE incidents i
+SET acknowledged_at = t.created_at, acknowledged_by = t.actor
+FROM (
+    SELECT DISTINCT ON (incident_id) incident_id, actor, created_at
+    FROM incident_status_transitions
+    WHERE to_status = 'acknowledged'
+    ORDER BY incident_id, created_at ASC
+) t
+WHERE i.id = t.incident_id;
+
+CREATE INDEX IF NOT EXISTS idx_incidents_acknowledged_at ON incidents (acknowledged_at) WHERE acknowledged_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_incidents_acknowledged_at;
+ALTER TABLE incidents DROP COLUMN acknowledged_by;
+ALTER TABLE incidents DROP COLUMN acknowledged_at;
+-- +goose StatementEnd
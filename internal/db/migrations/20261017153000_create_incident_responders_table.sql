@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE incidents ADD COLUMN assignee TEXT; -- name of the incident commander, if any
+
+CREATE TABLE IF NOT EXISTS incident_responders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    incident_id UUID NOT NULL REFERENCES incidents (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    role TEXT NOT NULL CHECK (role IN ('commander', 'scribe', 'comms_lead')),
+    channel TEXT NOT NULL,
+    destination TEXT NOT NULL DEFAULT '',
+    assigned_by TEXT NOT NULL,
+    assigned_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (incident_id, name)
+);
+
+-- an incident has at most one commander
+CREATE UNIQUE INDEX IF NOT EXISTS idx_incident_responders_commander
+    ON incident_responders (incident_id) WHERE role = 'commander';
+
+CREATE INDEX IF NOT EXISTS idx_incidents_assignee ON incidents (assignee) WHERE assignee IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_incidents_assignee;
+DROP TABLE IF EXISTS incident_responders;
+ALTER TABLE incidents DROP COLUMN assignee;
+-- +goose StatementEnd
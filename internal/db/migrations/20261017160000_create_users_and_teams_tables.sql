@@ -0,0 +1,115 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS teams (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL UNIQUE,
+    slug TEXT NOT NULL UNIQUE, -- lower-cased letters and digits of the name, so "Dev Ops" and "devops" collide
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,                          -- handle, as sent in X-Actor
+    email TEXT NOT NULL DEFAULT '',
+    contact_methods JSONB NOT NULL DEFAULT '[]', -- ordered by preference
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_name ON users (lower(name));
+
+CREATE TABLE IF NOT EXISTS team_members (
+    team_id UUID NOT NULL REFERENCES teams (id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (team_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_team_members_user_id ON team_members (user_id);
+
+-- One team per family of spellings, named after the most used one. Teams
+-- without a single letter or digit are gathered under "Unassigned".
+WITH named AS (
+    SELECT team FROM incidents
+    UNION ALL SELECT team FROM escalation_policies
+    UNION ALL SELECT team FROM routing_rules WHERE team <> ''
+    UNION ALL SELECT team FROM schedules
+),
+spellings AS (
+    SELECT trim(team) AS team, lower(regexp_replace(team, '[^[:alnum:]]', '', 'g')) AS slug
+    FROM named
+)
+INSERT INTO teams (name, slug)
+SELECT
+    CASE WHEN slug = '' THEN 'Unassigned' ELSE mode() WITHIN GROUP (ORDER BY team) END,
+    CASE WHEN slug = '' THEN 'unassigned' ELSE slug END
+FROM spellings
+GROUP BY slug
+ON CONFLICT DO NOTHING;
+
+UPDATE incidents i
+SET team = t.name
+FROM teams t
+WHERE t.slug = COALESCE(NULLIF(lower(regexp_replace(i.team, '[^[:alnum:]]', '', 'g')), ''), 'unassigned')
+  AND i.team <> t.name;
+
+-- renaming a team renames it on its incidents
+ALTER TABLE incidents ADD CONSTRAINT incidents_team_fkey
+    FOREIGN KEY (team) REFERENCES teams (name) ON UPDATE CASCADE;
+
+-- escalation policies, routing rules and schedules name their team the same
+-- way. Two escalation policies whose teams are spellings of one team break
+-- the one-policy-per-team index and have to be merged by hand first.
+DROP INDEX IF EXISTS idx_escalation_policies_team;
+
+UPDATE escalation_policies p
+SET team = t.name
+FROM teams t
+WHERE t.slug = COALESCE(NULLIF(lower(regexp_replace(p.team, '[^[:alnum:]]', '', 'g')), ''), 'unassigned')
+  AND p.team <> t.name;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_escalation_policies_team ON escalation_policies (team);
+
+ALTER TABLE escalation_policies ADD CONSTRAINT escalation_policies_team_fkey
+    FOREIGN KEY (team) REFERENCES teams (name) ON UPDATE CASCADE;
+
+-- a rule for every team has no team rather than an empty one
+ALTER TABLE routing_rules ALTER COLUMN team DROP NOT NULL, ALTER COLUMN team DROP DEFAULT;
+UPDATE routing_rules SET team = NULL WHERE team = '';
+
+UPDATE routing_rules r
+SET team = t.name
+FROM teams t
+WHERE t.slug = COALESCE(NULLIF(lower(regexp_replace(r.team, '[^[:alnum:]]', '', 'g')), ''), 'unassigned')
+  AND r.team <> t.name;
+
+ALTER TABLE routing_rules ADD CONSTRAINT routing_rules_team_fkey
+    FOREIGN KEY (team) REFERENCES teams (name) ON UPDATE CASCADE;
+
+UPDATE schedules s
+SET team = t.name
+FROM teams t
+WHERE t.slug = COALESCE(NULLIF(lower(regexp_replace(s.team, '[^[:alnum:]]', '', 'g')), ''), 'unassigned')
+  AND s.team <> t.name;
+
+ALTER TABLE schedules ADD CONSTRAINT schedules_team_fkey
+    FOREIGN KEY (team) REFERENCES teams (name) ON UPDATE CASCADE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- incidents, escalation policies, routing rules and schedules keep their canonical team names
+ALTER TABLE schedules DROP CONSTRAINT IF EXISTS schedules_team_fkey;
+ALTER TABLE routing_rules DROP CONSTRAINT IF EXISTS routing_rules_team_fkey;
+UPDATE routing_rules SET team = '' WHERE team IS NULL;
+ALTER TABLE routing_rules ALTER COLUMN team SET DEFAULT '', ALTER COLUMN team SET NOT NULL;
+ALTER TABLE escalation_policies DROP CONSTRAINT IF EXISTS escalation_policies_team_fkey;
+DROP INDEX IF EXISTS idx_escalation_policies_team;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_escalation_policies_team ON escalation_policies (lower(team));
+ALTER TABLE incidents DROP CONSTRAINT IF EXISTS incidents_team_fkey;
+DROP TABLE IF EXISTS team_members;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS teams;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- keyset pagination walks (sort column, id) in either direction
+CREATE INDEX IF NOT EXISTS idx_incidents_created_at_id ON incidents (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_incidents_updated_at_id ON incidents (updated_at, id);
+
+-- the common filters, paged newest first
+CREATE INDEX IF NOT EXISTS idx_incidents_status_created_at ON incidents (status, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_incidents_team_created_at ON incidents (team, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_incidents_severity ON incidents (lower(severity));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_incidents_severity;
+DROP INDEX IF EXISTS idx_incidents_team_created_at;
+DROP INDEX IF EXISTS idx_incidents_status_created_at;
+DROP INDEX IF EXISTS idx_incidents_updated_at_id;
+DROP INDEX IF EXISTS idx_incidents_created_at_id;
+-- +goose StatementEnd
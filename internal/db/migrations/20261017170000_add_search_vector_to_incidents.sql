@@ -0,0 +1,56 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE incidents ADD COLUMN search_vector TSVECTOR NOT NULL DEFAULT ''::tsvector;
+
+-- Title outweighs description, which outweighs timeline notes.
+CREATE OR REPLACE FUNCTION incident_search_vector(incident UUID, title TEXT, description TEXT)
+RETURNS TSVECTOR AS $$
+    SELECT setweight(to_tsvector('english', coalesce(title, '')), 'A')
+        || setweight(to_tsvector('english', coalesce(description, '')), 'B')
+        || setweight(to_tsvector('english', coalesce((
+            SELECT string_agg(message, ' ' ORDER BY created_at)
+            FROM incident_events
+            WHERE incident_id = incident AND event_type = 'note'
+        ), '')), 'C')
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION incidents_search_vector_trigger() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector := incident_search_vector(NEW.id, NEW.title, NEW.description);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER incidents_search_vector_update
+    BEFORE INSERT OR UPDATE OF title, description ON incidents
+    FOR EACH ROW EXECUTE FUNCTION incidents_search_vector_trigger();
+
+CREATE OR REPLACE FUNCTION incident_notes_search_vector_trigger() RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE incidents
+    SET search_vector = incident_search_vector(id, title, description)
+    WHERE id = NEW.incident_id;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER incident_notes_search_vector_update
+    AFTER INSERT ON incident_events
+    FOR EACH ROW WHEN (NEW.event_type = 'note')
+    EXECUTE FUNCTION incident_notes_search_vector_trigger();
+
+UPDATE incidents SET search_vector = incident_search_vector(id, title, description);
+
+CREATE INDEX IF NOT EXISTS idx_incidents_search_vector ON incidents USING GIN (search_vector);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_incidents_search_vector;
+DROP TRIGGER IF EXISTS incident_notes_search_vector_update ON incident_events;
+DROP FUNCTION IF EXISTS incident_notes_search_vector_trigger();
+DROP TRIGGER IF EXISTS incidents_search_vector_update ON incidents;
+DROP FUNCTION IF EXISTS incidents_search_vector_trigger();
+DROP FUNCTION IF EXISTS incident_search_vector(UUID, TEXT, TEXT);
+ALTER TABLE incidents DROP COLUMN search_vector;
+-- +goose StatementEnd
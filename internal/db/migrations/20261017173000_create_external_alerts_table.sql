@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS external_alerts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    source TEXT NOT NULL,           -- e.g. alertmanager
+    fingerprint TEXT NOT NULL,      -- identifies the alert group within its source
+    incident_id UUID NOT NULL REFERENCES incidents (id) ON DELETE CASCADE,
+    status TEXT NOT NULL,           -- firing or resolved, as last reported
+    labels JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (source, fingerprint)
+);
+
+CREATE INDEX IF NOT EXISTS idx_external_alerts_incident_id ON external_alerts (incident_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS external_alerts;
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE incidents
+    ADD COLUMN IF NOT EXISTS fingerprint TEXT,
+    ADD COLUMN IF NOT EXISTS occurrence_count INTEGER NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS last_occurred_at TIMESTAMPTZ;
+
+-- existing incidents get the fingerprint the API derives when none is given:
+-- a hash of the team slug and the whitespace-normalised, lower-cased title
+UPDATE incidents
+SET fingerprint = encode(sha256(convert_to(
+        lower(regexp_replace(team, '[^[:alnum:]]', '', 'g')) || E'\n' ||
+        lower(btrim(regexp_replace(title, '\s+', ' ', 'g'))),
+        'UTF8')), 'hex'),
+    last_occurred_at = created_at;
+
+ALTER TABLE incidents
+    ALTER COLUMN fingerprint SET NOT NULL,
+    ALTER COLUMN last_occurred_at SET NOT NULL,
+    ALTER COLUMN last_occurred_at SET DEFAULT now();
+
+-- new occurrences only ever group into incidents that are still active
+CREATE INDEX IF NOT EXISTS idx_incidents_active_fingerprint
+    ON incidents (fingerprint, last_occurred_at DESC)
+    WHERE status NOT IN ('resolved', 'closed');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_incidents_active_fingerprint;
+
+ALTER TABLE incidents
+    DROP COLUMN IF EXISTS last_occurred_at,
+    DROP COLUMN IF EXISTS occurrence_count,
+    DROP COLUMN IF EXISTS fingerprint;
+-- +goose StatementEnd
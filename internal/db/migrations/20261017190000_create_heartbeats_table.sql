@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS heartbeats (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL UNIQUE,
+    team TEXT NOT NULL REFERENCES teams (name) ON UPDATE CASCADE,
+    severity TEXT NOT NULL,
+    interval_seconds INTEGER NOT NULL CHECK (interval_seconds > 0),
+    grace_seconds INTEGER NOT NULL DEFAULT 0 CHECK (grace_seconds >= 0),
+    status TEXT NOT NULL DEFAULT 'new' CHECK (status IN ('new', 'up', 'down')),
+    last_ping_at TIMESTAMPTZ,
+    incident_id UUID REFERENCES incidents (id) ON DELETE SET NULL, -- opened when the heartbeat went down
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS heartbeats;
+-- +goose StatementEnd
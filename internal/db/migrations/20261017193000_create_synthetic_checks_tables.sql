@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS synthetic_checks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL UNIQUE,
+    type TEXT NOT NULL CHECK (type IN ('http', 'tcp')),
+    url TEXT NOT NULL DEFAULT '',
+    method TEXT NOT NULL DEFAULT '',
+    expected_status INTEGER, -- any 2xx when NULL
+    body_contains TEXT NOT NULL DEFAULT '',
+    host TEXT NOT NULL DEFAULT '',
+    port INTEGER CHECK (port BETWEEN 1 AND 65535),
+    interval_seconds INTEGER NOT NULL CHECK (interval_seconds > 0),
+    timeout_seconds INTEGER NOT NULL CHECK (timeout_seconds > 0),
+    failure_threshold INTEGER NOT NULL CHECK (failure_threshold > 0),
+    team TEXT NOT NULL REFERENCES teams (name) ON UPDATE CASCADE,
+    severity TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'new' CHECK (status IN ('new', 'up', 'down')),
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    last_checked_at TIMESTAMPTZ,
+    next_run_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    incident_id UUID REFERENCES incidents (id) ON DELETE SET NULL, -- opened when the check went down
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_synthetic_checks_next_run_at ON synthetic_checks (next_run_at);
+
+CREATE TABLE IF NOT EXISTS synthetic_check_results (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    check_id UUID NOT NULL REFERENCES synthetic_checks (id) ON DELETE CASCADE,
+    checked_at TIMESTAMPTZ NOT NULL,
+    success BOOLEAN NOT NULL,
+    duration_ms BIGINT NOT NULL,
+    status_code INTEGER,
+    error_message TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_synthetic_check_results_check_id ON synthetic_check_results (check_id, checked_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS synthetic_check_results;
+DROP TABLE IF EXISTS synthetic_checks;
+-- +goose StatementEnd
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hascho/go-incident-dashboard-api/internal/middleware"
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+	"github.com/hascho/go-incident-dashboard-api/internal/service"
+)
+
+// maxEventBytes bounds the body of an integration event
+const maxEventBytes = 1 << 20
+
+type AlertHandler struct {
+	Service service.AlertService
+}
+
+func NewAlertHandler(svc service.AlertService) *AlertHandler {
+	return &AlertHandler{Service: svc}
+}
+
+// IngestAlertmanager receives Alertmanager webhook notifications. Alertmanager
+// retries on 5xx, so only failures worth retrying are reported that way.
+func (h *AlertHandler) IngestAlertmanager(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+
+	var payload model.AlertmanagerPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid request: %v", err.Error()),
+		})
+		return
+	}
+
+	result, err := h.Service.IngestAlertmanager(c.Request.Context(), payload)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidAlert) || errors.Is(err, service.ErrUnknownTeam) {
+			// Alertmanager does not retry a 400, so this is the only trace of the dropped alert
+			logger.Warn().Err(err).Str("group_key", payload.GroupKey).Str("receiver", payload.Receiver).Msg("Rejected Alertmanager notification")
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+			return
+		}
+		logger.Error().Err(err).Str("group_key", payload.GroupKey).Msg("Failed to ingest Alertmanager notification")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to ingest alert notification",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// IngestEvent receives an event for an integration. The integration's token
+// goes in an "Authorization: Bearer" header or, for senders that cannot set
+// headers, the token query parameter.
+func (h *AlertHandler) IngestEvent(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	integrationID, ok := parseIntegrationID(c)
+	if !ok {
+		return
+	}
+
+	payload, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxEventBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, model.ErrorResponse{
+				Code:    http.StatusRequestEntityTooLarge,
+				Message: fmt.Sprintf("Event payload must not exceed %d bytes", maxEventBytes),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid request: %v", err.Error()),
+		})
+		return
+	}
+
+	token := c.Query("token")
+	if bearer, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); found {
+		token = strings.TrimSpace(bearer)
+	}
+
+	result, err := h.Service.IngestEvent(c.Request.Context(), integrationID, token, payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			integrationNotFound(c, integrationID)
+		case errors.Is(err, service.ErrIntegrationUnauthorized):
+			c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+				Code:    http.StatusUnauthorized,
+				Message: err.Error(),
+			})
+		case errors.Is(err, service.ErrInvalidAlert), errors.Is(err, service.ErrUnknownTeam):
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+		default:
+			logger.Error().Err(err).Str("integration_id", integrationID).Msg("Failed to ingest integration event")
+			c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to ingest event",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
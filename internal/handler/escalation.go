@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hascho/go-incident-dashboard-api/internal/middleware"
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+	"github.com/hascho/go-incident-dashboard-api/internal/service"
+)
+
+type EscalationHandler struct {
+	Service service.EscalationService
+}
+
+func NewEscalationHandler(svc service.EscalationService) *EscalationHandler {
+	return &EscalationHandler{Service: svc}
+}
+
+func (h *EscalationHandler) CreateEscalationPolicy(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+
+	var req model.EscalationPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid request: %v", err.Error()),
+		})
+		return
+	}
+
+	policy, err := h.Service.CreateEscalationPolicy(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidEscalationPolicy) || errors.Is(err, service.ErrUnknownTeam) {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, service.ErrEscalationPolicyConflict) {
+			c.JSON(http.StatusConflict, model.ErrorResponse{
+				Code:    http.StatusConflict,
+				Message: err.Error(),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to create escalation policy")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create escalation policy",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, policy)
+}
+
+func (h *EscalationHandler) GetAllEscalationPolicies(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+
+	policies, err := h.Service.GetAllEscalationPolicies(c.Request.Context())
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to list escalation policies")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to retrieve escalation policies",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, policies)
+}
+
+func (h *EscalationHandler) GetEscalationPolicyByID(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	policyID, ok := parseEscalationPolicyID(c)
+	if !ok {
+		return
+	}
+
+	policy, err := h.Service.GetEscalationPolicyByID(c.Request.Context(), policyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Escalation policy with ID %s not found", policyID),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to retrieve escalation policy")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+func (h *EscalationHandler) UpdateEscalationPolicy(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	policyID, ok := parseEscalationPolicyID(c)
+	if !ok {
+		return
+	}
+
+	var req model.EscalationPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid request: %v", err.Error()),
+		})
+		return
+	}
+
+	policy, err := h.Service.UpdateEscalationPolicy(c.Request.Context(), policyID, req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidEscalationPolicy) || errors.Is(err, service.ErrUnknownTeam) {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, service.ErrEscalationPolicyConflict) {
+			c.JSON(http.StatusConflict, model.ErrorResponse{
+				Code:    http.StatusConflict,
+				Message: err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Escalation policy with ID %s not found", policyID),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to update escalation policy")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update escalation policy",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+func (h *EscalationHandler) DeleteEscalationPolicy(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	policyID, ok := parseEscalationPolicyID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.DeleteEscalationPolicy(c.Request.Context(), policyID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Escalation policy with ID %s not found", policyID),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to delete escalation policy")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error during deletion.",
+		})
+		return
+	}
+
+	logger.Info().Str("policy_id", policyID).Msg("Escalation policy deleted")
+	c.Status(http.StatusNoContent)
+}
+
+// parseEscalationPolicyID validates the :id path parameter, writing a 400 when it is not a UUID
+func parseEscalationPolicyID(c *gin.Context) (string, bool) {
+	policyID := c.Param("id")
+	if _, err := uuid.Parse(policyID); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Escalation policy ID '%s' is not a valid UUID format.", policyID),
+		})
+		return "", false
+	}
+	return policyID, true
+}
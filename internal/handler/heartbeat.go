@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hascho/go-incident-dashboard-api/internal/middleware"
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+	"github.com/hascho/go-incident-dashboard-api/internal/service"
+)
+
+type HeartbeatHandler struct {
+	Service service.HeartbeatService
+}
+
+func NewHeartbeatHandler(svc service.HeartbeatService) *HeartbeatHandler {
+	return &HeartbeatHandler{Service: svc}
+}
+
+func (h *HeartbeatHandler) CreateHeartbeat(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+
+	var req model.HeartbeatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid request: %v", err.Error()),
+		})
+		return
+	}
+
+	heartbeat, err := h.Service.CreateHeartbeat(c.Request.Context(), req)
+	if err != nil {
+		if writeHeartbeatError(c, err) {
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to create heartbeat")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create heartbeat",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, heartbeat)
+}
+
+func (h *HeartbeatHandler) GetAllHeartbeats(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+
+	heartbeats, err := h.Service.GetAllHeartbeats(c.Request.Context())
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to list heartbeats")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to retrieve heartbeats",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, heartbeats)
+}
+
+func (h *HeartbeatHandler) GetHeartbeatByID(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	heartbeatID, ok := parseHeartbeatID(c)
+	if !ok {
+		return
+	}
+
+	heartbeat, err := h.Service.GetHeartbeatByID(c.Request.Context(), heartbeatID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			heartbeatNotFound(c, heartbeatID)
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to retrieve heartbeat")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, heartbeat)
+}
+
+func (h *HeartbeatHandler) UpdateHeartbeat(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	heartbeatID, ok := parseHeartbeatID(c)
+	if !ok {
+		return
+	}
+
+	var req model.HeartbeatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid request: %v", err.Error()),
+		})
+		return
+	}
+
+	heartbeat, err := h.Service.UpdateHeartbeat(c.Request.Context(), heartbeatID, req)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			heartbeatNotFound(c, heartbeatID)
+			return
+		}
+		if writeHeartbeatError(c, err) {
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to update heartbeat")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update heartbeat",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, heartbeat)
+}
+
+func (h *HeartbeatHandler) DeleteHeartbeat(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	heartbeatID, ok := parseHeartbeatID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.DeleteHeartbeat(c.Request.Context(), heartbeatID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			heartbeatNotFound(c, heartbeatID)
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to delete heartbeat")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error during deletion.",
+		})
+		return
+	}
+
+	logger.Info().Str("heartbeat_id", heartbeatID).Msg("Heartbeat deleted")
+	c.Status(http.StatusNoContent)
+}
+
+// Ping is called by the monitored job each time it runs
+func (h *HeartbeatHandler) Ping(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	heartbeatID, ok := parseHeartbeatID(c)
+	if !ok {
+		return
+	}
+
+	heartbeat, err := h.Service.Ping(c.Request.Context(), heartbeatID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			heartbeatNotFound(c, heartbeatID)
+			return
+		}
+		logger.Error().Err(err).Str("heartbeat_id", heartbeatID).Msg("Failed to record heartbeat ping")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to record ping",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, heartbeat)
+}
+
+// writeHeartbeatError answers the client errors shared by create and update,
+// reporting whether it wrote a response
+func writeHeartbeatError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrInvalidHeartbeat), errors.Is(err, service.ErrUnknownTeam):
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+	case errors.Is(err, service.ErrHeartbeatConflict):
+		c.JSON(http.StatusConflict, model.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: err.Error(),
+		})
+	default:
+		return false
+	}
+	return true
+}
+
+func heartbeatNotFound(c *gin.Context, heartbeatID string) {
+	c.JSON(http.StatusNotFound, model.ErrorResponse{
+		Code:    http.StatusNotFound,
+		Message: fmt.Sprintf("Heartbeat with ID %s not found", heartbeatID),
+	})
+}
+
+func parseHeartbeatID(c *gin.Context) (string, bool) {
+	heartbeatID := c.Param("id")
+	if _, err := uuid.Parse(heartbeatID); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Heartbeat ID '%s' is not a valid UUID format.", heartbeatID),
+		})
+		return "", false
+	}
+	return heartbeatID, true
+}
@@ -5,6 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -54,11 +57,16 @@ func (h *IncidentHandler) GetIncidentByID(c *gin.Context) {
 	}
 
 	response := model.IncidentResponse{
-		ID:       incidentID,
-		Title:    incident.ID,
-		Status:   incident.Status,
-		Severity: incident.Severity,
-		Team:     incident.Team,
+		ID:             incidentID,
+		Title:          incident.ID,
+		Status:         incident.Status,
+		Severity:       incident.Severity,
+		Team:           incident.Team,
+		Assignee:       incident.Assignee,
+		AcknowledgedAt: incident.AcknowledgedAt,
+		AcknowledgedBy: incident.AcknowledgedBy,
+		Fingerprint:    incident.Fingerprint,
+		Occurrences:    incident.OccurrenceCount,
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -81,11 +89,22 @@ func (h *IncidentHandler) CreateIncident(c *gin.Context) {
 		Description: req.Description,
 		Severity:    req.Severity,
 		Team:        req.Team,
-		Status:      "open", // Handler dictates the initial state
+		Tags:        req.Tags,
+		Fingerprint: req.Fingerprint,
+		Status:      model.StatusOpen, // Handler dictates the initial state
 	}
 
-	createdIncident, err := h.Service.CreateIncident(c.Request.Context(), incident)
+	actor := middleware.GetActor(c.Request.Context())
+
+	createdIncident, err := h.Service.CreateIncident(c.Request.Context(), actor, incident)
 	if err != nil {
+		if errors.Is(err, service.ErrUnknownTeam) {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("%v; create the team first with POST /teams", err),
+			})
+			return
+		}
 		logger.Error().Err(err).Msg("Repository failure during incident creation")
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Code:    http.StatusInternalServerError,
@@ -95,22 +114,98 @@ func (h *IncidentHandler) CreateIncident(c *gin.Context) {
 	}
 
 	response := model.IncidentResponse{
-		ID:       createdIncident.ID,
-		Title:    createdIncident.Title,
-		Status:   createdIncident.Status,
-		Severity: createdIncident.Severity,
-		Team:     createdIncident.Team,
+		ID:          createdIncident.ID,
+		Title:       createdIncident.Title,
+		Status:      createdIncident.Status,
+		Severity:    createdIncident.Severity,
+		Team:        createdIncident.Team,
+		Assignee:    createdIncident.Assignee,
+		Fingerprint: createdIncident.Fingerprint,
+		Occurrences: createdIncident.OccurrenceCount,
+	}
+
+	// a duplicate was grouped into an existing incident rather than created
+	if createdIncident.OccurrenceCount > 1 {
+		logger.Info().Str("incident_id", createdIncident.ID).Msg("Incident occurrence recorded")
+		c.JSON(http.StatusOK, response)
+		return
 	}
 
 	logger.Info().Str("incident_id", createdIncident.ID).Msg("Incident created successfully")
 	c.JSON(http.StatusCreated, response)
 }
 
+// GetAllIncidents lists incidents a page at a time. Filters: assignee (or
+// "me"), status and severity (comma-separated), team, created_after,
+// created_before, updated_after and updated_before (RFC 3339). Ordering: sort
+// (created_at or updated_at) and direction (asc or desc). Paging: limit, and
+// cursor set to the previous page's next_cursor.
 func (h *IncidentHandler) GetAllIncidents(c *gin.Context) {
 	logger := middleware.GetLogger(c.Request.Context())
 
-	incidents, err := h.Service.GetAllIncidents(c.Request.Context())
+	filter := model.IncidentFilter{
+		Assignee:   c.Query("assignee"),
+		Statuses:   queryList(c, "status"),
+		Severities: queryList(c, "severity"),
+		Team:       c.Query("team"),
+		Sort:       c.Query("sort"),
+		Direction:  c.Query("direction"),
+		Cursor:     c.Query("cursor"),
+	}
+	if filter.Assignee == "me" {
+		actor := middleware.GetActor(c.Request.Context())
+		if actor == middleware.AnonymousActor {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("'assignee=me' needs the %s header to identify you.", middleware.ActorHeader),
+			})
+			return
+		}
+		filter.Assignee = actor
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Query parameter 'limit' must be an integer, got '%s'.", raw),
+			})
+			return
+		}
+		filter.Limit = parsed
+	}
+
+	for param, dest := range map[string]**time.Time{
+		"created_after":  &filter.CreatedAfter,
+		"created_before": &filter.CreatedBefore,
+		"updated_after":  &filter.UpdatedAfter,
+		"updated_before": &filter.UpdatedBefore,
+	} {
+		raw := c.Query(param)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Query parameter '%s' must be an RFC 3339 timestamp, got '%s'.", param, raw),
+			})
+			return
+		}
+		*dest = &parsed
+	}
+
+	page, err := h.Service.GetAllIncidents(c.Request.Context(), filter)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidFilter) {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+			return
+		}
 		logger.Error().Err(err).Msg("Failed to list incidents")
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Code:    http.StatusInternalServerError,
@@ -119,14 +214,20 @@ func (h *IncidentHandler) GetAllIncidents(c *gin.Context) {
 		return
 	}
 
-	response := make([]model.IncidentResponse, len(incidents))
-	for i, incident := range incidents {
-		response[i] = model.IncidentResponse{
-			ID:       incident.ID,
-			Title:    incident.Title,
-			Status:   incident.Status,
-			Severity: incident.Severity,
-			Team:     incident.Team,
+	response := model.IncidentListResponse{
+		Incidents:  make([]model.IncidentResponse, len(page.Incidents)),
+		NextCursor: page.NextCursor,
+	}
+	for i, incident := range page.Incidents {
+		response.Incidents[i] = model.IncidentResponse{
+			ID:             incident.ID,
+			Title:          incident.Title,
+			Status:         incident.Status,
+			Severity:       incident.Severity,
+			Team:           incident.Team,
+			Assignee:       incident.Assignee,
+			AcknowledgedAt: incident.AcknowledgedAt,
+			AcknowledgedBy: incident.AcknowledgedBy,
 		}
 	}
 
@@ -154,7 +255,9 @@ func (h *IncidentHandler) PatchIncident(c *gin.Context) {
 		return
 	}
 
-	updatedIncident, err := h.Service.UpdateIncident(c.Request.Context(), incidentID, req)
+	actor := middleware.GetActor(c.Request.Context())
+
+	updatedIncident, err := h.Service.UpdateIncident(c.Request.Context(), incidentID, actor, req)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			c.JSON(http.StatusNotFound, model.ErrorResponse{
@@ -163,6 +266,21 @@ func (h *IncidentHandler) PatchIncident(c *gin.Context) {
 			})
 			return
 		}
+		if errors.Is(err, service.ErrInvalidStatus) || errors.Is(err, service.ErrInvalidUpdate) {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidTransition) {
+			logger.Warn().Err(err).Str("incident_id", incidentID).Msg("Rejected incident status transition")
+			c.JSON(http.StatusConflict, model.ErrorResponse{
+				Code:    http.StatusConflict,
+				Message: err.Error(),
+			})
+			return
+		}
 		logger.Error().Err(err).Msg("Failed to update incident")
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Code:    http.StatusInternalServerError,
@@ -172,11 +290,169 @@ func (h *IncidentHandler) PatchIncident(c *gin.Context) {
 	}
 
 	response := model.IncidentResponse{
-		ID:       updatedIncident.ID,
-		Title:    updatedIncident.Title,
-		Status:   updatedIncident.Status,
-		Severity: updatedIncident.Severity,
-		Team:     updatedIncident.Team,
+		ID:             updatedIncident.ID,
+		Title:          updatedIncident.Title,
+		Status:         updatedIncident.Status,
+		Severity:       updatedIncident.Severity,
+		Team:           updatedIncident.Team,
+		Assignee:       updatedIncident.Assignee,
+		AcknowledgedAt: updatedIncident.AcknowledgedAt,
+		AcknowledgedBy: updatedIncident.AcknowledgedBy,
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *IncidentHandler) AcknowledgeIncident(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	incidentID := c.Param("id")
+
+	if _, err := uuid.Parse(incidentID); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Incident ID '%s' is not a valid UUID format.", incidentID),
+		})
+		return
+	}
+
+	actor := middleware.GetActor(c.Request.Context())
+
+	incident, err := h.Service.AcknowledgeIncident(c.Request.Context(), incidentID, actor)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Incident with ID %s not found", incidentID),
+			})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidTransition) {
+			logger.Warn().Err(err).Str("incident_id", incidentID).Msg("Rejected incident acknowledgement")
+			c.JSON(http.StatusConflict, model.ErrorResponse{
+				Code:    http.StatusConflict,
+				Message: err.Error(),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to acknowledge incident")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error during acknowledgement.",
+		})
+		return
+	}
+
+	logger.Info().Str("incident_id", incidentID).Str("actor", actor).Msg("Incident acknowledged")
+	c.JSON(http.StatusOK, model.IncidentResponse{
+		ID:             incident.ID,
+		Title:          incident.Title,
+		Status:         incident.Status,
+		Severity:       incident.Severity,
+		Team:           incident.Team,
+		Assignee:       incident.Assignee,
+		AcknowledgedAt: incident.AcknowledgedAt,
+		AcknowledgedBy: incident.AcknowledgedBy,
+	})
+}
+
+// GetMTTAStats reports mean-time-to-acknowledge per team and severity over the
+// incidents created since ?since (default the last 30 days), optionally narrowed
+// by ?team and ?severity.
+func (h *IncidentHandler) GetMTTAStats(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+
+	filter := model.MTTAFilter{
+		Team:     c.Query("team"),
+		Severity: c.Query("severity"),
+		Since:    time.Now().AddDate(0, 0, -30),
+	}
+	if raw := c.Query("since"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Query parameter 'since' must be an RFC 3339 timestamp, got '%s'.", raw),
+			})
+			return
+		}
+		filter.Since = parsed
+	}
+
+	stats, err := h.Service.GetMTTAStats(c.Request.Context(), filter)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to compute MTTA stats")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to compute mean time to acknowledge",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// SearchIncidents runs a full-text search over titles, descriptions and
+// timeline notes, best matches first. See service.SearchIncidents for the
+// syntax of q.
+func (h *IncidentHandler) SearchIncidents(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Query parameter 'q' is required.",
+		})
+		return
+	}
+
+	var limit int
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Query parameter 'limit' must be an integer, got '%s'.", raw),
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	hits, err := h.Service.SearchIncidents(c.Request.Context(), q, limit)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSearch) {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to search incidents")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to search incidents",
+		})
+		return
+	}
+
+	response := make([]model.IncidentSearchResult, len(hits))
+	for i, hit := range hits {
+		response[i] = model.IncidentSearchResult{
+			IncidentResponse: model.IncidentResponse{
+				ID:             hit.Incident.ID,
+				Title:          hit.Incident.Title,
+				Status:         hit.Incident.Status,
+				Severity:       hit.Incident.Severity,
+				Team:           hit.Incident.Team,
+				Assignee:       hit.Incident.Assignee,
+				AcknowledgedAt: hit.Incident.AcknowledgedAt,
+				AcknowledgedBy: hit.Incident.AcknowledgedBy,
+			},
+			CreatedAt: hit.Incident.CreatedAt,
+			Rank:      hit.Rank,
+			Snippet:   hit.Snippet,
+		}
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -213,3 +489,16 @@ func (h *IncidentHandler) DeleteIncident(c *gin.Context) {
 	logger.Info().Str("incident_id", incidentID).Msg("Incident deleted successfully")
 	c.Status(http.StatusNoContent)
 }
+
+// queryList collects a list query parameter given comma-separated, repeated, or both
+func queryList(c *gin.Context, key string) []string {
+	var values []string
+	for _, raw := range c.QueryArray(key) {
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hascho/go-incident-dashboard-api/internal/middleware"
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+	"github.com/hascho/go-incident-dashboard-api/internal/service"
+)
+
+type IntegrationHandler struct {
+	Service service.IntegrationService
+}
+
+func NewIntegrationHandler(svc service.IntegrationService) *IntegrationHandler {
+	return &IntegrationHandler{Service: svc}
+}
+
+func (h *IntegrationHandler) CreateIntegration(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+
+	var req model.IntegrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid request: %v", err.Error()),
+		})
+		return
+	}
+
+	integration, err := h.Service.CreateIntegration(c.Request.Context(), req)
+	if err != nil {
+		if writeIntegrationError(c, err) {
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to create integration")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create integration",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, integration)
+}
+
+func (h *IntegrationHandler) GetAllIntegrations(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+
+	integrations, err := h.Service.GetAllIntegrations(c.Request.Context())
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to list integrations")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to retrieve integrations",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, integrations)
+}
+
+func (h *IntegrationHandler) GetIntegrationByID(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	integrationID, ok := parseIntegrationID(c)
+	if !ok {
+		return
+	}
+
+	integration, err := h.Service.GetIntegrationByID(c.Request.Context(), integrationID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			integrationNotFound(c, integrationID)
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to retrieve integration")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, integration)
+}
+
+func (h *IntegrationHandler) UpdateIntegration(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	integrationID, ok := parseIntegrationID(c)
+	if !ok {
+		return
+	}
+
+	var req model.IntegrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid request: %v", err.Error()),
+		})
+		return
+	}
+
+	integration, err := h.Service.UpdateIntegration(c.Request.Context(), integrationID, req)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			integrationNotFound(c, integrationID)
+			return
+		}
+		if writeIntegrationError(c, err) {
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to update integration")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update integration",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, integration)
+}
+
+func (h *IntegrationHandler) RotateToken(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	integrationID, ok := parseIntegrationID(c)
+	if !ok {
+		return
+	}
+
+	integration, err := h.Service.RotateToken(c.Request.Context(), integrationID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			integrationNotFound(c, integrationID)
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to rotate integration token")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to rotate integration token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, integration)
+}
+
+func (h *IntegrationHandler) DeleteIntegration(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	integrationID, ok := parseIntegrationID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.DeleteIntegration(c.Request.Context(), integrationID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			integrationNotFound(c, integrationID)
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to delete integration")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error during deletion.",
+		})
+		return
+	}
+
+	logger.Info().Str("integration_id", integrationID).Msg("Integration deleted")
+	c.Status(http.StatusNoContent)
+}
+
+// writeIntegrationError answers the client errors shared by create and update,
+// reporting whether it wrote a response
+func writeIntegrationError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrInvalidIntegration), errors.Is(err, service.ErrUnknownTeam):
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+	case errors.Is(err, service.ErrIntegrationConflict):
+		c.JSON(http.StatusConflict, model.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: err.Error(),
+		})
+	default:
+		return false
+	}
+	return true
+}
+
+func integrationNotFound(c *gin.Context, integrationID string) {
+	c.JSON(http.StatusNotFound, model.ErrorResponse{
+		Code:    http.StatusNotFound,
+		Message: fmt.Sprintf("Integration with ID %s not found", integrationID),
+	})
+}
+
+func parseIntegrationID(c *gin.Context) (string, bool) {
+	integrationID := c.Param("id")
+	if _, err := uuid.Parse(integrationID); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Integration ID '%s' is not a valid UUID format.", integrationID),
+		})
+		return "", false
+	}
+	return integrationID, true
+}
@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hascho/go-incident-dashboard-api/internal/middleware"
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+	"github.com/hascho/go-incident-dashboard-api/internal/service"
+)
+
+const (
+	defaultDeadLetterLimit = 50
+	maxDeadLetterLimit     = 500
+)
+
+type JobHandler struct {
+	Service service.JobService
+}
+
+func NewJobHandler(svc service.JobService) *JobHandler {
+	return &JobHandler{Service: svc}
+}
+
+func (h *JobHandler) ListDeadLetteredJobs(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+
+	limit := defaultDeadLetterLimit
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxDeadLetterLimit {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("limit must be an integer between 1 and %d", maxDeadLetterLimit),
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	jobs, err := h.Service.ListDeadLetteredJobs(c.Request.Context(), limit)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to list dead-lettered jobs")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to retrieve dead-lettered jobs",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, jobs)
+}
+
+func (h *JobHandler) GetJob(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+
+	jobID, ok := parseJobID(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.Service.GetJob(c.Request.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Job with ID %s not found", jobID),
+			})
+			return
+		}
+		logger.Error().Err(err).Str("job_id", jobID.String()).Msg("Failed to retrieve job")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+func (h *JobHandler) GetIncidentNotifications(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	incidentID := c.Param("id")
+
+	if _, err := uuid.Parse(incidentID); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Incident ID '%s' is not a valid UUID format.", incidentID),
+		})
+		return
+	}
+
+	attempts, err := h.Service.GetIncidentAttempts(c.Request.Context(), incidentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Incident with ID %s not found", incidentID),
+			})
+			return
+		}
+		logger.Error().Err(err).Str("incident_id", incidentID).Msg("Failed to retrieve notification attempts")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to retrieve notification attempts",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, attempts)
+}
+
+func (h *JobHandler) RequeueJob(c *gin.Context) {
+	h.applyToJob(c, h.Service.RequeueJobs, "requeue")
+}
+
+func (h *JobHandler) DiscardJob(c *gin.Context) {
+	h.applyToJob(c, h.Service.DiscardJobs, "discard")
+}
+
+func (h *JobHandler) RequeueJobs(c *gin.Context) {
+	h.applyToJobs(c, h.Service.RequeueJobs, "requeue")
+}
+
+func (h *JobHandler) DiscardJobs(c *gin.Context) {
+	h.applyToJobs(c, h.Service.DiscardJobs, "discard")
+}
+
+type jobAction func(ctx context.Context, jobIDs []uuid.UUID) ([]*model.NotificationJob, error)
+
+// applyToJob runs a dead-letter action against the job in the URL
+func (h *JobHandler) applyToJob(c *gin.Context, action jobAction, verb string) {
+	logger := middleware.GetLogger(c.Request.Context())
+
+	jobID, ok := parseJobID(c)
+	if !ok {
+		return
+	}
+
+	jobs, err := action(c.Request.Context(), []uuid.UUID{jobID})
+	if err != nil {
+		logger.Error().Err(err).Str("job_id", jobID.String()).Msgf("Failed to %s job", verb)
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("Failed to %s job", verb),
+		})
+		return
+	}
+
+	if len(jobs) == 0 {
+		c.JSON(http.StatusNotFound, model.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: fmt.Sprintf("No dead-lettered job with ID %s", jobID),
+		})
+		return
+	}
+
+	logger.Info().Str("job_id", jobID.String()).Msgf("Dead-lettered job %sd", verb)
+	c.JSON(http.StatusOK, jobs[0])
+}
+
+// applyToJobs runs a dead-letter action against every job in the request body.
+// Jobs that are unknown or not dead-lettered are skipped and left out of the response.
+func (h *JobHandler) applyToJobs(c *gin.Context, action jobAction, verb string) {
+	logger := middleware.GetLogger(c.Request.Context())
+
+	var req model.JobIDsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid request: %v", err.Error()),
+		})
+		return
+	}
+
+	jobIDs := make([]uuid.UUID, 0, len(req.JobIDs))
+	for _, raw := range req.JobIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Job ID '%s' is not a valid UUID format.", raw),
+			})
+			return
+		}
+		jobIDs = append(jobIDs, id)
+	}
+
+	jobs, err := action(c.Request.Context(), jobIDs)
+	if err != nil {
+		logger.Error().Err(err).Msgf("Failed to %s jobs", verb)
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: fmt.Sprintf("Failed to %s jobs", verb),
+		})
+		return
+	}
+
+	logger.Info().Int("requested", len(jobIDs)).Int("affected", len(jobs)).Msgf("Dead-lettered jobs %sd", verb)
+	c.JSON(http.StatusOK, jobs)
+}
+
+func parseJobID(c *gin.Context) (uuid.UUID, bool) {
+	raw := c.Param("id")
+	jobID, err := uuid.Parse(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Job ID '%s' is not a valid UUID format.", raw),
+		})
+		return uuid.Nil, false
+	}
+	return jobID, true
+}
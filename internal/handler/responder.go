@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hascho/go-incident-dashboard-api/internal/middleware"
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+	"github.com/hascho/go-incident-dashboard-api/internal/service"
+)
+
+type ResponderHandler struct {
+	Service service.ResponderService
+}
+
+func NewResponderHandler(svc service.ResponderService) *ResponderHandler {
+	return &ResponderHandler{Service: svc}
+}
+
+func (h *ResponderHandler) GetResponders(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	incidentID, ok := parseIncidentID(c)
+	if !ok {
+		return
+	}
+
+	responders, err := h.Service.GetResponders(c.Request.Context(), incidentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Incident with ID %s not found", incidentID),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to list incident responders")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to retrieve incident responders",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, responders)
+}
+
+func (h *ResponderHandler) AssignResponder(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	incidentID, ok := parseIncidentID(c)
+	if !ok {
+		return
+	}
+
+	var req model.AssignResponderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid request: %v", err.Error()),
+		})
+		return
+	}
+
+	actor := middleware.GetActor(c.Request.Context())
+
+	responder, err := h.Service.AssignResponder(c.Request.Context(), incidentID, actor, req)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Incident with ID %s not found", incidentID),
+			})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidResponder) {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to assign incident responder")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to assign responder",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, responder)
+}
+
+func (h *ResponderHandler) RemoveResponder(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	incidentID, ok := parseIncidentID(c)
+	if !ok {
+		return
+	}
+	responderID := c.Param("responder_id")
+	if _, err := uuid.Parse(responderID); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Responder ID '%s' is not a valid UUID format.", responderID),
+		})
+		return
+	}
+
+	actor := middleware.GetActor(c.Request.Context())
+
+	if err := h.Service.RemoveResponder(c.Request.Context(), incidentID, responderID, actor); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Responder with ID %s not found on incident %s", responderID, incidentID),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to remove incident responder")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error during removal.",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// parseIncidentID validates the :id path parameter, writing a 400 when it is not a UUID
+func parseIncidentID(c *gin.Context) (string, bool) {
+	incidentID := c.Param("id")
+	if _, err := uuid.Parse(incidentID); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Incident ID '%s' is not a valid UUID format.", incidentID),
+		})
+		return "", false
+	}
+	return incidentID, true
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hascho/go-incident-dashboard-api/internal/middleware"
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+	"github.com/hascho/go-incident-dashboard-api/internal/service"
+)
+
+type RoutingHandler struct {
+	Service service.RoutingService
+}
+
+func NewRoutingHandler(svc service.RoutingService) *RoutingHandler {
+	return &RoutingHandler{Service: svc}
+}
+
+func (h *RoutingHandler) CreateRoutingRule(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+
+	var req model.RoutingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid request: %v", err.Error()),
+		})
+		return
+	}
+
+	rule, err := h.Service.CreateRoutingRule(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRoutingRule) || errors.Is(err, service.ErrUnknownTeam) {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to create routing rule")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create routing rule",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+func (h *RoutingHandler) GetAllRoutingRules(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+
+	rules, err := h.Service.GetAllRoutingRules(c.Request.Context())
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to list routing rules")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to retrieve routing rules",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+func (h *RoutingHandler) GetRoutingRuleByID(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	ruleID, ok := parseRoutingRuleID(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.Service.GetRoutingRuleByID(c.Request.Context(), ruleID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Routing rule with ID %s not found", ruleID),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to retrieve routing rule")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+func (h *RoutingHandler) UpdateRoutingRule(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	ruleID, ok := parseRoutingRuleID(c)
+	if !ok {
+		return
+	}
+
+	var req model.RoutingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid request: %v", err.Error()),
+		})
+		return
+	}
+
+	rule, err := h.Service.UpdateRoutingRule(c.Request.Context(), ruleID, req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRoutingRule) || errors.Is(err, service.ErrUnknownTeam) {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Routing rule with ID %s not found", ruleID),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to update routing rule")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update routing rule",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+func (h *RoutingHandler) DeleteRoutingRule(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	ruleID, ok := parseRoutingRuleID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.DeleteRoutingRule(c.Request.Context(), ruleID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Routing rule with ID %s not found", ruleID),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to delete routing rule")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error during deletion.",
+		})
+		return
+	}
+
+	logger.Info().Str("rule_id", ruleID).Msg("Routing rule deleted")
+	c.Status(http.StatusNoContent)
+}
+
+// parseRoutingRuleID validates the :id path parameter, writing a 400 when it is not a UUID
+func parseRoutingRuleID(c *gin.Context) (string, bool) {
+	ruleID := c.Param("id")
+	if _, err := uuid.Parse(ruleID); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Routing rule ID '%s' is not a valid UUID format.", ruleID),
+		})
+		return "", false
+	}
+	return ruleID, true
+}
@@ -0,0 +1,333 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hascho/go-incident-dashboard-api/internal/middleware"
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+	"github.com/hascho/go-incident-dashboard-api/internal/service"
+)
+
+type ScheduleHandler struct {
+	Service service.ScheduleService
+}
+
+func NewScheduleHandler(svc service.ScheduleService) *ScheduleHandler {
+	return &ScheduleHandler{Service: svc}
+}
+
+func (h *ScheduleHandler) CreateSchedule(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+
+	var req model.ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid request: %v", err.Error()),
+		})
+		return
+	}
+
+	schedule, err := h.Service.CreateSchedule(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSchedule) || errors.Is(err, service.ErrUnknownTeam) {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to create schedule")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create schedule",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, schedule)
+}
+
+func (h *ScheduleHandler) GetAllSchedules(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+
+	schedules, err := h.Service.GetAllSchedules(c.Request.Context())
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to list schedules")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to retrieve schedules",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedules)
+}
+
+func (h *ScheduleHandler) GetScheduleByID(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	scheduleID, ok := parseScheduleID(c)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.Service.GetScheduleByID(c.Request.Context(), scheduleID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Schedule with ID %s not found", scheduleID),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to retrieve schedule")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+func (h *ScheduleHandler) UpdateSchedule(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	scheduleID, ok := parseScheduleID(c)
+	if !ok {
+		return
+	}
+
+	var req model.ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid request: %v", err.Error()),
+		})
+		return
+	}
+
+	schedule, err := h.Service.UpdateSchedule(c.Request.Context(), scheduleID, req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSchedule) || errors.Is(err, service.ErrUnknownTeam) {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Schedule with ID %s not found", scheduleID),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to update schedule")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update schedule",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+func (h *ScheduleHandler) DeleteSchedule(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	scheduleID, ok := parseScheduleID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.DeleteSchedule(c.Request.Context(), scheduleID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Schedule with ID %s not found", scheduleID),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to delete schedule")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error during deletion.",
+		})
+		return
+	}
+
+	logger.Info().Str("schedule_id", scheduleID).Msg("Schedule deleted")
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ScheduleHandler) GetOnCall(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	scheduleID, ok := parseScheduleID(c)
+	if !ok {
+		return
+	}
+
+	at := time.Now()
+	if raw := c.Query("at"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Query parameter 'at' must be an RFC 3339 timestamp, got '%s'.", raw),
+			})
+			return
+		}
+		at = parsed
+	}
+
+	onCall, err := h.Service.GetOnCall(c.Request.Context(), scheduleID, at)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Schedule with ID %s not found", scheduleID),
+			})
+			return
+		}
+		if errors.Is(err, service.ErrNoOnCall) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("No one is on call for schedule %s at %s", scheduleID, at.Format(time.RFC3339)),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to resolve on-call responder")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, onCall)
+}
+
+func (h *ScheduleHandler) GetOverrides(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	scheduleID, ok := parseScheduleID(c)
+	if !ok {
+		return
+	}
+
+	overrides, err := h.Service.GetUpcomingOverrides(c.Request.Context(), scheduleID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Schedule with ID %s not found", scheduleID),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to list schedule overrides")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to retrieve schedule overrides",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, overrides)
+}
+
+func (h *ScheduleHandler) CreateOverride(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	scheduleID, ok := parseScheduleID(c)
+	if !ok {
+		return
+	}
+
+	var req model.CreateOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid request: %v", err.Error()),
+		})
+		return
+	}
+
+	override, err := h.Service.CreateOverride(c.Request.Context(), scheduleID, req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSchedule) || errors.Is(err, service.ErrUnknownTeam) {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Schedule with ID %s not found", scheduleID),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to create schedule override")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create schedule override",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, override)
+}
+
+func (h *ScheduleHandler) DeleteOverride(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	scheduleID, ok := parseScheduleID(c)
+	if !ok {
+		return
+	}
+	overrideID := c.Param("override_id")
+	if _, err := uuid.Parse(overrideID); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Override ID '%s' is not a valid UUID format.", overrideID),
+		})
+		return
+	}
+
+	if err := h.Service.DeleteOverride(c.Request.Context(), scheduleID, overrideID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Override with ID %s not found on schedule %s", overrideID, scheduleID),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to delete schedule override")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error during deletion.",
+		})
+		return
+	}
+
+	logger.Info().Str("schedule_id", scheduleID).Str("override_id", overrideID).Msg("Schedule override deleted")
+	c.Status(http.StatusNoContent)
+}
+
+// parseScheduleID validates the :id path parameter, writing a 400 when it is not a UUID
+func parseScheduleID(c *gin.Context) (string, bool) {
+	scheduleID := c.Param("id")
+	if _, err := uuid.Parse(scheduleID); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Schedule ID '%s' is not a valid UUID format.", scheduleID),
+		})
+		return "", false
+	}
+	return scheduleID, true
+}
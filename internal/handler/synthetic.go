@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hascho/go-incident-dashboard-api/internal/middleware"
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+	"github.com/hascho/go-incident-dashboard-api/internal/service"
+)
+
+const (
+	defaultSyntheticResultLimit = 100
+	maxSyntheticResultLimit     = 1000
+)
+
+type SyntheticCheckHandler struct {
+	Service service.SyntheticCheckService
+}
+
+func NewSyntheticCheckHandler(svc service.SyntheticCheckService) *SyntheticCheckHandler {
+	return &SyntheticCheckHandler{Service: svc}
+}
+
+func (h *SyntheticCheckHandler) CreateSyntheticCheck(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+
+	var req model.SyntheticCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid request: %v", err.Error()),
+		})
+		return
+	}
+
+	check, err := h.Service.CreateSyntheticCheck(c.Request.Context(), req)
+	if err != nil {
+		if writeSyntheticCheckError(c, err) {
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to create synthetic check")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create synthetic check",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, check)
+}
+
+func (h *SyntheticCheckHandler) GetAllSyntheticChecks(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+
+	checks, err := h.Service.GetAllSyntheticChecks(c.Request.Context())
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to list synthetic checks")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to retrieve synthetic checks",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, checks)
+}
+
+func (h *SyntheticCheckHandler) GetSyntheticCheckByID(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	checkID, ok := parseSyntheticCheckID(c)
+	if !ok {
+		return
+	}
+
+	check, err := h.Service.GetSyntheticCheckByID(c.Request.Context(), checkID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			syntheticCheckNotFound(c, checkID)
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to retrieve synthetic check")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, check)
+}
+
+func (h *SyntheticCheckHandler) UpdateSyntheticCheck(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	checkID, ok := parseSyntheticCheckID(c)
+	if !ok {
+		return
+	}
+
+	var req model.SyntheticCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid request: %v", err.Error()),
+		})
+		return
+	}
+
+	check, err := h.Service.UpdateSyntheticCheck(c.Request.Context(), checkID, req)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			syntheticCheckNotFound(c, checkID)
+			return
+		}
+		if writeSyntheticCheckError(c, err) {
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to update synthetic check")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update synthetic check",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, check)
+}
+
+func (h *SyntheticCheckHandler) DeleteSyntheticCheck(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	checkID, ok := parseSyntheticCheckID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.DeleteSyntheticCheck(c.Request.Context(), checkID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			syntheticCheckNotFound(c, checkID)
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to delete synthetic check")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error during deletion.",
+		})
+		return
+	}
+
+	logger.Info().Str("check_id", checkID).Msg("Synthetic check deleted")
+	c.Status(http.StatusNoContent)
+}
+
+// GetResults returns the check's results since ?since (default the last 24
+// hours), newest first, at most ?limit of them.
+func (h *SyntheticCheckHandler) GetResults(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	checkID, ok := parseSyntheticCheckID(c)
+	if !ok {
+		return
+	}
+
+	since := time.Now().Add(-24 * time.Hour)
+	if raw := c.Query("since"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Query parameter 'since' must be an RFC 3339 timestamp, got '%s'.", raw),
+			})
+			return
+		}
+		since = parsed
+	}
+
+	limit := defaultSyntheticResultLimit
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxSyntheticResultLimit {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("limit must be an integer between 1 and %d", maxSyntheticResultLimit),
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	results, err := h.Service.GetResults(c.Request.Context(), checkID, since, limit)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			syntheticCheckNotFound(c, checkID)
+			return
+		}
+		logger.Error().Err(err).Str("check_id", checkID).Msg("Failed to retrieve synthetic check results")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to retrieve synthetic check results",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// writeSyntheticCheckError answers the client errors shared by create and update,
+// reporting whether it wrote a response
+func writeSyntheticCheckError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrInvalidSyntheticCheck), errors.Is(err, service.ErrUnknownTeam):
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+	case errors.Is(err, service.ErrSyntheticCheckConflict):
+		c.JSON(http.StatusConflict, model.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: err.Error(),
+		})
+	default:
+		return false
+	}
+	return true
+}
+
+func syntheticCheckNotFound(c *gin.Context, checkID string) {
+	c.JSON(http.StatusNotFound, model.ErrorResponse{
+		Code:    http.StatusNotFound,
+		Message: fmt.Sprintf("Synthetic check with ID %s not found", checkID),
+	})
+}
+
+func parseSyntheticCheckID(c *gin.Context) (string, bool) {
+	checkID := c.Param("id")
+	if _, err := uuid.Parse(checkID); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Synthetic check ID '%s' is not a valid UUID format.", checkID),
+		})
+		return "", false
+	}
+	return checkID, true
+}
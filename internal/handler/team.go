@@ -0,0 +1,302 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hascho/go-incident-dashboard-api/internal/middleware"
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+	"github.com/hascho/go-incident-dashboard-api/internal/service"
+)
+
+type TeamHandler struct {
+	Service service.TeamService
+}
+
+func NewTeamHandler(svc service.TeamService) *TeamHandler {
+	return &TeamHandler{Service: svc}
+}
+
+func (h *TeamHandler) CreateTeam(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+
+	var req model.TeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid request: %v", err.Error()),
+		})
+		return
+	}
+
+	team, err := h.Service.CreateTeam(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTeam) {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, service.ErrTeamConflict) {
+			c.JSON(http.StatusConflict, model.ErrorResponse{
+				Code:    http.StatusConflict,
+				Message: err.Error(),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to create team")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create team",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, team)
+}
+
+func (h *TeamHandler) GetAllTeams(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+
+	teams, err := h.Service.GetAllTeams(c.Request.Context())
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to list teams")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to retrieve teams",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, teams)
+}
+
+func (h *TeamHandler) GetTeamByID(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	teamID, ok := parseTeamID(c)
+	if !ok {
+		return
+	}
+
+	team, err := h.Service.GetTeamByID(c.Request.Context(), teamID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Team with ID %s not found", teamID),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to retrieve team")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, team)
+}
+
+func (h *TeamHandler) UpdateTeam(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	teamID, ok := parseTeamID(c)
+	if !ok {
+		return
+	}
+
+	var req model.TeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid request: %v", err.Error()),
+		})
+		return
+	}
+
+	team, err := h.Service.UpdateTeam(c.Request.Context(), teamID, req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTeam) {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, service.ErrTeamConflict) {
+			c.JSON(http.StatusConflict, model.ErrorResponse{
+				Code:    http.StatusConflict,
+				Message: err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Team with ID %s not found", teamID),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to update team")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update team",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, team)
+}
+
+func (h *TeamHandler) DeleteTeam(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	teamID, ok := parseTeamID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.DeleteTeam(c.Request.Context(), teamID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Team with ID %s not found", teamID),
+			})
+			return
+		}
+		if errors.Is(err, service.ErrTeamInUse) {
+			c.JSON(http.StatusConflict, model.ErrorResponse{
+				Code:    http.StatusConflict,
+				Message: err.Error(),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to delete team")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error during deletion.",
+		})
+		return
+	}
+
+	logger.Info().Str("team_id", teamID).Msg("Team deleted")
+	c.Status(http.StatusNoContent)
+}
+
+func (h *TeamHandler) GetMembers(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	teamID, ok := parseTeamID(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.Service.GetMembers(c.Request.Context(), teamID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Team with ID %s not found", teamID),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to list team members")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to retrieve team members",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+func (h *TeamHandler) AddMember(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	teamID, ok := parseTeamID(c)
+	if !ok {
+		return
+	}
+
+	var req model.AddTeamMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid request: %v", err.Error()),
+		})
+		return
+	}
+
+	if err := h.Service.AddMember(c.Request.Context(), teamID, req.UserID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Team with ID %s not found", teamID),
+			})
+			return
+		}
+		if errors.Is(err, service.ErrUnknownUser) {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to add team member")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to add team member",
+		})
+		return
+	}
+
+	logger.Info().Str("team_id", teamID).Str("user_id", req.UserID).Msg("Team member added")
+	c.Status(http.StatusNoContent)
+}
+
+func (h *TeamHandler) RemoveMember(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	teamID, ok := parseTeamID(c)
+	if !ok {
+		return
+	}
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.RemoveMember(c.Request.Context(), teamID, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("User %s is not a member of team %s", userID, teamID),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to remove team member")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error during removal.",
+		})
+		return
+	}
+
+	logger.Info().Str("team_id", teamID).Str("user_id", userID).Msg("Team member removed")
+	c.Status(http.StatusNoContent)
+}
+
+// parseTeamID validates the :id path parameter, writing a 400 when it is not a UUID
+func parseTeamID(c *gin.Context) (string, bool) {
+	teamID := c.Param("id")
+	if _, err := uuid.Parse(teamID); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Team ID '%s' is not a valid UUID format.", teamID),
+		})
+		return "", false
+	}
+	return teamID, true
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hascho/go-incident-dashboard-api/internal/middleware"
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+	"github.com/hascho/go-incident-dashboard-api/internal/service"
+)
+
+type TimelineHandler struct {
+	Service service.TimelineService
+}
+
+func NewTimelineHandler(svc service.TimelineService) *TimelineHandler {
+	return &TimelineHandler{Service: svc}
+}
+
+func (h *TimelineHandler) GetTimeline(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	incidentID := c.Param("id")
+
+	if _, err := uuid.Parse(incidentID); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Incident ID '%s' is not a valid UUID format.", incidentID),
+		})
+		return
+	}
+
+	events, err := h.Service.GetTimeline(c.Request.Context(), incidentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Incident with ID %s not found", incidentID),
+			})
+			return
+		}
+		logger.Error().Err(err).Str("incident_id", incidentID).Msg("Failed to retrieve incident timeline")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to retrieve incident timeline",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+func (h *TimelineHandler) AddNote(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	incidentID := c.Param("id")
+
+	if _, err := uuid.Parse(incidentID); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Incident ID '%s' is not a valid UUID format.", incidentID),
+		})
+		return
+	}
+
+	var req model.CreateNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Message) == "" {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request: a non-empty message is required",
+		})
+		return
+	}
+
+	actor := middleware.GetActor(c.Request.Context())
+
+	note, err := h.Service.AddNote(c.Request.Context(), incidentID, actor, req.Message)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Incident with ID %s not found", incidentID),
+			})
+			return
+		}
+		logger.Error().Err(err).Str("incident_id", incidentID).Msg("Failed to add incident note")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to add note",
+		})
+		return
+	}
+
+	logger.Info().Str("incident_id", incidentID).Str("event_id", note.ID).Msg("Note added to incident timeline")
+	c.JSON(http.StatusCreated, note)
+}
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hascho/go-incident-dashboard-api/internal/middleware"
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+	"github.com/hascho/go-incident-dashboard-api/internal/service"
+)
+
+type UserHandler struct {
+	Service service.UserService
+}
+
+func NewUserHandler(svc service.UserService) *UserHandler {
+	return &UserHandler{Service: svc}
+}
+
+func (h *UserHandler) CreateUser(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+
+	var req model.UserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid request: %v", err.Error()),
+		})
+		return
+	}
+
+	user, err := h.Service.CreateUser(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidUser) {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, service.ErrUserConflict) {
+			c.JSON(http.StatusConflict, model.ErrorResponse{
+				Code:    http.StatusConflict,
+				Message: err.Error(),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to create user")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create user",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, user)
+}
+
+func (h *UserHandler) GetAllUsers(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+
+	users, err := h.Service.GetAllUsers(c.Request.Context())
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to list users")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to retrieve users",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+func (h *UserHandler) GetUserByID(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.Service.GetUserByID(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("User with ID %s not found", userID),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to retrieve user")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *UserHandler) UpdateUser(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	var req model.UserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid request: %v", err.Error()),
+		})
+		return
+	}
+
+	user, err := h.Service.UpdateUser(c.Request.Context(), userID, req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidUser) {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, service.ErrUserConflict) {
+			c.JSON(http.StatusConflict, model.ErrorResponse{
+				Code:    http.StatusConflict,
+				Message: err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("User with ID %s not found", userID),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to update user")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update user",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.DeleteUser(c.Request.Context(), userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("User with ID %s not found", userID),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to delete user")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error during deletion.",
+		})
+		return
+	}
+
+	logger.Info().Str("user_id", userID).Msg("User deleted")
+	c.Status(http.StatusNoContent)
+}
+
+// parseUserID validates the :user_id path parameter (or :id when there is
+// none), writing a 400 when it is not a UUID
+func parseUserID(c *gin.Context) (string, bool) {
+	userID := c.Param("user_id")
+	if userID == "" {
+		userID = c.Param("id")
+	}
+	if _, err := uuid.Parse(userID); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("User ID '%s' is not a valid UUID format.", userID),
+		})
+		return "", false
+	}
+	return userID, true
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hascho/go-incident-dashboard-api/internal/middleware"
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+	"github.com/hascho/go-incident-dashboard-api/internal/service"
+)
+
+type WebhookHandler struct {
+	Service service.WebhookService
+}
+
+func NewWebhookHandler(svc service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{Service: svc}
+}
+
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+
+	var req model.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid request: %v", err.Error()),
+		})
+		return
+	}
+
+	webhook, err := h.Service.CreateWebhook(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidWebhook) {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to create webhook subscription")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create webhook subscription",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+func (h *WebhookHandler) GetAllWebhooks(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+
+	webhooks, err := h.Service.GetAllWebhooks(c.Request.Context())
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to list webhook subscriptions")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to retrieve webhook subscriptions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+func (h *WebhookHandler) GetWebhookByID(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	webhookID := c.Param("id")
+
+	if _, err := uuid.Parse(webhookID); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Webhook ID '%s' is not a valid UUID format.", webhookID),
+		})
+		return
+	}
+
+	webhook, err := h.Service.GetWebhookByID(c.Request.Context(), webhookID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Webhook with ID %s not found", webhookID),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to retrieve webhook subscription")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	logger := middleware.GetLogger(c.Request.Context())
+	webhookID := c.Param("id")
+
+	if _, err := uuid.Parse(webhookID); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Webhook ID '%s' is not a valid UUID format.", webhookID),
+		})
+		return
+	}
+
+	if err := h.Service.DeleteWebhook(c.Request.Context(), webhookID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Webhook with ID %s not found", webhookID),
+			})
+			return
+		}
+		logger.Error().Err(err).Msg("Failed to delete webhook subscription")
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error during deletion.",
+		})
+		return
+	}
+
+	logger.Info().Str("webhook_id", webhookID).Msg("Webhook subscription deleted")
+	c.Status(http.StatusNoContent)
+}
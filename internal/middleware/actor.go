@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const ActorHeader = "X-Actor"
+
+const ActorContextKey contextKey = "actor"
+
+// AnonymousActor is recorded when a request does not identify its caller
+const AnonymousActor = "anonymous"
+
+// Actor reads the caller identity from the X-Actor header and injects it into context
+func Actor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor := strings.TrimSpace(c.GetHeader(ActorHeader))
+		if actor == "" {
+			actor = AnonymousActor
+		}
+
+		ctx := context.WithValue(c.Request.Context(), ActorContextKey, actor)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// GetActor is a helper function to safely retrieve the caller identity
+func GetActor(ctx context.Context) string {
+	if actor, ok := ctx.Value(ActorContextKey).(string); ok {
+		return actor
+	}
+	return AnonymousActor
+}
@@ -0,0 +1,75 @@
+package model
+
+import "time"
+
+// Alertmanager notification statuses.
+const (
+	AlertFiring   = "firing"
+	AlertResolved = "resolved"
+)
+
+// AlertSourceAlertmanager marks alerts ingested from Prometheus Alertmanager.
+const AlertSourceAlertmanager = "alertmanager"
+
+// Outcomes of ingesting an alert notification.
+const (
+	AlertActionCreated   = "created"
+	AlertActionGrouped   = "grouped" // added as an occurrence of an active incident with the same fingerprint
+	AlertActionReopened  = "reopened"
+	AlertActionResolved  = "resolved"
+	AlertActionUnchanged = "unchanged"
+	AlertActionIgnored   = "ignored"
+)
+
+// AlertmanagerPayload is the body of an Alertmanager webhook notification
+// (version 4). Each notification describes one alert group.
+type AlertmanagerPayload struct {
+	Version           string              `json:"version"`
+	GroupKey          string              `json:"groupKey" binding:"required"`
+	TruncatedAlerts   int                 `json:"truncatedAlerts"`
+	Status            string              `json:"status" binding:"required,oneof=firing resolved"`
+	Receiver          string              `json:"receiver"`
+	GroupLabels       map[string]string   `json:"groupLabels"`
+	CommonLabels      map[string]string   `json:"commonLabels"`
+	CommonAnnotations map[string]string   `json:"commonAnnotations"`
+	ExternalURL       string              `json:"externalURL"`
+	Alerts            []AlertmanagerAlert `json:"alerts"`
+}
+
+type AlertmanagerAlert struct {
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+// AlertRule maps alerts onto a team and severity. Matchers are regular
+// expressions that must match the whole label value; an empty Team or
+// Severity leaves it to the alert's own team and severity labels.
+type AlertRule struct {
+	Matchers map[string]string `json:"matchers"`
+	Team     string            `json:"team"`
+	Severity string            `json:"severity"`
+}
+
+// ExternalAlert links an alert group from a monitoring system to the incident
+// it opened.
+type ExternalAlert struct {
+	ID          string            `json:"id"`
+	Source      string            `json:"source"`
+	Fingerprint string            `json:"fingerprint"`
+	IncidentID  string            `json:"incident_id"`
+	Status      string            `json:"status"`
+	Labels      map[string]string `json:"labels"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// AlertIngestResult reports what a notification did.
+type AlertIngestResult struct {
+	Action     string `json:"action"`
+	IncidentID string `json:"incident_id,omitempty"`
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// NotificationAttempt records a single delivery attempt of a notification job.
+type NotificationAttempt struct {
+	ID           string    `json:"id"`
+	JobID        string    `json:"job_id"`
+	IncidentID   string    `json:"incident_id"`
+	WorkerID     string    `json:"worker_id"`
+	Channel      string    `json:"channel"`
+	Attempt      int       `json:"attempt"`
+	Success      bool      `json:"success"`
+	StartedAt    time.Time `json:"started_at"`
+	FinishedAt   time.Time `json:"finished_at"`
+	DurationMS   int64     `json:"duration_ms"`
+	ErrorMessage string    `json:"error_message,omitempty"`
+	ResponseCode *int      `json:"response_code,omitempty"` // provider response code, when the channel has one
+}
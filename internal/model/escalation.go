@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// EscalationPolicy pages a team's unacknowledged incidents level by level until
+// someone acknowledges them.
+type EscalationPolicy struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Team       string            `json:"team"`
+	Severities []string          `json:"severities"` // empty escalates every severity
+	Levels     []EscalationLevel `json:"levels"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+// EscalationLevel is paged DelayMinutes after the previous level, or after the
+// incident was opened for the first level.
+type EscalationLevel struct {
+	DelayMinutes int             `json:"delay_minutes" binding:"min=0"`
+	Targets      []RoutingTarget `json:"targets" binding:"required,min=1,dive"`
+}
+
+// EscalationPolicyRequest creates a policy, or replaces one on update.
+type EscalationPolicyRequest struct {
+	Name       string            `json:"name" binding:"required"`
+	Team       string            `json:"team" binding:"required"`
+	Severities []string          `json:"severities"`
+	Levels     []EscalationLevel `json:"levels" binding:"required,min=1,dive"`
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Incident timeline event types.
+const (
+	EventCreated            = "created"
+	EventStatusChanged      = "status_changed"
+	EventSeverityChanged    = "severity_changed"
+	EventDescriptionUpdated = "description_updated"
+	EventEscalated          = "escalated"
+	EventResponderAssigned  = "responder_assigned"
+	EventResponderRemoved   = "responder_removed"
+	EventNotificationSent   = "notification_sent"
+	EventNotificationFailed = "notification_failed"
+	EventNote               = "note"
+	EventOccurred           = "occurred" // a duplicate report grouped into the incident
+)
+
+// IncidentEvent is a single entry in an incident's chronological timeline.
+type IncidentEvent struct {
+	ID         string          `json:"id"`
+	IncidentID string          `json:"incident_id"`
+	Type       string          `json:"type"`
+	Actor      string          `json:"actor"`
+	Message    string          `json:"message"`
+	Metadata   json.RawMessage `json:"metadata,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type CreateNoteRequest struct {
+	Message string `json:"message" binding:"required"`
+}
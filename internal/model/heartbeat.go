@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// Heartbeat statuses. A new heartbeat has never pinged; it goes down if its
+// first ping does not arrive within an interval and grace of its creation.
+const (
+	HeartbeatNew  = "new"
+	HeartbeatUp   = "up"
+	HeartbeatDown = "down"
+)
+
+// Heartbeat is a dead-man's switch: something (usually a cron job) pings it
+// at least every IntervalSeconds, and an incident is opened for Team when a
+// ping is more than GraceSeconds late.
+type Heartbeat struct {
+	ID              string     `json:"id"`
+	Name            string     `json:"name"`
+	Team            string     `json:"team"`
+	Severity        string     `json:"severity"`
+	IntervalSeconds int        `json:"interval_seconds"`
+	GraceSeconds    int        `json:"grace_seconds"`
+	Status          string     `json:"status"`
+	LastPingAt      *time.Time `json:"last_ping_at,omitempty"`
+	IncidentID      *string    `json:"incident_id,omitempty"` // the open incident while the heartbeat is down
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// HeartbeatRequest creates a heartbeat, or replaces one on update.
+type HeartbeatRequest struct {
+	Name            string `json:"name" binding:"required"`
+	Team            string `json:"team" binding:"required"`
+	Severity        string `json:"severity"`
+	IntervalSeconds int    `json:"interval_seconds" binding:"required,min=1"`
+	GraceSeconds    int    `json:"grace_seconds" binding:"min=0"`
+}
@@ -2,34 +2,175 @@ package model
 
 import "time"
 
+// Incident lifecycle statuses, in their usual order of progression.
+const (
+	StatusOpen          = "open"
+	StatusAcknowledged  = "acknowledged"
+	StatusInvestigating = "investigating"
+	StatusMitigated     = "mitigated"
+	StatusResolved      = "resolved"
+	StatusClosed        = "closed"
+)
+
+// Incident event types delivered to notification channels and webhooks.
+const (
+	EventTypeIncidentCreated   = "incident.created"
+	EventTypeIncidentUpdated   = "incident.updated"
+	EventTypeIncidentResolved  = "incident.resolved"
+	EventTypeIncidentReopened  = "incident.reopened"
+	EventTypeIncidentEscalated = "incident.escalated"
+	EventTypeIncidentAssigned  = "incident.assigned"
+	EventTypeIncidentDeleted   = "incident.deleted"
+)
+
+// IncidentEventTypes lists every event type a subscriber can receive.
+var IncidentEventTypes = []string{
+	EventTypeIncidentCreated,
+	EventTypeIncidentUpdated,
+	EventTypeIncidentResolved,
+	EventTypeIncidentReopened,
+	EventTypeIncidentEscalated,
+	EventTypeIncidentAssigned,
+	EventTypeIncidentDeleted,
+}
+
+// Notification statuses reported back onto the incident by the worker.
+const (
+	NotificationPending = "pending"
+	NotificationSent    = "sent"
+	NotificationFailed  = "failed"
+)
+
 type Incident struct {
-	ID                 string    `json:"id"`
-	Title              string    `json:"title"`
-	Description        string    `json:"description"`
-	Status             string    `json:"status"`
-	Severity           string    `json:"severity"`
-	Team               string    `json:"team"`
-	NotificationStatus string    `json:"notification_status"`
-	CreatedAt          time.Time `json:"created_at"`
-	UpdatedAt          time.Time `json:"updated_at"`
+	ID                 string     `json:"id"`
+	Title              string     `json:"title"`
+	Description        string     `json:"description"`
+	Status             string     `json:"status"`
+	Severity           string     `json:"severity"`
+	Team               string     `json:"team"`
+	Tags               []string   `json:"tags"`
+	Assignee           *string    `json:"assignee,omitempty"` // the incident commander
+	NotificationStatus string     `json:"notification_status"`
+	AcknowledgedAt     *time.Time `json:"acknowledged_at,omitempty"` // first acknowledgement, kept across reopens
+	AcknowledgedBy     *string    `json:"acknowledged_by,omitempty"`
+	Fingerprint        string     `json:"fingerprint"`      // occurrences with the same fingerprint group into one incident
+	OccurrenceCount    int        `json:"occurrence_count"` // how many times the incident was reported, including the first
+	LastOccurredAt     time.Time  `json:"last_occurred_at"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
 }
 
 type CreateIncidentRequest struct {
-	Title       string `json:"title" binding:"required"`
-	Description string `json:"description"`
-	Severity    string `json:"severity" binding:"required"`
-	Team        string `json:"team" binding:"required"`
+	Title       string   `json:"title" binding:"required"`
+	Description string   `json:"description"`
+	Severity    string   `json:"severity" binding:"required"`
+	Team        string   `json:"team" binding:"required"`
+	Tags        []string `json:"tags"`
+	Fingerprint string   `json:"fingerprint" binding:"max=255"` // derived from team and title when empty
 }
 
 type IncidentResponse struct {
-	ID       string `json:"id"`
-	Title    string `json:"title"`
-	Status   string `json:"status"`
-	Severity string `json:"severity"`
-	Team     string `json:"team"`
+	ID             string     `json:"id"`
+	Title          string     `json:"title"`
+	Status         string     `json:"status"`
+	Severity       string     `json:"severity"`
+	Team           string     `json:"team"`
+	Assignee       *string    `json:"assignee,omitempty"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy *string    `json:"acknowledged_by,omitempty"`
+	Fingerprint    string     `json:"fingerprint,omitempty"`
+	Occurrences    int        `json:"occurrence_count,omitempty"`
 }
 
 type UpdateIncidentRequest struct {
 	Status      *string `json:"status"`
+	Severity    *string `json:"severity"`
 	Description *string `json:"description"`
+	Reason      *string `json:"reason"` // recorded against the status transition, if any
+}
+
+// Incident listing sort fields and directions.
+const (
+	SortCreatedAt = "created_at"
+	SortUpdatedAt = "updated_at"
+
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+// IncidentFilter narrows, orders and pages an incident listing. Empty fields
+// match everything.
+type IncidentFilter struct {
+	Assignee      string
+	Statuses      []string
+	Severities    []string // matched ignoring case
+	Team          string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	Sort          string // SortCreatedAt or SortUpdatedAt
+	Direction     string // SortAsc or SortDesc
+	Limit         int
+	Cursor        string // NextCursor of the previous page
+}
+
+// IncidentPage is one page of an incident listing. NextCursor is empty on the
+// last page.
+type IncidentPage struct {
+	Incidents  []*Incident
+	NextCursor string
+}
+
+type IncidentListResponse struct {
+	Incidents  []IncidentResponse `json:"incidents"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+// FieldChange is one incident field changed by an update, carried on the
+// notifications for that update.
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// MTTAFilter narrows the incidents that mean-time-to-acknowledge is computed over.
+type MTTAFilter struct {
+	Team     string
+	Severity string
+	Since    time.Time // only incidents created at or after this time
+}
+
+// MTTAStat is the mean time to acknowledge the incidents of one team and severity.
+type MTTAStat struct {
+	Team         string  `json:"team"`
+	Severity     string  `json:"severity"`
+	Acknowledged int     `json:"acknowledged"` // incidents the mean is taken over
+	MTTASeconds  float64 `json:"mtta_seconds"`
+}
+
+// IncidentSearch is a parsed full-text search: free text in websearch syntax
+// plus the qualifiers pulled out of the query.
+type IncidentSearch struct {
+	Text       string
+	Team       string
+	Severities []string
+	Statuses   []string
+	Limit      int
+}
+
+// IncidentSearchHit is an incident matching a search, with its relevance and a
+// snippet of the matching text, HTML-escaped, with the terms wrapped in <mark>.
+type IncidentSearchHit struct {
+	Incident *Incident
+	Rank     float64
+	Snippet  string
+}
+
+type IncidentSearchResult struct {
+	IncidentResponse
+	CreatedAt time.Time `json:"created_at"`
+	Rank      float64   `json:"rank"`
+	Snippet   string    `json:"snippet"`
 }
@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// AlertSourceIntegration prefixes the alert source of events ingested through
+// an integration; the integration's ID follows it.
+const AlertSourceIntegration = "integration"
+
+// Integration is an inbound event source with its own ingestion URL and
+// token. Its mapping turns the source's JSON payloads into incidents.
+type Integration struct {
+	ID        string             `json:"id"`
+	Name      string             `json:"name"`
+	Mapping   IntegrationMapping `json:"mapping"`
+	TokenHash string             `json:"-"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+// IntegrationMapping maps payload fields onto an incident. Each field is
+// either a JSONPath-style expression starting with "$" (e.g.
+// "$.alert.labels['team']" or "$.records[0].title") or a literal value.
+type IntegrationMapping struct {
+	Title       string            `json:"title"`
+	Description string            `json:"description,omitempty"` // defaults to the payload itself
+	Severity    string            `json:"severity,omitempty"`
+	SeverityMap map[string]string `json:"severity_map,omitempty"` // translates source severities, e.g. "P1" to "critical"
+	Team        string            `json:"team"`
+	DedupKey    string            `json:"dedup_key,omitempty"` // events with the same key belong to the same incident; defaults to the title
+	Resolve     *ResolveCondition `json:"resolve,omitempty"`
+}
+
+// ResolveCondition marks an event as resolving its incident when the value at
+// Path equals one of Values, ignoring case.
+type ResolveCondition struct {
+	Path   string   `json:"path"`
+	Values []string `json:"values"`
+}
+
+type IntegrationRequest struct {
+	Name    string             `json:"name" binding:"required"`
+	Mapping IntegrationMapping `json:"mapping"`
+}
+
+// IntegrationWithToken is returned when an integration is created or its
+// token rotated; the token is not stored and cannot be shown again.
+type IntegrationWithToken struct {
+	*Integration
+	Token     string `json:"token"`
+	IngestURL string `json:"ingest_url"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// NotificationJob is the API view of a row in the notification_jobs table.
+type NotificationJob struct {
+	ID            string          `json:"id"`
+	IncidentID    string          `json:"incident_id"`
+	Type          string          `json:"type"`
+	EventType     string          `json:"event_type"`
+	Channel       string          `json:"channel"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	LastError     string          `json:"last_error,omitempty"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// NotificationJobDetail is a single job together with its attempt history.
+type NotificationJobDetail struct {
+	NotificationJob
+	Attempts []*NotificationAttempt `json:"attempts"`
+}
+
+type JobIDsRequest struct {
+	JobIDs []string `json:"job_ids" binding:"required,min=1"`
+}
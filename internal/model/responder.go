@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// Incident responder roles. The commander is the incident's assignee.
+const (
+	RoleCommander = "commander"
+	RoleScribe    = "scribe"
+	RoleCommsLead = "comms_lead"
+)
+
+// ResponderRoles lists every role a responder can be assigned.
+var ResponderRoles = []string{RoleCommander, RoleScribe, RoleCommsLead}
+
+// IncidentResponder is a person working an incident in a given role.
+type IncidentResponder struct {
+	ID         string    `json:"id"`
+	IncidentID string    `json:"incident_id"`
+	Role       string    `json:"role"`
+	Responder  Responder `json:"responder"`
+	AssignedBy string    `json:"assigned_by"`
+	AssignedAt time.Time `json:"assigned_at"`
+}
+
+// AssignResponderRequest assigns a person to an incident. Assigning someone who
+// already responds to the incident changes their role.
+type AssignResponderRequest struct {
+	Role      string    `json:"role" binding:"required"`
+	Responder Responder `json:"responder" binding:"required"`
+}
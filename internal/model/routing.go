@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// RoutingRule fans matching incident events out to one or more notification
+// targets. Empty match fields match every incident.
+type RoutingRule struct {
+	ID           string          `json:"id"`
+	Name         string          `json:"name"`
+	Team         string          `json:"team"`
+	Severity     string          `json:"severity"`
+	TitlePattern string          `json:"title_pattern"`
+	Tags         []string        `json:"tags"`
+	Events       []string        `json:"events"`
+	Targets      []RoutingTarget `json:"targets"`
+	Enabled      bool            `json:"enabled"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+// RoutingTarget is a notifier channel and its channel specific destination.
+type RoutingTarget struct {
+	Channel     string `json:"channel" binding:"required"`
+	Destination string `json:"destination"` // empty means the notifier's default
+}
+
+// RoutingRuleRequest creates a rule, or replaces one on update.
+type RoutingRuleRequest struct {
+	Name         string          `json:"name" binding:"required"`
+	Team         string          `json:"team"`
+	Severity     string          `json:"severity"`
+	TitlePattern string          `json:"title_pattern"`
+	Tags         []string        `json:"tags"`
+	Events       []string        `json:"events"` // defaults to every event type
+	Targets      []RoutingTarget `json:"targets" binding:"required,min=1,dive"`
+	Enabled      *bool           `json:"enabled"` // defaults to true
+}
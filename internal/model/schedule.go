@@ -0,0 +1,82 @@
+package model
+
+import "time"
+
+// Schedule rotations.
+const (
+	RotationDaily  = "daily"
+	RotationWeekly = "weekly"
+)
+
+// Schedule says who is on call for a team at any moment. Layers are stacked in
+// order: the last layer covering a moment decides, and an override beats them all.
+type Schedule struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Team      string          `json:"team"`
+	TimeZone  string          `json:"time_zone"`
+	Layers    []ScheduleLayer `json:"layers"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// ScheduleLayer rotates through its participants, handing off every day or
+// week at HandoffTime in the schedule's time zone. The first shift starts on
+// StartDate; weekly rotations hand off on that weekday.
+type ScheduleLayer struct {
+	Name         string               `json:"name"`
+	Rotation     string               `json:"rotation" binding:"required,oneof=daily weekly"`
+	StartDate    string               `json:"start_date" binding:"required"`   // YYYY-MM-DD
+	HandoffTime  string               `json:"handoff_time" binding:"required"` // HH:MM
+	Restriction  *ScheduleRestriction `json:"restriction,omitempty"`
+	Participants []Responder          `json:"participants" binding:"required,min=1,dive"`
+}
+
+// ScheduleRestriction limits a layer to a daily window, e.g. business hours.
+// The window may wrap past midnight.
+type ScheduleRestriction struct {
+	StartTime string `json:"start_time" binding:"required"` // HH:MM
+	EndTime   string `json:"end_time" binding:"required"`   // HH:MM
+}
+
+// Responder is a person who can be on call, and how to reach them.
+type Responder struct {
+	Name        string `json:"name" binding:"required"`
+	Channel     string `json:"channel" binding:"required"`
+	Destination string `json:"destination"`
+}
+
+// ScheduleOverride puts Responder on call for a fixed period, e.g. to cover a shift.
+type ScheduleOverride struct {
+	ID         string    `json:"id"`
+	ScheduleID string    `json:"schedule_id"`
+	Responder  Responder `json:"responder"`
+	StartsAt   time.Time `json:"starts_at"`
+	EndsAt     time.Time `json:"ends_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ScheduleRequest creates a schedule, or replaces one on update.
+type ScheduleRequest struct {
+	Name     string          `json:"name" binding:"required"`
+	Team     string          `json:"team" binding:"required"`
+	TimeZone string          `json:"time_zone" binding:"required"`
+	Layers   []ScheduleLayer `json:"layers" binding:"required,min=1,dive"`
+}
+
+type CreateOverrideRequest struct {
+	Responder Responder `json:"responder" binding:"required"`
+	StartsAt  time.Time `json:"starts_at" binding:"required"`
+	EndsAt    time.Time `json:"ends_at" binding:"required"`
+}
+
+// OnCall is the responder on call for a schedule at a moment, and the shift
+// that puts them there.
+type OnCall struct {
+	ScheduleID string    `json:"schedule_id"`
+	At         time.Time `json:"at"`
+	Responder  Responder `json:"responder"`
+	Source     string    `json:"source"` // "override" or the layer name
+	ShiftStart time.Time `json:"shift_start"`
+	ShiftEnd   time.Time `json:"shift_end"`
+}
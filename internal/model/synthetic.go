@@ -0,0 +1,74 @@
+package model
+
+import "time"
+
+// Synthetic check types
+const (
+	SyntheticCheckHTTP = "http"
+	SyntheticCheckTCP  = "tcp"
+)
+
+// Synthetic check statuses. A new check is neither up nor down until its
+// first success or until it reaches its failure threshold.
+const (
+	SyntheticCheckNew  = "new"
+	SyntheticCheckUp   = "up"
+	SyntheticCheckDown = "down"
+)
+
+// SyntheticCheck probes a URL or TCP port every IntervalSeconds. After
+// FailureThreshold failures in a row an incident is opened for Team, and the
+// next success resolves it.
+type SyntheticCheck struct {
+	ID                  string     `json:"id"`
+	Name                string     `json:"name"`
+	Type                string     `json:"type"`
+	URL                 string     `json:"url,omitempty"`
+	Method              string     `json:"method,omitempty"`
+	ExpectedStatus      *int       `json:"expected_status,omitempty"` // any 2xx when unset
+	BodyContains        string     `json:"body_contains,omitempty"`
+	Host                string     `json:"host,omitempty"`
+	Port                *int       `json:"port,omitempty"`
+	IntervalSeconds     int        `json:"interval_seconds"`
+	TimeoutSeconds      int        `json:"timeout_seconds"`
+	FailureThreshold    int        `json:"failure_threshold"`
+	Team                string     `json:"team"`
+	Severity            string     `json:"severity"`
+	Status              string     `json:"status"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastCheckedAt       *time.Time `json:"last_checked_at,omitempty"`
+	NextRunAt           time.Time  `json:"next_run_at"`
+	IncidentID          *string    `json:"incident_id,omitempty"` // the open incident while the check is down
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// SyntheticCheckRequest creates a synthetic check, or replaces one on update.
+// HTTP checks use URL, Method, ExpectedStatus and BodyContains; TCP checks
+// use Host and Port.
+type SyntheticCheckRequest struct {
+	Name             string `json:"name" binding:"required"`
+	Type             string `json:"type" binding:"required,oneof=http tcp"`
+	URL              string `json:"url"`
+	Method           string `json:"method"`
+	ExpectedStatus   *int   `json:"expected_status"`
+	BodyContains     string `json:"body_contains"`
+	Host             string `json:"host"`
+	Port             *int   `json:"port"`
+	IntervalSeconds  int    `json:"interval_seconds" binding:"required,min=1"`
+	TimeoutSeconds   int    `json:"timeout_seconds" binding:"min=0"`
+	FailureThreshold int    `json:"failure_threshold" binding:"min=0"`
+	Team             string `json:"team" binding:"required"`
+	Severity         string `json:"severity"`
+}
+
+// SyntheticCheckResult is the outcome of one run of a synthetic check.
+type SyntheticCheckResult struct {
+	ID           string    `json:"id"`
+	CheckID      string    `json:"check_id"`
+	CheckedAt    time.Time `json:"checked_at"`
+	Success      bool      `json:"success"`
+	DurationMS   int64     `json:"duration_ms"`
+	StatusCode   *int      `json:"status_code,omitempty"` // HTTP checks that got a response
+	ErrorMessage string    `json:"error_message,omitempty"`
+}
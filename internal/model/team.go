@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+// Team owns incidents. Names are unique ignoring case, spaces and punctuation.
+type Team struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Slug        string    `json:"slug"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TeamRequest creates a team, or replaces one on update. Renaming a team
+// renames it on its incidents.
+type TeamRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+// User is a person who can respond to incidents.
+type User struct {
+	ID             string          `json:"id"`
+	Name           string          `json:"name"` // handle, as sent in X-Actor
+	Email          string          `json:"email"`
+	ContactMethods []ContactMethod `json:"contact_methods"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// ContactMethod is one way of reaching a user through a notification channel.
+type ContactMethod struct {
+	Channel     string `json:"channel" binding:"required"`
+	Destination string `json:"destination"`
+}
+
+// UserRequest creates a user, or replaces one on update.
+type UserRequest struct {
+	Name           string          `json:"name" binding:"required"`
+	Email          string          `json:"email"`
+	ContactMethods []ContactMethod `json:"contact_methods" binding:"dive"`
+}
+
+type AddTeamMemberRequest struct {
+	UserID string `json:"user_id" binding:"required,uuid"`
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// StatusTransition is a single audited change of an incident's lifecycle status.
+type StatusTransition struct {
+	ID         string    `json:"id"`
+	IncidentID string    `json:"incident_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Actor      string    `json:"actor"`
+	Reason     string    `json:"reason,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
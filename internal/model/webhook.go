@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// WebhookSubscription is an external HTTPS endpoint that receives incident events.
+type WebhookSubscription struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"` // only returned when the subscription is created
+	Events    []string  `json:"events"`           // empty means every event type
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"` // generated when omitted
+}
+
+// WebhookEvent is the JSON body delivered to webhook subscribers.
+type WebhookEvent struct {
+	ID        string        `json:"id"` // stable across retries, so receivers can deduplicate
+	Type      string        `json:"type"`
+	CreatedAt time.Time     `json:"created_at"`
+	Data      *Incident     `json:"data"`
+	Changes   []FieldChange `json:"changes,omitempty"` // fields changed by an update
+}
@@ -0,0 +1,258 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+const ChannelEmail = "email"
+
+//go:embed templates/email.txt.tmpl templates/email.html.tmpl
+var emailTemplates embed.FS
+
+var (
+	emailTextTemplate = texttemplate.Must(texttemplate.ParseFS(emailTemplates, "templates/email.txt.tmpl"))
+	emailHTMLTemplate = htmltemplate.Must(htmltemplate.ParseFS(emailTemplates, "templates/email.html.tmpl"))
+)
+
+// EmailConfig holds the SMTP relay settings for the email notifier.
+type EmailConfig struct {
+	Host         string
+	Port         int
+	Username     string // leave empty to skip SMTP AUTH
+	Password     string
+	From         string
+	To           []string // used when a job has no destination of its own
+	StartTLS     bool     // require STARTTLS before authenticating and sending
+	DashboardURL string
+}
+
+// EmailNotifier sends multipart HTML/plaintext incident emails over SMTP.
+type EmailNotifier struct {
+	Config    EmailConfig
+	TLSConfig *tls.Config // overrides the default STARTTLS configuration, e.g. for a private CA
+	Timeout   time.Duration
+}
+
+func NewEmailNotifier(cfg EmailConfig) *EmailNotifier {
+	return &EmailNotifier{
+		Config:  cfg,
+		Timeout: 30 * time.Second,
+	}
+}
+
+func (n *EmailNotifier) Channel() string {
+	return ChannelEmail
+}
+
+// Send delivers one email. A job destination is a comma separated list of recipients.
+func (n *EmailNotifier) Send(ctx context.Context, notification Notification) (Result, error) {
+	recipients := n.Config.To
+	if notification.Destination != "" {
+		recipients = splitAddresses(notification.Destination)
+	}
+	if len(recipients) == 0 {
+		return Result{}, fmt.Errorf("%w: email: no recipients configured", ErrPermanent)
+	}
+	if n.Config.Host == "" {
+		return Result{}, fmt.Errorf("%w: email: no SMTP host configured", ErrPermanent)
+	}
+
+	msg, err := n.buildMessage(notification, recipients)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if err := n.deliver(ctx, recipients, msg); err != nil {
+		// surface the SMTP reply code for the attempt log when the server rejected us.
+		// A 5xx reply (unknown mailbox, bad credentials) stays rejected; 4xx is transient.
+		var smtpErr *textproto.Error
+		if errors.As(err, &smtpErr) {
+			if smtpErr.Code >= 500 {
+				return Result{StatusCode: smtpErr.Code}, fmt.Errorf("%w: email: %w", ErrPermanent, err)
+			}
+			return Result{StatusCode: smtpErr.Code}, fmt.Errorf("email: %w", err)
+		}
+		return Result{}, fmt.Errorf("email: %w", err)
+	}
+	return Result{StatusCode: 250}, nil
+}
+
+func (n *EmailNotifier) deliver(ctx context.Context, recipients []string, msg []byte) error {
+	addr := net.JoinHostPort(n.Config.Host, strconv.Itoa(n.Config.Port))
+
+	dialer := &net.Dialer{Timeout: n.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+
+	// bound the whole SMTP conversation, not just the dial
+	deadline := time.Now().Add(n.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, n.Config.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if n.Config.StartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("server does not support STARTTLS")
+		}
+		tlsConfig := n.TLSConfig
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{ServerName: n.Config.Host}
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("STARTTLS failed: %w", err)
+		}
+	}
+
+	if n.Config.Username != "" {
+		auth := smtp.PlainAuth("", n.Config.Username, n.Config.Password, n.Config.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(n.Config.From); err != nil {
+		return err
+	}
+	for _, rcpt := range recipients {
+		if err := client.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+type emailData struct {
+	Notification
+	Label   string   // what happened, e.g. "Incident resolved"
+	Changes []string // one line per changed field
+	Link    string
+}
+
+func (n *EmailNotifier) buildMessage(notification Notification, recipients []string) ([]byte, error) {
+	data := emailData{
+		Notification: notification,
+		Label:        eventLabel(notification.EventType),
+		Changes:      changeLines(notification.Changes),
+		Link:         incidentURL(n.Config.DashboardURL, notification.Incident.ID),
+	}
+
+	var text, html bytes.Buffer
+	if err := emailTextTemplate.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("email: failed to render plaintext body: %w", err)
+	}
+	if err := emailHTMLTemplate.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("email: failed to render HTML body: %w", err)
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	headers := []struct{ key, value string }{
+		{"From", n.Config.From},
+		{"To", strings.Join(recipients, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", headline(notification))},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", messageID(n.Config.From)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", mw.Boundary())},
+	}
+
+	var msg bytes.Buffer
+	for _, h := range headers {
+		fmt.Fprintf(&msg, "%s: %s\r\n", h.key, h.value)
+	}
+	msg.WriteString("\r\n")
+
+	// plaintext first: clients render the last alternative they understand
+	for _, part := range []struct {
+		contentType string
+		content     []byte
+	}{
+		{"text/plain; charset=utf-8", text.Bytes()},
+		{"text/html; charset=utf-8", html.Bytes()},
+	} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("email: failed to create MIME part: %w", err)
+		}
+		qp := quotedprintable.NewWriter(pw)
+		if _, err := qp.Write(part.content); err != nil {
+			return nil, fmt.Errorf("email: failed to encode MIME part: %w", err)
+		}
+		if err := qp.Close(); err != nil {
+			return nil, fmt.Errorf("email: failed to encode MIME part: %w", err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("email: failed to finish MIME body: %w", err)
+	}
+
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+func splitAddresses(list string) []string {
+	var out []string
+	for _, addr := range strings.Split(list, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			out = append(out, addr)
+		}
+	}
+	return out
+}
+
+func messageID(from string) string {
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = strings.Trim(from[at+1:], "> ")
+	}
+	buf := make([]byte, 12)
+	_, _ = rand.Read(buf)
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(buf), domain)
+}
@@ -0,0 +1,370 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/http/httptest"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+)
+
+// smtpSink is an in-process SMTP server that accepts one message per
+// connection and records the conversation.
+type smtpSink struct {
+	listener net.Listener
+	tls      *tls.Config
+	username string
+	password string
+	// rcptReply, when set, is sent in answer to RCPT instead of accepting it
+	rcptReply string
+
+	mu        sync.Mutex
+	tlsUsed   bool
+	authedTLS bool // AUTH succeeded over TLS
+	from      string
+	rcpts     []string
+	data      string
+}
+
+// newSMTPSink serves SMTP on a local port, offering STARTTLS with the
+// certificate httptest uses for its TLS servers
+func newSMTPSink(t *testing.T, username string, password string) (*smtpSink, *x509.CertPool) {
+	t.Helper()
+
+	certServer := httptest.NewTLSServer(nil)
+	certServer.Close()
+	roots := x509.NewCertPool()
+	roots.AddCert(certServer.Certificate())
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	sink := &smtpSink{
+		listener: listener,
+		tls:      certServer.TLS,
+		username: username,
+		password: password,
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go sink.serve(conn)
+		}
+	}()
+	return sink, roots
+}
+
+func (s *smtpSink) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpSink) serve(conn net.Conn) {
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+	secure := false
+
+	text.PrintfLine("220 sink ESMTP ready")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			if secure {
+				text.PrintfLine("250-sink\r\n250 AUTH PLAIN")
+			} else {
+				text.PrintfLine("250-sink\r\n250-STARTTLS\r\n250 AUTH PLAIN")
+			}
+		case "STARTTLS":
+			text.PrintfLine("220 2.0.0 ready to start TLS")
+			tlsConn := tls.Server(conn, s.tls)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, secure = tlsConn, true
+			text = textproto.NewConn(conn)
+			s.mu.Lock()
+			s.tlsUsed = true
+			s.mu.Unlock()
+		case "AUTH":
+			mechanism, initial, _ := strings.Cut(arg, " ")
+			decoded, _ := base64.StdEncoding.DecodeString(initial)
+			if mechanism != "PLAIN" || string(decoded) != "\x00"+s.username+"\x00"+s.password {
+				text.PrintfLine("535 5.7.8 authentication credentials invalid")
+				continue
+			}
+			s.mu.Lock()
+			s.authedTLS = secure
+			s.mu.Unlock()
+			text.PrintfLine("235 2.7.0 authentication successful")
+		case "MAIL":
+			s.mu.Lock()
+			s.from = strings.TrimSuffix(strings.TrimPrefix(arg, "FROM:<"), ">")
+			s.mu.Unlock()
+			text.PrintfLine("250 2.1.0 ok")
+		case "RCPT":
+			if s.rcptReply != "" {
+				text.PrintfLine("%s", s.rcptReply)
+				continue
+			}
+			s.mu.Lock()
+			s.rcpts = append(s.rcpts, strings.TrimSuffix(strings.TrimPrefix(arg, "TO:<"), ">"))
+			s.mu.Unlock()
+			text.PrintfLine("250 2.1.5 ok")
+		case "DATA":
+			text.PrintfLine("354 end data with <CR><LF>.<CR><LF>")
+			data, err := io.ReadAll(text.DotReader())
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.data = string(data)
+			s.mu.Unlock()
+			text.PrintfLine("250 2.0.0 queued")
+		case "QUIT":
+			text.PrintfLine("221 2.0.0 bye")
+			return
+		default:
+			text.PrintfLine("502 5.5.2 command not recognised")
+		}
+	}
+}
+
+func TestEmailNotifierDeliversOverSTARTTLSWithAuth(t *testing.T) {
+	sink, roots := newSMTPSink(t, "alerts", "s3cret")
+
+	n := NewEmailNotifier(EmailConfig{
+		Host:         "127.0.0.1",
+		Port:         sink.port(),
+		Username:     "alerts",
+		Password:     "s3cret",
+		From:         "incidents@example.com",
+		To:           []string{"oncall@example.com"},
+		StartTLS:     true,
+		DashboardURL: "http://dashboard.local",
+	})
+	n.TLSConfig = &tls.Config{RootCAs: roots, ServerName: "127.0.0.1"}
+
+	result, err := n.Send(context.Background(), Notification{
+		EventType:   model.EventTypeIncidentCreated,
+		Incident:    testIncident(),
+		Destination: "sre@example.com, lead@example.com",
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if result.StatusCode != 250 {
+		t.Errorf("StatusCode = %d, want 250", result.StatusCode)
+	}
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if !sink.tlsUsed || !sink.authedTLS {
+		t.Errorf("STARTTLS used = %v, authenticated over TLS = %v; want both", sink.tlsUsed, sink.authedTLS)
+	}
+	if sink.from != "incidents@example.com" {
+		t.Errorf("MAIL FROM = %q", sink.from)
+	}
+	if got := strings.Join(sink.rcpts, ","); got != "sre@example.com,lead@example.com" {
+		t.Errorf("RCPT TO = %q, want the job's recipients", got)
+	}
+
+	msg, err := mail.ReadMessage(bufio.NewReader(strings.NewReader(sink.data)))
+	if err != nil {
+		t.Fatalf("parsing message: %v", err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if want := "New incident: [HIGH] API gateway timing out (payments)"; subject != want {
+		t.Errorf("Subject = %q, want %q", subject, want)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q, want multipart/alternative", msg.Header.Get("Content-Type"))
+	}
+
+	parts := map[string]string{}
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextRawPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("reading MIME part: %v", err)
+		}
+		if enc := part.Header.Get("Content-Transfer-Encoding"); enc != "quoted-printable" {
+			t.Errorf("Content-Transfer-Encoding = %q, want quoted-printable", enc)
+		}
+		body, err := io.ReadAll(quotedprintable.NewReader(part))
+		if err != nil {
+			t.Fatalf("decoding MIME part: %v", err)
+		}
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[contentType] = string(body)
+	}
+
+	for _, contentType := range []string{"text/plain", "text/html"} {
+		body, ok := parts[contentType]
+		if !ok {
+			t.Errorf("message has no %s part", contentType)
+			continue
+		}
+		for _, want := range []string{
+			"API gateway timing out",
+			"high",
+			"payments",
+			"p99 latency above 5s on /checkout",
+			"http://dashboard.local/incidents/9f1c2d3e-4b5a-4c6d-8e7f-0a1b2c3d4e5f",
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("%s part does not contain %q:\n%s", contentType, want, body)
+			}
+		}
+	}
+}
+
+func TestEmailNotifierReportsRejectedAuth(t *testing.T) {
+	sink, roots := newSMTPSink(t, "alerts", "s3cret")
+
+	n := NewEmailNotifier(EmailConfig{
+		Host:     "127.0.0.1",
+		Port:     sink.port(),
+		Username: "alerts",
+		Password: "wrong",
+		From:     "incidents@example.com",
+		To:       []string{"oncall@example.com"},
+		StartTLS: true,
+	})
+	n.TLSConfig = &tls.Config{RootCAs: roots, ServerName: "127.0.0.1"}
+
+	result, err := n.Send(context.Background(), Notification{EventType: model.EventTypeIncidentCreated, Incident: testIncident()})
+	if err == nil {
+		t.Fatal("Send() succeeded with the wrong password")
+	}
+	if result.StatusCode != 535 {
+		t.Errorf("StatusCode = %d, want the server's 535", result.StatusCode)
+	}
+	if !errors.Is(err, ErrPermanent) {
+		t.Errorf("Send() error = %v, want ErrPermanent", err)
+	}
+}
+
+func TestEmailNotifierClassifiesRejectedRecipients(t *testing.T) {
+	tests := []struct {
+		reply     string
+		code      int
+		permanent bool
+	}{
+		{"550 5.1.1 mailbox unknown", 550, true},
+		{"553 5.1.3 bad recipient address syntax", 553, true},
+		{"450 4.2.1 mailbox busy, try again later", 450, false},
+		{"421 4.7.0 too many connections", 421, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.reply, func(t *testing.T) {
+			sink, _ := newSMTPSink(t, "", "")
+			sink.rcptReply = tt.reply
+
+			n := NewEmailNotifier(EmailConfig{
+				Host: "127.0.0.1",
+				Port: sink.port(),
+				From: "incidents@example.com",
+				To:   []string{"oncall@example.com"},
+			})
+			result, err := n.Send(context.Background(), Notification{EventType: model.EventTypeIncidentCreated, Incident: testIncident()})
+			if err == nil {
+				t.Fatal("Send() succeeded, want an error")
+			}
+			if result.StatusCode != tt.code {
+				t.Errorf("StatusCode = %d, want %d", result.StatusCode, tt.code)
+			}
+			if got := errors.Is(err, ErrPermanent); got != tt.permanent {
+				t.Errorf("permanent = %v, want %v (err: %v)", got, tt.permanent, err)
+			}
+		})
+	}
+}
+
+func TestEmailNotifierMisconfigurationIsPermanent(t *testing.T) {
+	tests := map[string]EmailConfig{
+		"no recipients": {Host: "127.0.0.1", Port: 25, From: "incidents@example.com"},
+		"no host":       {Port: 25, From: "incidents@example.com", To: []string{"oncall@example.com"}},
+	}
+	for name, cfg := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewEmailNotifier(cfg).Send(context.Background(), Notification{EventType: model.EventTypeIncidentCreated, Incident: testIncident()})
+			if !errors.Is(err, ErrPermanent) {
+				t.Errorf("Send() error = %v, want ErrPermanent", err)
+			}
+		})
+	}
+}
+
+func TestEmailNotifierRequiresSTARTTLSWhenConfigured(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+
+	// a server that never offers STARTTLS
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		text := textproto.NewConn(conn)
+		text.PrintfLine("220 plain ESMTP")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+			if strings.HasPrefix(strings.ToUpper(line), "EHLO") {
+				text.PrintfLine("250 plain")
+				continue
+			}
+			text.PrintfLine("221 bye")
+			return
+		}
+	}()
+
+	n := NewEmailNotifier(EmailConfig{
+		Host:     "127.0.0.1",
+		Port:     listener.Addr().(*net.TCPAddr).Port,
+		From:     "incidents@example.com",
+		To:       []string{"oncall@example.com"},
+		StartTLS: true,
+	})
+
+	_, err = n.Send(context.Background(), Notification{EventType: model.EventTypeIncidentCreated, Incident: testIncident()})
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Errorf("Send() error = %v, want a missing STARTTLS error", err)
+	}
+}
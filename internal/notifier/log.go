@@ -0,0 +1,37 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+)
+
+// ChannelLog writes notifications to the worker log. It is the default channel
+// for local development, where no real provider is configured.
+const ChannelLog = "log"
+
+type LogNotifier struct {
+	Logger zerolog.Logger
+}
+
+func NewLogNotifier(logger zerolog.Logger) *LogNotifier {
+	return &LogNotifier{Logger: logger}
+}
+
+func (n *LogNotifier) Channel() string {
+	return ChannelLog
+}
+
+func (n *LogNotifier) Send(ctx context.Context, notification Notification) (Result, error) {
+	n.Logger.Info().
+		Str("job_id", notification.JobID).
+		Str("event_type", notification.EventType).
+		Str("incident_id", notification.Incident.ID).
+		Str("title", notification.Incident.Title).
+		Str("severity", notification.Incident.Severity).
+		Str("team", notification.Incident.Team).
+		Str("status", notification.Incident.Status).
+		Strs("changes", changeLines(notification.Changes)).
+		Msg(eventLabel(notification.EventType))
+	return Result{}, nil
+}
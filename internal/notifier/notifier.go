@@ -0,0 +1,121 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+)
+
+// ErrPermanent marks delivery failures that no retry can fix; the worker
+// dead-letters such jobs straight away.
+var ErrPermanent = errors.New("permanent delivery failure")
+
+// ErrUnknownChannel is returned when a job names a channel with no registered notifier.
+var ErrUnknownChannel = fmt.Errorf("%w: no notifier registered for channel", ErrPermanent)
+
+// Notification is everything a notifier needs to deliver one message.
+type Notification struct {
+	JobID       string
+	EventType   string
+	Incident    *model.Incident
+	Changes     []model.FieldChange // fields changed by an update; empty for other events
+	Destination string              // channel specific target, e.g. a webhook URL; empty means the notifier's default
+	CreatedAt   time.Time           // when the event was enqueued
+}
+
+// Result describes the provider's answer to a delivery.
+type Result struct {
+	StatusCode int // provider response code, 0 when the channel has none
+}
+
+// Notifier delivers incident notifications over one channel.
+type Notifier interface {
+	Channel() string
+	Send(ctx context.Context, n Notification) (Result, error)
+}
+
+// Registry resolves the notifier for a job's channel.
+type Registry struct {
+	notifiers map[string]Notifier
+}
+
+func NewRegistry(notifiers ...Notifier) *Registry {
+	r := &Registry{notifiers: make(map[string]Notifier)}
+	for _, n := range notifiers {
+		r.Register(n)
+	}
+	return r
+}
+
+// Register adds n, replacing any notifier previously registered for its channel
+func (r *Registry) Register(n Notifier) {
+	r.notifiers[n.Channel()] = n
+}
+
+func (r *Registry) Get(channel string) (Notifier, error) {
+	n, ok := r.notifiers[channel]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownChannel, channel)
+	}
+	return n, nil
+}
+
+// summary is the one-line description used for subjects and fallback texts
+func summary(incident *model.Incident) string {
+	return fmt.Sprintf("[%s] %s (%s)", strings.ToUpper(incident.Severity), incident.Title, incident.Team)
+}
+
+// headline announces the event, e.g. "Incident resolved: [HIGH] API down (payments)"
+func headline(n Notification) string {
+	return eventLabel(n.EventType) + ": " + summary(n.Incident)
+}
+
+func eventLabel(eventType string) string {
+	switch eventType {
+	case model.EventTypeIncidentCreated:
+		return "New incident"
+	case model.EventTypeIncidentUpdated:
+		return "Incident updated"
+	case model.EventTypeIncidentResolved:
+		return "Incident resolved"
+	case model.EventTypeIncidentReopened:
+		return "Incident reopened"
+	case model.EventTypeIncidentEscalated:
+		return "Incident escalated"
+	case model.EventTypeIncidentAssigned:
+		return "Incident assigned"
+	case model.EventTypeIncidentDeleted:
+		return "Incident deleted"
+	default:
+		return "Incident"
+	}
+}
+
+// changeLines describes each changed field on its own line. Descriptions are
+// only flagged, since the new text is shown with the incident anyway.
+func changeLines(changes []model.FieldChange) []string {
+	lines := make([]string, 0, len(changes))
+	for _, c := range changes {
+		if c.Field == "description" {
+			lines = append(lines, "Description updated")
+			continue
+		}
+		if c.From == "" {
+			lines = append(lines, fmt.Sprintf("%s: %s", capitalize(c.Field), c.To))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s → %s", capitalize(c.Field), c.From, c.To))
+	}
+	return lines
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,225 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+)
+
+const ChannelPagerDuty = "pagerduty"
+
+// DefaultPagerDutyEventsURL is the public Events API v2 endpoint.
+const DefaultPagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDuty Events API v2 event actions.
+const (
+	pagerDutyTrigger     = "trigger"
+	pagerDutyAcknowledge = "acknowledge"
+	pagerDutyResolve     = "resolve"
+)
+
+// IncidentStore reads an incident as it is at delivery time.
+type IncidentStore interface {
+	GetIncidentByID(ctx context.Context, id string) (*model.Incident, error)
+}
+
+// PagerDutyNotifier mirrors the incident lifecycle onto a PagerDuty Events API
+// v2 compatible endpoint. The incident ID is the dedup key, so every event for
+// an incident lands on the same alert. The job destination is the routing key.
+type PagerDutyNotifier struct {
+	Incidents    IncidentStore
+	EventsURL    string
+	RoutingKey   string // used when a job has no destination of its own
+	DashboardURL string
+	Client       *http.Client
+}
+
+func NewPagerDutyNotifier(incidents IncidentStore, eventsURL string, routingKey string, dashboardURL string) *PagerDutyNotifier {
+	if eventsURL == "" {
+		eventsURL = DefaultPagerDutyEventsURL
+	}
+	return &PagerDutyNotifier{
+		Incidents:    incidents,
+		EventsURL:    eventsURL,
+		RoutingKey:   routingKey,
+		DashboardURL: dashboardURL,
+		Client:       &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (n *PagerDutyNotifier) Channel() string {
+	return ChannelPagerDuty
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"` // only sent with trigger
+	Links       []pagerDutyLink   `json:"links,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string         `json:"summary"`
+	Source        string         `json:"source"`
+	Severity      string         `json:"severity"`
+	Group         string         `json:"group,omitempty"`
+	CustomDetails map[string]any `json:"custom_details,omitempty"`
+}
+
+type pagerDutyLink struct {
+	Href string `json:"href"`
+	Text string `json:"text"`
+}
+
+type pagerDutyResponse struct {
+	Status   string   `json:"status"`
+	Message  string   `json:"message"`
+	DedupKey string   `json:"dedup_key"`
+	Errors   []string `json:"errors"`
+}
+
+func (n *PagerDutyNotifier) Send(ctx context.Context, notification Notification) (Result, error) {
+	routingKey := notification.Destination
+	if routingKey == "" {
+		routingKey = n.RoutingKey
+	}
+	if routingKey == "" {
+		return Result{}, fmt.Errorf("%w: pagerduty: no routing key configured", ErrPermanent)
+	}
+
+	notification, err := n.current(ctx, notification)
+	if err != nil {
+		return Result{}, err
+	}
+
+	body, err := json.Marshal(n.buildEvent(notification, routingKey))
+	if err != nil {
+		return Result{}, fmt.Errorf("pagerduty: failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.EventsURL, bytes.NewReader(body))
+	if err != nil {
+		return Result{}, fmt.Errorf("pagerduty: failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("pagerduty: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	result := Result{StatusCode: resp.StatusCode}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return result, nil
+	}
+
+	var parsed pagerDutyResponse
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	_ = json.Unmarshal(raw, &parsed)
+	reason := strings.Join(append([]string{parsed.Message}, parsed.Errors...), "; ")
+
+	// 400 means the event itself is invalid; sending it again cannot succeed
+	if resp.StatusCode == http.StatusBadRequest {
+		return result, fmt.Errorf("%w: pagerduty: event rejected: %s", ErrPermanent, reason)
+	}
+	return result, fmt.Errorf("pagerduty: endpoint returned %d: %s", resp.StatusCode, reason)
+}
+
+// current replaces the job's incident snapshot with the incident as it is now.
+// Jobs retry independently, so a trigger that backed off can be delivered after
+// a later resolve; acting on the current status keeps it from reopening the
+// alert. An incident that no longer exists is resolved.
+func (n *PagerDutyNotifier) current(ctx context.Context, notification Notification) (Notification, error) {
+	if notification.EventType == model.EventTypeIncidentDeleted {
+		return notification, nil
+	}
+
+	incident, err := n.Incidents.GetIncidentByID(ctx, notification.Incident.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		notification.EventType = model.EventTypeIncidentDeleted
+		return notification, nil
+	}
+	if err != nil {
+		return notification, fmt.Errorf("pagerduty: failed to read incident %s: %w", notification.Incident.ID, err)
+	}
+	notification.Incident = incident
+	return notification, nil
+}
+
+func (n *PagerDutyNotifier) buildEvent(notification Notification, routingKey string) pagerDutyEvent {
+	incident := notification.Incident
+
+	event := pagerDutyEvent{
+		RoutingKey:  routingKey,
+		EventAction: pagerDutyAction(notification),
+		DedupKey:    incident.ID,
+	}
+	if event.EventAction != pagerDutyTrigger {
+		return event
+	}
+
+	event.Payload = &pagerDutyPayload{
+		Summary:  summary(incident),
+		Source:   "incident-dashboard",
+		Severity: pagerDutySeverity(incident.Severity),
+		Group:    incident.Team,
+		CustomDetails: map[string]any{
+			"incident_id": incident.ID,
+			"description": incident.Description,
+			"status":      incident.Status,
+		},
+	}
+	if link := incidentURL(n.DashboardURL, incident.ID); link != "" {
+		event.Links = []pagerDutyLink{{Href: link, Text: "View incident"}}
+	}
+	return event
+}
+
+// pagerDutyAction maps the incident lifecycle onto PagerDuty's alert lifecycle
+func pagerDutyAction(notification Notification) string {
+	switch notification.EventType {
+	case model.EventTypeIncidentDeleted:
+		return pagerDutyResolve
+	case model.EventTypeIncidentAssigned:
+		// an assignment pages the assignee on their own service, even when the
+		// team's alert has already been acknowledged
+		if notification.Incident.Status != model.StatusResolved && notification.Incident.Status != model.StatusClosed {
+			return pagerDutyTrigger
+		}
+	}
+	switch notification.Incident.Status {
+	case model.StatusAcknowledged, model.StatusInvestigating, model.StatusMitigated:
+		return pagerDutyAcknowledge
+	case model.StatusResolved, model.StatusClosed:
+		return pagerDutyResolve
+	default:
+		return pagerDutyTrigger
+	}
+}
+
+// pagerDutySeverity maps our free-text severities onto the four PagerDuty accepts
+func pagerDutySeverity(severity string) string {
+	switch strings.ToLower(severity) {
+	case "critical", "sev1", "p1":
+		return "critical"
+	case "high", "sev2", "p2":
+		return "error"
+	case "medium", "sev3", "p3", "warning":
+		return "warning"
+	case "low", "sev4", "p4", "info":
+		return "info"
+	default:
+		return "error"
+	}
+}
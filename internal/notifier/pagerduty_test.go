@@ -0,0 +1,235 @@
+package notifier
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+)
+
+// fakeIncidentStore serves incidents from memory; a missing ID is sql.ErrNoRows
+type fakeIncidentStore struct {
+	incidents map[string]*model.Incident
+	err       error
+}
+
+func (s *fakeIncidentStore) GetIncidentByID(ctx context.Context, id string) (*model.Incident, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	incident, ok := s.incidents[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	copied := *incident
+	return &copied, nil
+}
+
+// eventsAPI is a stand-in for the Events API v2 enqueue endpoint
+type eventsAPI struct {
+	*httptest.Server
+	mu     sync.Mutex
+	events []pagerDutyEvent
+	status int
+}
+
+func newEventsAPI(t *testing.T) *eventsAPI {
+	t.Helper()
+	api := &eventsAPI{status: http.StatusAccepted}
+	api.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event pagerDutyEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("decoding event: %v", err)
+		}
+		api.mu.Lock()
+		api.events = append(api.events, event)
+		status := api.status
+		api.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if status == http.StatusBadRequest {
+			json.NewEncoder(w).Encode(pagerDutyResponse{Status: "invalid event", Message: "Event object is invalid", Errors: []string{"'routing_key' is invalid"}})
+			return
+		}
+		json.NewEncoder(w).Encode(pagerDutyResponse{Status: "success", Message: "Event processed", DedupKey: event.DedupKey})
+	}))
+	t.Cleanup(api.Close)
+	return api
+}
+
+func (api *eventsAPI) sent() []pagerDutyEvent {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return append([]pagerDutyEvent(nil), api.events...)
+}
+
+func TestPagerDutyNotifierTriggersOpenIncident(t *testing.T) {
+	api := newEventsAPI(t)
+	incident := testIncident()
+	store := &fakeIncidentStore{incidents: map[string]*model.Incident{incident.ID: incident}}
+
+	n := NewPagerDutyNotifier(store, api.URL, "default-key", "http://dashboard.local")
+	result, err := n.Send(context.Background(), Notification{EventType: model.EventTypeIncidentCreated, Incident: incident})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if result.StatusCode != http.StatusAccepted {
+		t.Errorf("StatusCode = %d, want 202", result.StatusCode)
+	}
+
+	events := api.sent()
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	event := events[0]
+	if event.EventAction != pagerDutyTrigger || event.DedupKey != incident.ID || event.RoutingKey != "default-key" {
+		t.Errorf("event = %+v, want a trigger keyed by the incident ID with the default routing key", event)
+	}
+	if event.Payload == nil || event.Payload.Severity != "error" || event.Payload.Group != "payments" ||
+		event.Payload.Summary != "[HIGH] API gateway timing out (payments)" {
+		t.Errorf("payload = %+v", event.Payload)
+	}
+	if len(event.Links) != 1 || event.Links[0].Href != "http://dashboard.local/incidents/"+incident.ID {
+		t.Errorf("links = %+v", event.Links)
+	}
+}
+
+func TestPagerDutyNotifierFollowsCurrentStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		eventType string
+		current   string // incident status at delivery; "" when it was deleted
+		want      string
+	}{
+		{"created, still open", model.EventTypeIncidentCreated, model.StatusOpen, pagerDutyTrigger},
+		{"created, since acknowledged", model.EventTypeIncidentCreated, model.StatusAcknowledged, pagerDutyAcknowledge},
+		{"created, since resolved", model.EventTypeIncidentCreated, model.StatusResolved, pagerDutyResolve},
+		{"created, since deleted", model.EventTypeIncidentCreated, "", pagerDutyResolve},
+		{"updated, since reopened", model.EventTypeIncidentUpdated, model.StatusOpen, pagerDutyTrigger},
+		{"assigned while investigating", model.EventTypeIncidentAssigned, model.StatusInvestigating, pagerDutyTrigger},
+		{"assigned, since closed", model.EventTypeIncidentAssigned, model.StatusClosed, pagerDutyResolve},
+		{"deleted", model.EventTypeIncidentDeleted, "", pagerDutyResolve},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newEventsAPI(t)
+
+			// the job was queued while the incident was open
+			snapshot := testIncident()
+			store := &fakeIncidentStore{incidents: map[string]*model.Incident{}}
+			if tt.current != "" {
+				current := testIncident()
+				current.Status = tt.current
+				store.incidents[current.ID] = current
+			}
+
+			n := NewPagerDutyNotifier(store, api.URL, "key", "")
+			if _, err := n.Send(context.Background(), Notification{EventType: tt.eventType, Incident: snapshot}); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+
+			events := api.sent()
+			if len(events) != 1 || events[0].EventAction != tt.want {
+				t.Fatalf("sent %+v, want one %s", events, tt.want)
+			}
+			if (events[0].Payload != nil) != (tt.want == pagerDutyTrigger) {
+				t.Errorf("payload = %+v; only triggers carry one", events[0].Payload)
+			}
+		})
+	}
+}
+
+func TestPagerDutyNotifierStaleTriggerDoesNotReopen(t *testing.T) {
+	api := newEventsAPI(t)
+	incident := testIncident()
+	store := &fakeIncidentStore{incidents: map[string]*model.Incident{incident.ID: incident}}
+	n := NewPagerDutyNotifier(store, api.URL, "key", "")
+
+	created := Notification{EventType: model.EventTypeIncidentCreated, Incident: testIncident()}
+
+	// the trigger fails and backs off while the incident is resolved
+	api.status = http.StatusServiceUnavailable
+	if _, err := n.Send(context.Background(), created); err == nil || errors.Is(err, ErrPermanent) {
+		t.Fatalf("Send() error = %v, want a retryable error", err)
+	}
+	api.status = http.StatusAccepted
+
+	resolved := testIncident()
+	resolved.Status = model.StatusResolved
+	store.incidents[resolved.ID] = resolved
+	if _, err := n.Send(context.Background(), Notification{EventType: model.EventTypeIncidentResolved, Incident: resolved}); err != nil {
+		t.Fatalf("resolve Send() error = %v", err)
+	}
+
+	// the retried trigger arrives last
+	if _, err := n.Send(context.Background(), created); err != nil {
+		t.Fatalf("retried Send() error = %v", err)
+	}
+
+	events := api.sent()
+	if last := events[len(events)-1]; last.EventAction != pagerDutyResolve {
+		t.Errorf("last event = %s, want the alert to stay resolved", last.EventAction)
+	}
+}
+
+func TestPagerDutyNotifierClassifiesFailures(t *testing.T) {
+	incident := testIncident()
+	store := &fakeIncidentStore{incidents: map[string]*model.Incident{incident.ID: incident}}
+
+	tests := []struct {
+		status    int
+		permanent bool
+	}{
+		{http.StatusBadRequest, true},
+		{http.StatusTooManyRequests, false},
+		{http.StatusInternalServerError, false},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			api := newEventsAPI(t)
+			api.status = tt.status
+
+			n := NewPagerDutyNotifier(store, api.URL, "key", "")
+			result, err := n.Send(context.Background(), Notification{EventType: model.EventTypeIncidentCreated, Incident: incident})
+			if err == nil {
+				t.Fatal("Send() succeeded, want an error")
+			}
+			if result.StatusCode != tt.status {
+				t.Errorf("StatusCode = %d, want %d", result.StatusCode, tt.status)
+			}
+			if got := errors.Is(err, ErrPermanent); got != tt.permanent {
+				t.Errorf("permanent = %v, want %v (err: %v)", got, tt.permanent, err)
+			}
+		})
+	}
+}
+
+func TestPagerDutyNotifierRetriesWhenIncidentUnreadable(t *testing.T) {
+	api := newEventsAPI(t)
+	store := &fakeIncidentStore{err: errors.New("connection refused")}
+
+	n := NewPagerDutyNotifier(store, api.URL, "key", "")
+	_, err := n.Send(context.Background(), Notification{EventType: model.EventTypeIncidentCreated, Incident: testIncident()})
+	if err == nil || errors.Is(err, ErrPermanent) {
+		t.Errorf("Send() error = %v, want a retryable error", err)
+	}
+	if events := api.sent(); len(events) != 0 {
+		t.Errorf("sent %+v without knowing the incident's status", events)
+	}
+}
+
+func TestPagerDutyNotifierWithoutRoutingKeyIsPermanent(t *testing.T) {
+	n := NewPagerDutyNotifier(&fakeIncidentStore{}, "http://127.0.0.1:1", "", "")
+	_, err := n.Send(context.Background(), Notification{EventType: model.EventTypeIncidentCreated, Incident: testIncident()})
+	if !errors.Is(err, ErrPermanent) {
+		t.Errorf("Send() error = %v, want ErrPermanent", err)
+	}
+}
@@ -0,0 +1,190 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const ChannelSlack = "slack"
+
+// Block Kit rejects a message whose header or section text is longer than this
+const (
+	slackHeaderLimit  = 150
+	slackSectionLimit = 3000
+)
+
+// slackEscaper escapes the characters Slack reads as markup in mrkdwn text
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// SlackNotifier posts Block Kit messages to Slack incoming webhooks.
+type SlackNotifier struct {
+	WebhookURL   string // used when a job has no destination of its own
+	DashboardURL string // base URL used to link back to the incident
+	Client       *http.Client
+}
+
+func NewSlackNotifier(webhookURL string, dashboardURL string) *SlackNotifier {
+	return &SlackNotifier{
+		WebhookURL:   webhookURL,
+		DashboardURL: dashboardURL,
+		Client:       &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (n *SlackNotifier) Channel() string {
+	return ChannelSlack
+}
+
+func (n *SlackNotifier) Send(ctx context.Context, notification Notification) (Result, error) {
+	url := notification.Destination
+	if url == "" {
+		url = n.WebhookURL
+	}
+	if url == "" {
+		return Result{}, fmt.Errorf("%w: slack: no webhook URL configured", ErrPermanent)
+	}
+
+	body, err := json.Marshal(n.buildMessage(notification))
+	if err != nil {
+		return Result{}, fmt.Errorf("slack: failed to marshal message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return Result{}, fmt.Errorf("slack: failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("slack: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	result := Result{StatusCode: resp.StatusCode}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// Slack explains rejections in a short plain-text body, e.g. "invalid_payload"
+		reason, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		// a bad payload or a revoked or archived webhook stays rejected;
+		// timeouts, rate limits and server errors are worth retrying
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+			resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+			return result, fmt.Errorf("%w: slack: webhook rejected the message with %d: %s", ErrPermanent, resp.StatusCode, strings.TrimSpace(string(reason)))
+		}
+		return result, fmt.Errorf("slack: webhook returned %d: %s", resp.StatusCode, strings.TrimSpace(string(reason)))
+	}
+	return result, nil
+}
+
+type slackMessage struct {
+	Text   string       `json:"text"` // fallback for notifications and clients without Block Kit
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type     string         `json:"type"`
+	Text     *slackText     `json:"text,omitempty"`
+	Fields   []slackText    `json:"fields,omitempty"`
+	Elements []slackElement `json:"elements,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackElement struct {
+	Type string     `json:"type"`
+	Text *slackText `json:"text,omitempty"`
+	URL  string     `json:"url,omitempty"`
+}
+
+func (n *SlackNotifier) buildMessage(notification Notification) slackMessage {
+	incident := notification.Incident
+
+	blocks := []slackBlock{
+		{
+			Type: "header",
+			Text: &slackText{Type: "plain_text", Text: truncateRunes(eventLabel(notification.EventType)+": "+incident.Title, slackHeaderLimit)},
+		},
+		{
+			Type: "section",
+			Fields: []slackText{
+				{Type: "mrkdwn", Text: "*Severity:*\n" + slackMrkdwn(incident.Severity, slackSectionLimit)},
+				{Type: "mrkdwn", Text: "*Team:*\n" + slackMrkdwn(incident.Team, slackSectionLimit)},
+				{Type: "mrkdwn", Text: "*Status:*\n" + slackMrkdwn(incident.Status, slackSectionLimit)},
+			},
+		},
+	}
+
+	if lines := changeLines(notification.Changes); len(lines) > 0 {
+		const label = "*Changes:*\n"
+		blocks = append(blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: label + slackMrkdwn("• "+strings.Join(lines, "\n• "), slackSectionLimit-len(label))},
+		})
+	}
+
+	if incident.Description != "" {
+		blocks = append(blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: slackMrkdwn(incident.Description, slackSectionLimit)},
+		})
+	}
+
+	if link := incidentURL(n.DashboardURL, incident.ID); link != "" {
+		blocks = append(blocks, slackBlock{
+			Type: "actions",
+			Elements: []slackElement{{
+				Type: "button",
+				Text: &slackText{Type: "plain_text", Text: "View incident"},
+				URL:  link,
+			}},
+		})
+	}
+
+	return slackMessage{
+		Text:   slackEscaper.Replace(headline(notification)),
+		Blocks: blocks,
+	}
+}
+
+// slackMrkdwn escapes user-supplied text for a mrkdwn field and cuts it to
+// limit runes, never in the middle of an escape
+func slackMrkdwn(text string, limit int) string {
+	escaped := slackEscaper.Replace(text)
+	if utf8.RuneCountInString(escaped) <= limit {
+		return escaped
+	}
+	cut := truncateRunes(escaped, limit)
+	body := strings.TrimSuffix(cut, "…")
+	if amp := strings.LastIndexByte(body, '&'); amp >= 0 && !strings.Contains(body[amp:], ";") {
+		body = body[:amp]
+	}
+	return body + "…"
+}
+
+// truncateRunes cuts text to at most limit runes, ending with an ellipsis when
+// anything was cut
+func truncateRunes(text string, limit int) string {
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:limit-1]) + "…"
+}
+
+// incidentURL links to an incident on the dashboard, or returns "" when no dashboard is configured
+func incidentURL(dashboardURL string, incidentID string) string {
+	if dashboardURL == "" {
+		return ""
+	}
+	return strings.TrimRight(dashboardURL, "/") + "/incidents/" + incidentID
+}
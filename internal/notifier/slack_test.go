@@ -0,0 +1,222 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+)
+
+func testIncident() *model.Incident {
+	return &model.Incident{
+		ID:          "9f1c2d3e-4b5a-4c6d-8e7f-0a1b2c3d4e5f",
+		Title:       "API gateway timing out",
+		Description: "p99 latency above 5s on /checkout",
+		Status:      model.StatusOpen,
+		Severity:    "high",
+		Team:        "payments",
+	}
+}
+
+func TestSlackNotifierPostsBlockKitMessage(t *testing.T) {
+	var got slackMessage
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		contentType = r.Header.Get("Content-Type")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decoding message: %v", err)
+		}
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	n := NewSlackNotifier("", "http://dashboard.local/")
+	result, err := n.Send(context.Background(), Notification{
+		EventType:   model.EventTypeIncidentUpdated,
+		Incident:    testIncident(),
+		Changes:     []model.FieldChange{{Field: "severity", From: "medium", To: "high"}},
+		Destination: server.URL,
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if result.StatusCode != http.StatusOK {
+		t.Errorf("StatusCode = %d, want 200", result.StatusCode)
+	}
+	if contentType != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", contentType)
+	}
+
+	if want := "Incident updated: [HIGH] API gateway timing out (payments)"; got.Text != want {
+		t.Errorf("fallback text = %q, want %q", got.Text, want)
+	}
+	if len(got.Blocks) != 5 {
+		t.Fatalf("got %d blocks, want header, fields, changes, description and actions: %+v", len(got.Blocks), got.Blocks)
+	}
+
+	header := got.Blocks[0]
+	if header.Type != "header" || header.Text == nil || header.Text.Type != "plain_text" ||
+		header.Text.Text != "Incident updated: API gateway timing out" {
+		t.Errorf("header block = %+v", header)
+	}
+
+	fields := got.Blocks[1]
+	wantFields := []string{"*Severity:*\nhigh", "*Team:*\npayments", "*Status:*\nopen"}
+	if fields.Type != "section" || len(fields.Fields) != len(wantFields) {
+		t.Fatalf("fields block = %+v", fields)
+	}
+	for i, want := range wantFields {
+		if fields.Fields[i].Type != "mrkdwn" || fields.Fields[i].Text != want {
+			t.Errorf("field %d = %+v, want mrkdwn %q", i, fields.Fields[i], want)
+		}
+	}
+
+	if changes := got.Blocks[2]; changes.Text == nil || changes.Text.Text != "*Changes:*\n• Severity: medium → high" {
+		t.Errorf("changes block = %+v", changes)
+	}
+	if description := got.Blocks[3]; description.Text == nil || description.Text.Text != "p99 latency above 5s on /checkout" {
+		t.Errorf("description block = %+v", description)
+	}
+
+	actions := got.Blocks[4]
+	if actions.Type != "actions" || len(actions.Elements) != 1 {
+		t.Fatalf("actions block = %+v", actions)
+	}
+	if button := actions.Elements[0]; button.Type != "button" ||
+		button.URL != "http://dashboard.local/incidents/9f1c2d3e-4b5a-4c6d-8e7f-0a1b2c3d4e5f" {
+		t.Errorf("button = %+v", button)
+	}
+}
+
+func TestSlackNotifierFallsBackToDefaultWebhook(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	n := NewSlackNotifier(server.URL, "")
+	if _, err := n.Send(context.Background(), Notification{EventType: model.EventTypeIncidentCreated, Incident: testIncident()}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if !called {
+		t.Error("default webhook was not called")
+	}
+}
+
+func TestSlackNotifierClassifiesFailures(t *testing.T) {
+	tests := []struct {
+		status    int
+		permanent bool
+	}{
+		{http.StatusBadRequest, true},
+		{http.StatusForbidden, true},
+		{http.StatusNotFound, true},
+		{http.StatusGone, true},
+		{http.StatusRequestTimeout, false},
+		{http.StatusTooManyRequests, false},
+		{http.StatusInternalServerError, false},
+		{http.StatusServiceUnavailable, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				io.WriteString(w, "invalid_payload")
+			}))
+			defer server.Close()
+
+			n := NewSlackNotifier(server.URL, "")
+			result, err := n.Send(context.Background(), Notification{EventType: model.EventTypeIncidentCreated, Incident: testIncident()})
+			if err == nil {
+				t.Fatal("Send() succeeded, want an error")
+			}
+			if result.StatusCode != tt.status {
+				t.Errorf("StatusCode = %d, want %d", result.StatusCode, tt.status)
+			}
+			if got := errors.Is(err, ErrPermanent); got != tt.permanent {
+				t.Errorf("permanent = %v, want %v (err: %v)", got, tt.permanent, err)
+			}
+			if !strings.Contains(err.Error(), "invalid_payload") {
+				t.Errorf("error %q does not carry Slack's reason", err)
+			}
+		})
+	}
+}
+
+func TestSlackNotifierWithoutWebhookIsPermanent(t *testing.T) {
+	n := NewSlackNotifier("", "")
+	_, err := n.Send(context.Background(), Notification{EventType: model.EventTypeIncidentCreated, Incident: testIncident()})
+	if !errors.Is(err, ErrPermanent) {
+		t.Errorf("Send() error = %v, want ErrPermanent", err)
+	}
+}
+
+func TestSlackNotifierUnreachableIsRetryable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	n := NewSlackNotifier(url, "")
+	_, err := n.Send(context.Background(), Notification{EventType: model.EventTypeIncidentCreated, Incident: testIncident()})
+	if err == nil || errors.Is(err, ErrPermanent) {
+		t.Errorf("Send() error = %v, want a retryable error", err)
+	}
+}
+
+func TestSlackNotifierFitsBlockKitLimits(t *testing.T) {
+	var got slackMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decoding message: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	incident := testIncident()
+	incident.Title = strings.Repeat("Disk full on <db-1> ", 20)
+	// an unmapped integration event's payload, with markup Slack would interpret
+	incident.Description = "<!channel> R&D " + strings.Repeat(`{"host": "db-1", "used": "99%"} `, 200) + "&"
+
+	n := NewSlackNotifier(server.URL, "")
+	if _, err := n.Send(context.Background(), Notification{EventType: model.EventTypeIncidentCreated, Incident: incident}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	header := []rune(got.Blocks[0].Text.Text)
+	if len(header) != slackHeaderLimit || header[len(header)-1] != '…' {
+		t.Errorf("header is %d runes, want %d ending in an ellipsis", len(header), slackHeaderLimit)
+	}
+
+	description := got.Blocks[2].Text.Text
+	if n := utf8.RuneCountInString(description); n > slackSectionLimit {
+		t.Errorf("description is %d runes, want at most %d", n, slackSectionLimit)
+	}
+	if !strings.HasPrefix(description, "&lt;!channel&gt; R&amp;D ") {
+		t.Errorf("description = %.40q..., want <, > and & escaped", description)
+	}
+	if !strings.HasSuffix(description, "…") {
+		t.Errorf("description does not end in an ellipsis: ...%q", description[len(description)-20:])
+	}
+	if strings.Contains(got.Text, "<") {
+		t.Errorf("fallback text %q is not escaped", got.Text)
+	}
+}
+
+func TestSlackMrkdwnDoesNotSplitEscapes(t *testing.T) {
+	got := slackMrkdwn("abc&def", 6)
+	if got != "abc…" {
+		t.Errorf("slackMrkdwn() = %q, want the cut escape dropped", got)
+	}
+}
@@ -0,0 +1,113 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+)
+
+const ChannelWebhook = "webhook"
+
+// Headers set on every webhook delivery.
+const (
+	HeaderWebhookID = "X-Webhook-ID"
+	HeaderEventType = "X-Event-Type"
+	HeaderTimestamp = "X-Timestamp"
+	HeaderSignature = "X-Signature"
+)
+
+// WebhookStore looks subscriptions up at delivery time, so deleted or
+// deactivated subscriptions stop receiving events straight away.
+type WebhookStore interface {
+	GetWebhookByID(ctx context.Context, id string) (*model.WebhookSubscription, error)
+}
+
+// WebhookNotifier delivers signed incident events to webhook subscribers. The
+// job destination is the subscription ID.
+type WebhookNotifier struct {
+	Store  WebhookStore
+	Client *http.Client
+}
+
+func NewWebhookNotifier(store WebhookStore) *WebhookNotifier {
+	return &WebhookNotifier{
+		Store:  store,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (n *WebhookNotifier) Channel() string {
+	return ChannelWebhook
+}
+
+func (n *WebhookNotifier) Send(ctx context.Context, notification Notification) (Result, error) {
+	subscription, err := n.Store.GetWebhookByID(ctx, notification.Destination)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Result{}, fmt.Errorf("%w: webhook subscription %s no longer exists", ErrPermanent, notification.Destination)
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("webhook: failed to load subscription: %w", err)
+	}
+	if !subscription.Active {
+		return Result{}, fmt.Errorf("%w: webhook subscription %s is inactive", ErrPermanent, subscription.ID)
+	}
+
+	body, err := json.Marshal(model.WebhookEvent{
+		ID:        notification.JobID,
+		Type:      notification.EventType,
+		CreatedAt: notification.CreatedAt,
+		Data:      notification.Incident,
+		Changes:   notification.Changes,
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("webhook: failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return Result{}, fmt.Errorf("webhook: failed to build request: %w", err)
+	}
+
+	// the timestamp is signed with the body and refreshed on every attempt, so
+	// receivers can reject captured deliveries that are replayed later
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderWebhookID, notification.JobID)
+	req.Header.Set(HeaderEventType, notification.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, SignWebhook(subscription.Secret, timestamp, body))
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("webhook: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	result := Result{StatusCode: resp.StatusCode}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return result, fmt.Errorf("webhook: endpoint returned %d", resp.StatusCode)
+	}
+	return result, nil
+}
+
+// SignWebhook computes the X-Signature value for a delivery: an HMAC-SHA256 over
+// "<timestamp>.<body>" keyed with the subscription secret. Receivers recompute it
+// and compare in constant time, and reject timestamps outside their tolerance.
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,166 @@
+package notifier
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+)
+
+// fakeWebhookStore serves subscriptions from memory; a missing ID is sql.ErrNoRows
+type fakeWebhookStore map[string]*model.WebhookSubscription
+
+func (s fakeWebhookStore) GetWebhookByID(ctx context.Context, id string) (*model.WebhookSubscription, error) {
+	subscription, ok := s[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return subscription, nil
+}
+
+// verifySignature checks a delivery the way a receiver would
+func verifySignature(secret string, timestamp string, body []byte, signature string) bool {
+	mac := hmac.New(sha256.New, []byte(secret))
+	io.WriteString(mac, timestamp+".")
+	mac.Write(body)
+	return hmac.Equal([]byte(signature), []byte("sha256="+hex.EncodeToString(mac.Sum(nil))))
+}
+
+func TestWebhookNotifierDeliversSignedEvent(t *testing.T) {
+	const secret = "whsec_test"
+	var (
+		headers http.Header
+		body    []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		if !verifySignature(secret, r.Header.Get(HeaderTimestamp), body, r.Header.Get(HeaderSignature)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	store := fakeWebhookStore{"sub-1": {ID: "sub-1", URL: server.URL, Secret: secret, Active: true}}
+	n := NewWebhookNotifier(store)
+
+	queuedAt := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+	before := time.Now().Unix()
+	result, err := n.Send(context.Background(), Notification{
+		JobID:       "job-42",
+		EventType:   model.EventTypeIncidentUpdated,
+		Incident:    testIncident(),
+		Changes:     []model.FieldChange{{Field: "status", From: "open", To: "acknowledged"}},
+		Destination: "sub-1",
+		CreatedAt:   queuedAt,
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v (the receiver could not verify the signature?)", err)
+	}
+	if result.StatusCode != http.StatusNoContent {
+		t.Errorf("StatusCode = %d, want 204", result.StatusCode)
+	}
+
+	if got := headers.Get(HeaderWebhookID); got != "job-42" {
+		t.Errorf("%s = %q, want the job ID", HeaderWebhookID, got)
+	}
+	if got := headers.Get(HeaderEventType); got != model.EventTypeIncidentUpdated {
+		t.Errorf("%s = %q", HeaderEventType, got)
+	}
+	timestamp, err := strconv.ParseInt(headers.Get(HeaderTimestamp), 10, 64)
+	if err != nil || timestamp < before || timestamp > time.Now().Unix() {
+		t.Errorf("%s = %q, want the delivery time in Unix seconds", HeaderTimestamp, headers.Get(HeaderTimestamp))
+	}
+	if got, want := headers.Get(HeaderSignature), SignWebhook(secret, timestamp, body); got != want {
+		t.Errorf("%s = %q, want %q", HeaderSignature, got, want)
+	}
+
+	var event model.WebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		t.Fatalf("decoding event: %v", err)
+	}
+	if event.ID != "job-42" || event.Type != model.EventTypeIncidentUpdated || !event.CreatedAt.Equal(queuedAt) {
+		t.Errorf("event = %+v", event)
+	}
+	if event.Data == nil || event.Data.ID != testIncident().ID || len(event.Changes) != 1 {
+		t.Errorf("event data = %+v, changes = %+v", event.Data, event.Changes)
+	}
+}
+
+func TestWebhookSignatureRejectsTampering(t *testing.T) {
+	body := []byte(`{"id":"job-1"}`)
+	signature := SignWebhook("secret", 1760693400, body)
+
+	if !verifySignature("secret", "1760693400", body, signature) {
+		t.Fatal("signature does not verify")
+	}
+	if verifySignature("secret", "1760693401", body, signature) {
+		t.Error("signature verifies with another timestamp")
+	}
+	if verifySignature("secret", "1760693400", []byte(`{"id":"job-2"}`), signature) {
+		t.Error("signature verifies with another body")
+	}
+	if verifySignature("other", "1760693400", body, signature) {
+		t.Error("signature verifies with another secret")
+	}
+}
+
+func TestWebhookNotifierSkipsGoneSubscriptions(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	store := fakeWebhookStore{"inactive": {ID: "inactive", URL: server.URL, Secret: "s", Active: false}}
+	n := NewWebhookNotifier(store)
+
+	for _, destination := range []string{"inactive", "deleted"} {
+		t.Run(destination, func(t *testing.T) {
+			_, err := n.Send(context.Background(), Notification{
+				EventType:   model.EventTypeIncidentCreated,
+				Incident:    testIncident(),
+				Destination: destination,
+			})
+			if !errors.Is(err, ErrPermanent) {
+				t.Errorf("Send() error = %v, want ErrPermanent", err)
+			}
+		})
+	}
+	if called {
+		t.Error("delivered to a subscription that is gone")
+	}
+}
+
+func TestWebhookNotifierRetriesFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	store := fakeWebhookStore{"sub-1": {ID: "sub-1", URL: server.URL, Secret: "s", Active: true}}
+	result, err := NewWebhookNotifier(store).Send(context.Background(), Notification{
+		EventType:   model.EventTypeIncidentCreated,
+		Incident:    testIncident(),
+		Destination: "sub-1",
+	})
+	if err == nil || errors.Is(err, ErrPermanent) {
+		t.Errorf("Send() error = %v, want a retryable error", err)
+	}
+	if result.StatusCode != http.StatusBadGateway {
+		t.Errorf("StatusCode = %d, want 502", result.StatusCode)
+	}
+}
@@ -0,0 +1,107 @@
+package probe
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+)
+
+// maxBodyBytes caps how much of a response is searched for BodyContains
+const maxBodyBytes = 1 << 20
+
+// Prober runs synthetic checks: an HTTP request whose response is compared
+// with what the check expects, or a TCP connection attempt. The HTTP client
+// and dialer can be replaced, e.g. to trust a test server's certificate.
+type Prober struct {
+	Client *http.Client
+	Dialer *net.Dialer
+}
+
+func NewProber() *Prober {
+	return &Prober{
+		Client: &http.Client{},
+		Dialer: &net.Dialer{},
+	}
+}
+
+// Probe runs the check once, giving up after its timeout. It never fails:
+// anything that goes wrong is reported as an unsuccessful result.
+func (p *Prober) Probe(ctx context.Context, check *model.SyntheticCheck) *model.SyntheticCheckResult {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(check.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	result := &model.SyntheticCheckResult{CheckID: check.ID, CheckedAt: time.Now()}
+
+	var err error
+	switch check.Type {
+	case model.SyntheticCheckHTTP:
+		result.StatusCode, err = p.probeHTTP(ctx, check)
+	case model.SyntheticCheckTCP:
+		err = p.probeTCP(ctx, check)
+	default:
+		err = fmt.Errorf("unknown check type %q", check.Type)
+	}
+
+	result.DurationMS = time.Since(result.CheckedAt).Milliseconds()
+	result.Success = err == nil
+	if err != nil {
+		result.ErrorMessage = err.Error()
+	}
+	return result
+}
+
+// probeHTTP returns the response code, if there was a response, and why the
+// response is not what the check expects
+func (p *Prober) probeHTTP(ctx context.Context, check *model.SyntheticCheck) (*int, error) {
+	req, err := http.NewRequestWithContext(ctx, check.Method, check.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("User-Agent", "go-incident-dashboard-synthetic-check")
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	statusCode := resp.StatusCode
+
+	if check.ExpectedStatus != nil {
+		if statusCode != *check.ExpectedStatus {
+			return &statusCode, fmt.Errorf("expected status %d, got %d", *check.ExpectedStatus, statusCode)
+		}
+	} else if statusCode < 200 || statusCode > 299 {
+		return &statusCode, fmt.Errorf("expected a 2xx status, got %d", statusCode)
+	}
+
+	if check.BodyContains != "" {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+		if err != nil {
+			return &statusCode, fmt.Errorf("failed to read response body: %w", err)
+		}
+		if !bytes.Contains(body, []byte(check.BodyContains)) {
+			return &statusCode, fmt.Errorf("response body does not contain %q", check.BodyContains)
+		}
+	}
+	return &statusCode, nil
+}
+
+func (p *Prober) probeTCP(ctx context.Context, check *model.SyntheticCheck) error {
+	port := 0
+	if check.Port != nil {
+		port = *check.Port
+	}
+
+	conn, err := p.Dialer.DialContext(ctx, "tcp", net.JoinHostPort(check.Host, strconv.Itoa(port)))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
@@ -0,0 +1,179 @@
+package probe
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+)
+
+func httpCheck(url string) *model.SyntheticCheck {
+	return &model.SyntheticCheck{
+		ID:             "check-1",
+		Type:           model.SyntheticCheckHTTP,
+		URL:            url,
+		Method:         http.MethodGet,
+		TimeoutSeconds: 5,
+	}
+}
+
+func tcpCheck(addr net.Addr) *model.SyntheticCheck {
+	host, port, _ := net.SplitHostPort(addr.String())
+	portNumber, _ := strconv.Atoi(port)
+	return &model.SyntheticCheck{
+		ID:             "check-1",
+		Type:           model.SyntheticCheckTCP,
+		Host:           host,
+		Port:           &portNumber,
+		TimeoutSeconds: 5,
+	}
+}
+
+func TestProbeHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			io.WriteString(w, `{"status":"ok"}`)
+		case "/created":
+			w.WriteHeader(http.StatusCreated)
+		case "/broken":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			io.WriteString(w, `{"status":"ok"}`)
+		}
+	}))
+	defer server.Close()
+
+	status := func(code int) *int { return &code }
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus *int
+		bodyContains   string
+		success        bool
+		statusCode     int
+		errContains    string
+	}{
+		{name: "any 2xx", path: "/created", success: true, statusCode: 201},
+		{name: "non-2xx", path: "/broken", success: false, statusCode: 503, errContains: "expected a 2xx status, got 503"},
+		{name: "expected status matches", path: "/broken", expectedStatus: status(503), success: true, statusCode: 503},
+		{name: "expected status differs", path: "/health", expectedStatus: status(204), success: false, statusCode: 200, errContains: "expected status 204, got 200"},
+		{name: "body contains", path: "/health", bodyContains: `"status":"ok"`, success: true, statusCode: 200},
+		{name: "body does not contain", path: "/health", bodyContains: "degraded", success: false, statusCode: 200, errContains: `does not contain "degraded"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := httpCheck(server.URL + tt.path)
+			check.ExpectedStatus = tt.expectedStatus
+			check.BodyContains = tt.bodyContains
+
+			result := NewProber().Probe(context.Background(), check)
+			if result.Success != tt.success {
+				t.Errorf("Success = %v, want %v (error: %q)", result.Success, tt.success, result.ErrorMessage)
+			}
+			if result.StatusCode == nil || *result.StatusCode != tt.statusCode {
+				t.Errorf("StatusCode = %v, want %d", result.StatusCode, tt.statusCode)
+			}
+			if !strings.Contains(result.ErrorMessage, tt.errContains) {
+				t.Errorf("ErrorMessage = %q, want it to contain %q", result.ErrorMessage, tt.errContains)
+			}
+			if result.CheckID != check.ID || result.CheckedAt.IsZero() {
+				t.Errorf("result = %+v, want it stamped with the check and time", result)
+			}
+		})
+	}
+}
+
+func TestProbeHTTPS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "pong")
+	}))
+	defer server.Close()
+
+	check := httpCheck(server.URL)
+	check.BodyContains = "pong"
+
+	// the test server's certificate is not trusted by default
+	if result := NewProber().Probe(context.Background(), check); result.Success {
+		t.Error("Probe() trusted an unknown certificate")
+	}
+
+	prober := NewProber()
+	prober.Client = server.Client()
+	if result := prober.Probe(context.Background(), check); !result.Success {
+		t.Errorf("Probe() failed: %s", result.ErrorMessage)
+	}
+}
+
+func TestProbeHTTPTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	check := httpCheck(server.URL)
+	check.TimeoutSeconds = 1
+
+	result := NewProber().Probe(context.Background(), check)
+	if result.Success {
+		t.Fatal("Probe() succeeded against a server that never answers")
+	}
+	if result.StatusCode != nil {
+		t.Errorf("StatusCode = %d, want none", *result.StatusCode)
+	}
+	if !strings.Contains(result.ErrorMessage, "deadline exceeded") {
+		t.Errorf("ErrorMessage = %q, want a timeout", result.ErrorMessage)
+	}
+	if result.DurationMS < 1000 || result.DurationMS > 3000 {
+		t.Errorf("DurationMS = %d, want about the 1s timeout", result.DurationMS)
+	}
+}
+
+func TestProbeTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	accepted := NewProber().Probe(context.Background(), tcpCheck(listener.Addr()))
+	if !accepted.Success {
+		t.Errorf("Probe() of a listening port failed: %s", accepted.ErrorMessage)
+	}
+
+	// nothing listens on the port once the listener is closed
+	listener.Close()
+	refused := NewProber().Probe(context.Background(), tcpCheck(listener.Addr()))
+	if refused.Success {
+		t.Error("Probe() of a closed port succeeded")
+	}
+	if !strings.Contains(refused.ErrorMessage, "refused") {
+		t.Errorf("ErrorMessage = %q, want connection refused", refused.ErrorMessage)
+	}
+}
+
+func TestProbeUnknownType(t *testing.T) {
+	result := NewProber().Probe(context.Background(), &model.SyntheticCheck{Type: "icmp", TimeoutSeconds: 1})
+	if result.Success || !strings.Contains(result.ErrorMessage, `unknown check type "icmp"`) {
+		t.Errorf("result = %+v, want an unknown type failure", result)
+	}
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+)
+
+type AlertRepository interface {
+	WithTx(tx *sql.Tx) AlertRepository
+	LockFingerprint(ctx context.Context, source string, fingerprint string) error
+	GetExternalAlert(ctx context.Context, source string, fingerprint string) (*model.ExternalAlert, error)
+	CreateExternalAlert(ctx context.Context, alert *model.ExternalAlert) (*model.ExternalAlert, error)
+	UpdateExternalAlert(ctx context.Context, alert *model.ExternalAlert) error
+	CountFiringAlerts(ctx context.Context, incidentID string) (int, error)
+}
+
+type alertRepository struct {
+	DB DBTX
+}
+
+func NewAlertRepository(db *sql.DB) AlertRepository {
+	return &alertRepository{DB: db}
+}
+
+// WithTx returns a copy of the repository whose queries run inside tx
+func (r *alertRepository) WithTx(tx *sql.Tx) AlertRepository {
+	return &alertRepository{DB: tx}
+}
+
+const externalAlertColumns = `id, source, fingerprint, incident_id, status, labels, created_at, updated_at`
+
+func scanExternalAlert(row rowScanner) (*model.ExternalAlert, error) {
+	alert := &model.ExternalAlert{}
+	var labels []byte
+	err := row.Scan(
+		&alert.ID,
+		&alert.Source,
+		&alert.Fingerprint,
+		&alert.IncidentID,
+		&alert.Status,
+		&labels,
+		&alert.CreatedAt,
+		&alert.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(labels, &alert.Labels); err != nil {
+		return nil, fmt.Errorf("failed to decode alert labels: %w", err)
+	}
+	return alert, nil
+}
+
+// LockFingerprint serialises the handling of one alert group until the
+// surrounding transaction ends, so notifications sent twice at once (e.g. by
+// an HA Alertmanager pair) cannot open two incidents. It also covers groups
+// that have no row yet, which FOR UPDATE cannot.
+func (r *alertRepository) LockFingerprint(ctx context.Context, source string, fingerprint string) error {
+	query := `SELECT pg_advisory_xact_lock(hashtextextended($1 || ':' || $2, 0))`
+
+	if _, err := r.DB.ExecContext(ctx, query, source, fingerprint); err != nil {
+		return fmt.Errorf("repository: failed to lock alert %s/%s: %w", source, fingerprint, err)
+	}
+	return nil
+}
+
+func (r *alertRepository) GetExternalAlert(ctx context.Context, source string, fingerprint string) (*model.ExternalAlert, error) {
+	query := `
+		SELECT ` + externalAlertColumns + `
+		FROM external_alerts
+		WHERE source = $1 AND fingerprint = $2`
+
+	alert, err := scanExternalAlert(r.DB.QueryRowContext(ctx, query, source, fingerprint))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("repository: failed to get alert %s/%s: %w", source, fingerprint, err)
+	}
+	return alert, nil
+}
+
+func (r *alertRepository) CreateExternalAlert(ctx context.Context, alert *model.ExternalAlert) (*model.ExternalAlert, error) {
+	labels, err := json.Marshal(alert.Labels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal alert labels: %w", err)
+	}
+
+	query := `
+		INSERT INTO external_alerts (source, fingerprint, incident_id, status, labels)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + externalAlertColumns
+
+	created, err := scanExternalAlert(r.DB.QueryRowContext(ctx, query, alert.Source, alert.Fingerprint, alert.IncidentID, alert.Status, labels))
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to create alert: %w", err)
+	}
+	return created, nil
+}
+
+// UpdateExternalAlert records the latest status and labels of an alert group,
+// and the incident it now points at.
+func (r *alertRepository) UpdateExternalAlert(ctx context.Context, alert *model.ExternalAlert) error {
+	labels, err := json.Marshal(alert.Labels)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert labels: %w", err)
+	}
+
+	query := `
+		UPDATE external_alerts
+		SET incident_id = $2, status = $3, labels = $4, updated_at = NOW()
+		WHERE id = $1`
+
+	res, err := r.DB.ExecContext(ctx, query, alert.ID, alert.IncidentID, alert.Status, labels)
+	if err != nil {
+		return fmt.Errorf("repository: failed to update alert %s: %w", alert.ID, err)
+	}
+	return checkDeleted(res)
+}
+
+// CountFiringAlerts counts the alert groups feeding an incident that are still firing
+func (r *alertRepository) CountFiringAlerts(ctx context.Context, incidentID string) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM external_alerts
+		WHERE incident_id = $1 AND status = 'firing'`
+
+	var count int
+	if err := r.DB.QueryRowContext(ctx, query, incidentID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("repository: failed to count firing alerts for incident %s: %w", incidentID, err)
+	}
+	return count, nil
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+)
+
+type AttemptRepository interface {
+	WithTx(tx *sql.Tx) AttemptRepository
+	CreateAttempt(ctx context.Context, attempt *model.NotificationAttempt) error
+	GetAttemptsByIncidentID(ctx context.Context, incidentID string) ([]*model.NotificationAttempt, error)
+	GetAttemptsByJobID(ctx context.Context, jobID string) ([]*model.NotificationAttempt, error)
+}
+
+type attemptRepository struct {
+	DB DBTX
+}
+
+func NewAttemptRepository(db *sql.DB) AttemptRepository {
+	return &attemptRepository{DB: db}
+}
+
+// WithTx returns a copy of the repository whose queries run inside tx
+func (r *attemptRepository) WithTx(tx *sql.Tx) AttemptRepository {
+	return &attemptRepository{DB: tx}
+}
+
+const attemptColumns = `id, job_id, incident_id, worker_id, channel, attempt, success, started_at, finished_at, duration_ms, error_message, response_code`
+
+func (r *attemptRepository) CreateAttempt(ctx context.Context, attempt *model.NotificationAttempt) error {
+	query := `
+		INSERT INTO notification_attempts
+			(job_id, incident_id, worker_id, channel, attempt, success, started_at, finished_at, duration_ms, error_message, response_code)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id`
+
+	err := r.DB.QueryRowContext(
+		ctx,
+		query,
+		attempt.JobID,
+		attempt.IncidentID,
+		attempt.WorkerID,
+		attempt.Channel,
+		attempt.Attempt,
+		attempt.Success,
+		attempt.StartedAt,
+		attempt.FinishedAt,
+		attempt.DurationMS,
+		attempt.ErrorMessage,
+		attempt.ResponseCode,
+	).Scan(&attempt.ID)
+	if err != nil {
+		return fmt.Errorf("repository: failed to record notification attempt: %w", err)
+	}
+	return nil
+}
+
+func (r *attemptRepository) GetAttemptsByIncidentID(ctx context.Context, incidentID string) ([]*model.NotificationAttempt, error) {
+	query := `
+		SELECT ` + attemptColumns + `
+		FROM notification_attempts
+		WHERE incident_id = $1
+		ORDER BY started_at ASC`
+
+	rows, err := r.DB.QueryContext(ctx, query, incidentID)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to query notification attempts: %w", err)
+	}
+	return scanAttempts(rows)
+}
+
+func (r *attemptRepository) GetAttemptsByJobID(ctx context.Context, jobID string) ([]*model.NotificationAttempt, error) {
+	query := `
+		SELECT ` + attemptColumns + `
+		FROM notification_attempts
+		WHERE job_id = $1
+		ORDER BY started_at ASC`
+
+	rows, err := r.DB.QueryContext(ctx, query, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to query notification attempts: %w", err)
+	}
+	return scanAttempts(rows)
+}
+
+func scanAttempts(rows *sql.Rows) ([]*model.NotificationAttempt, error) {
+	defer rows.Close()
+
+	attempts := make([]*model.NotificationAttempt, 0)
+	for rows.Next() {
+		a := &model.NotificationAttempt{}
+		err := rows.Scan(
+			&a.ID,
+			&a.JobID,
+			&a.IncidentID,
+			&a.WorkerID,
+			&a.Channel,
+			&a.Attempt,
+			&a.Success,
+			&a.StartedAt,
+			&a.FinishedAt,
+			&a.DurationMS,
+			&a.ErrorMessage,
+			&a.ResponseCode,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("repository: failed to scan notification attempt row: %w", err)
+		}
+		attempts = append(attempts, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
+	}
+
+	return attempts, nil
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+)
+
+// ErrInvalidCursor is returned for a page cursor that is malformed or was
+// issued for a different sort order.
+var ErrInvalidCursor = errors.New("invalid page cursor")
+
+// incidentSortColumns maps the sort fields a listing accepts onto columns
+var incidentSortColumns = map[string]string{
+	model.SortCreatedAt: "created_at",
+	model.SortUpdatedAt: "updated_at",
+}
+
+// incidentCursor is the position after the last incident of a page: its sort
+// value and id, which breaks ties. The sort it was issued for travels with it.
+type incidentCursor struct {
+	Sort      string    `json:"s"`
+	Direction string    `json:"d"`
+	Value     time.Time `json:"v"`
+	ID        string    `json:"id"`
+}
+
+func encodeIncidentCursor(sort, direction string, last *model.Incident) string {
+	cursor := incidentCursor{Sort: sort, Direction: direction, Value: last.CreatedAt, ID: last.ID}
+	if sort == model.SortUpdatedAt {
+		cursor.Value = last.UpdatedAt
+	}
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeIncidentCursor(encoded, sort, direction string) (*incidentCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor incidentCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if _, err := uuid.Parse(cursor.ID); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.Sort != sort || cursor.Direction != direction {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+)
+
+// Escalation is the paging state of an unacknowledged incident.
+type Escalation struct {
+	IncidentID       string
+	PolicyID         string
+	NextLevel        int // index into the policy's levels
+	NextEscalationAt time.Time
+}
+
+type EscalationRepository interface {
+	WithTx(tx *sql.Tx) EscalationRepository
+	CreateEscalationPolicy(ctx context.Context, policy *model.EscalationPolicy) (*model.EscalationPolicy, error)
+	GetEscalationPolicyByID(ctx context.Context, id string) (*model.EscalationPolicy, error)
+	GetEscalationPolicyByTeam(ctx context.Context, team string) (*model.EscalationPolicy, error)
+	GetAllEscalationPolicies(ctx context.Context) ([]*model.EscalationPolicy, error)
+	UpdateEscalationPolicy(ctx context.Context, policy *model.EscalationPolicy) (*model.EscalationPolicy, error)
+	DeleteEscalationPolicy(ctx context.Context, id string) error
+	StartEscalation(ctx context.Context, incidentID string, policyID string, delay time.Duration) error
+	StopEscalation(ctx context.Context, incidentID string) error
+	ClaimDueEscalations(ctx context.Context, limit int) ([]*Escalation, error)
+	AdvanceEscalation(ctx context.Context, incidentID string, nextLevel int, delay time.Duration) error
+}
+
+type escalationRepository struct {
+	DB DBTX
+}
+
+func NewEscalationRepository(db *sql.DB) EscalationRepository {
+	return &escalationRepository{DB: db}
+}
+
+// WithTx returns a copy of the repository whose queries run inside tx
+func (r *escalationRepository) WithTx(tx *sql.Tx) EscalationRepository {
+	return &escalationRepository{DB: tx}
+}
+
+const escalationPolicyColumns = `id, name, team, severities, levels, created_at, updated_at`
+
+func scanEscalationPolicy(row rowScanner) (*model.EscalationPolicy, error) {
+	policy := &model.EscalationPolicy{}
+	var severities, levels []byte
+	err := row.Scan(
+		&policy.ID,
+		&policy.Name,
+		&policy.Team,
+		&severities,
+		&levels,
+		&policy.CreatedAt,
+		&policy.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(severities, &policy.Severities); err != nil {
+		return nil, fmt.Errorf("failed to decode escalation policy severities: %w", err)
+	}
+	if err := json.Unmarshal(levels, &policy.Levels); err != nil {
+		return nil, fmt.Errorf("failed to decode escalation policy levels: %w", err)
+	}
+	return policy, nil
+}
+
+// escalationPolicyArgs encodes the JSONB columns of a policy
+func escalationPolicyArgs(policy *model.EscalationPolicy) (severities, levels []byte, err error) {
+	if severities, err = json.Marshal(nonNil(policy.Severities)); err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal escalation policy severities: %w", err)
+	}
+	if levels, err = json.Marshal(nonNil(policy.Levels)); err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal escalation policy levels: %w", err)
+	}
+	return severities, levels, nil
+}
+
+func (r *escalationRepository) CreateEscalationPolicy(ctx context.Context, policy *model.EscalationPolicy) (*model.EscalationPolicy, error) {
+	severities, levels, err := escalationPolicyArgs(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO escalation_policies (name, team, severities, levels)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + escalationPolicyColumns
+
+	created, err := scanEscalationPolicy(r.DB.QueryRowContext(ctx, query, policy.Name, policy.Team, severities, levels))
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to create escalation policy: %w", err)
+	}
+	return created, nil
+}
+
+func (r *escalationRepository) GetEscalationPolicyByID(ctx context.Context, id string) (*model.EscalationPolicy, error) {
+	query := `SELECT ` + escalationPolicyColumns + ` FROM escalation_policies WHERE id = $1`
+
+	policy, err := scanEscalationPolicy(r.DB.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("repository: failed to get escalation policy %s: %w", id, err)
+	}
+	return policy, nil
+}
+
+// GetEscalationPolicyByTeam returns the policy of the team with the given canonical name
+func (r *escalationRepository) GetEscalationPolicyByTeam(ctx context.Context, team string) (*model.EscalationPolicy, error) {
+	query := `SELECT ` + escalationPolicyColumns + ` FROM escalation_policies WHERE team = $1`
+
+	policy, err := scanEscalationPolicy(r.DB.QueryRowContext(ctx, query, team))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("repository: failed to get escalation policy for team %s: %w", team, err)
+	}
+	return policy, nil
+}
+
+func (r *escalationRepository) GetAllEscalationPolicies(ctx context.Context) ([]*model.EscalationPolicy, error) {
+	query := `SELECT ` + escalationPolicyColumns + ` FROM escalation_policies ORDER BY team ASC`
+
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to query escalation policies: %w", err)
+	}
+	defer rows.Close()
+
+	policies := make([]*model.EscalationPolicy, 0)
+	for rows.Next() {
+		policy, err := scanEscalationPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository: failed to scan escalation policy row: %w", err)
+		}
+		policies = append(policies, policy)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
+	}
+
+	return policies, nil
+}
+
+func (r *escalationRepository) UpdateEscalationPolicy(ctx context.Context, policy *model.EscalationPolicy) (*model.EscalationPolicy, error) {
+	severities, levels, err := escalationPolicyArgs(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE escalation_policies
+		SET name = $2, team = $3, severities = $4, levels = $5, updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + escalationPolicyColumns
+
+	updated, err := scanEscalationPolicy(r.DB.QueryRowContext(ctx, query, policy.ID, policy.Name, policy.Team, severities, levels))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("repository: failed to update escalation policy %s: %w", policy.ID, err)
+	}
+	return updated, nil
+}
+
+func (r *escalationRepository) DeleteEscalationPolicy(ctx context.Context, id string) error {
+	query := `DELETE FROM escalation_policies WHERE id = $1`
+
+	res, err := r.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("repository: failed to delete escalation policy %s: %w", id, err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("repository: failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// StartEscalation schedules the first level of policy for the incident. An
+// incident that is already escalating keeps its current progress.
+func (r *escalationRepository) StartEscalation(ctx context.Context, incidentID string, policyID string, delay time.Duration) error {
+	query := `
+		INSERT INTO incident_escalations (incident_id, policy_id, next_level, next_escalation_at)
+		VALUES ($1, $2, 0, NOW() + make_interval(secs => $3))
+		ON CONFLICT (incident_id) DO NOTHING`
+
+	if _, err := r.DB.ExecContext(ctx, query, incidentID, policyID, delay.Seconds()); err != nil {
+		return fmt.Errorf("repository: failed to start escalation for incident %s: %w", incidentID, err)
+	}
+	return nil
+}
+
+// StopEscalation cancels any pending escalation of the incident
+func (r *escalationRepository) StopEscalation(ctx context.Context, incidentID string) error {
+	query := `DELETE FROM incident_escalations WHERE incident_id = $1`
+
+	if _, err := r.DB.ExecContext(ctx, query, incidentID); err != nil {
+		return fmt.Errorf("repository: failed to stop escalation for incident %s: %w", incidentID, err)
+	}
+	return nil
+}
+
+// ClaimDueEscalations locks up to limit escalations whose next level is due.
+// It must run inside a transaction; the rows stay locked until it ends, and
+// SKIP LOCKED lets several schedulers work through the backlog side by side.
+func (r *escalationRepository) ClaimDueEscalations(ctx context.Context, limit int) ([]*Escalation, error) {
+	query := `
+		SELECT incident_id, policy_id, next_level, next_escalation_at
+		FROM incident_escalations
+		WHERE next_escalation_at <= NOW()
+		ORDER BY next_escalation_at ASC
+		FOR UPDATE SKIP LOCKED
+		LIMIT $1`
+
+	rows, err := r.DB.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to claim due escalations: %w", err)
+	}
+	defer rows.Close()
+
+	escalations := make([]*Escalation, 0)
+	for rows.Next() {
+		e := &Escalation{}
+		if err := rows.Scan(&e.IncidentID, &e.PolicyID, &e.NextLevel, &e.NextEscalationAt); err != nil {
+			return nil, fmt.Errorf("repository: failed to scan escalation row: %w", err)
+		}
+		escalations = append(escalations, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
+	}
+
+	return escalations, nil
+}
+
+// AdvanceEscalation schedules nextLevel to be paged after delay
+func (r *escalationRepository) AdvanceEscalation(ctx context.Context, incidentID string, nextLevel int, delay time.Duration) error {
+	query := `
+		UPDATE incident_escalations
+		SET next_level = $2, next_escalation_at = NOW() + make_interval(secs => $3), updated_at = NOW()
+		WHERE incident_id = $1`
+
+	if _, err := r.DB.ExecContext(ctx, query, incidentID, nextLevel, delay.Seconds()); err != nil {
+		return fmt.Errorf("repository: failed to advance escalation for incident %s: %w", incidentID, err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+)
+
+type EventRepository interface {
+	WithTx(tx *sql.Tx) EventRepository
+	CreateEvent(ctx context.Context, event *model.IncidentEvent) error
+	GetEventsByIncidentID(ctx context.Context, incidentID string) ([]*model.IncidentEvent, error)
+}
+
+type eventRepository struct {
+	DB DBTX
+}
+
+func NewEventRepository(db *sql.DB) EventRepository {
+	return &eventRepository{DB: db}
+}
+
+// WithTx returns a copy of the repository whose queries run inside tx
+func (r *eventRepository) WithTx(tx *sql.Tx) EventRepository {
+	return &eventRepository{DB: tx}
+}
+
+func (r *eventRepository) CreateEvent(ctx context.Context, event *model.IncidentEvent) error {
+	query := `
+		INSERT INTO incident_events (incident_id, event_type, actor, message, metadata)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+
+	// a nil RawMessage would be sent as an empty string, which is not valid JSONB
+	var metadata any
+	if len(event.Metadata) > 0 {
+		metadata = []byte(event.Metadata)
+	}
+
+	err := r.DB.QueryRowContext(
+		ctx,
+		query,
+		event.IncidentID,
+		event.Type,
+		event.Actor,
+		event.Message,
+		metadata,
+	).Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("repository: failed to create incident event: %w", err)
+	}
+	return nil
+}
+
+func (r *eventRepository) GetEventsByIncidentID(ctx context.Context, incidentID string) ([]*model.IncidentEvent, error) {
+	query := `
+		SELECT id, incident_id, event_type, actor, message, metadata, created_at
+		FROM incident_events
+		WHERE incident_id = $1
+		ORDER BY created_at ASC, id ASC`
+
+	rows, err := r.DB.QueryContext(ctx, query, incidentID)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to query incident events: %w", err)
+	}
+	return scanEvents(rows)
+}
+
+func scanEvents(rows *sql.Rows) ([]*model.IncidentEvent, error) {
+	defer rows.Close()
+
+	events := make([]*model.IncidentEvent, 0)
+	for rows.Next() {
+		event := &model.IncidentEvent{}
+		var metadata []byte
+		err := rows.Scan(
+			&event.ID,
+			&event.IncidentID,
+			&event.Type,
+			&event.Actor,
+			&event.Message,
+			&metadata,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("repository: failed to scan incident event row: %w", err)
+		}
+		event.Metadata = metadata
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
+	}
+
+	return events, nil
+}
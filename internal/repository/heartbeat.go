@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+)
+
+type HeartbeatRepository interface {
+	WithTx(tx *sql.Tx) HeartbeatRepository
+	CreateHeartbeat(ctx context.Context, heartbeat *model.Heartbeat) (*model.Heartbeat, error)
+	GetHeartbeatByID(ctx context.Context, id string) (*model.Heartbeat, error)
+	GetHeartbeatByIDForUpdate(ctx context.Context, id string) (*model.Heartbeat, error)
+	GetHeartbeatByName(ctx context.Context, name string) (*model.Heartbeat, error)
+	GetAllHeartbeats(ctx context.Context) ([]*model.Heartbeat, error)
+	UpdateHeartbeat(ctx context.Context, heartbeat *model.Heartbeat) (*model.Heartbeat, error)
+	DeleteHeartbeat(ctx context.Context, id string) error
+	ClaimOverdueHeartbeats(ctx context.Context, limit int, retryAfter time.Duration) ([]*model.Heartbeat, error)
+	SetHeartbeatIncident(ctx context.Context, id string, incidentID string) error
+	RecordPing(ctx context.Context, id string) (*model.Heartbeat, error)
+}
+
+type heartbeatRepository struct {
+	DB DBTX
+}
+
+func NewHeartbeatRepository(db *sql.DB) HeartbeatRepository {
+	return &heartbeatRepository{DB: db}
+}
+
+// WithTx returns a copy of the repository whose queries run inside tx
+func (r *heartbeatRepository) WithTx(tx *sql.Tx) HeartbeatRepository {
+	return &heartbeatRepository{DB: tx}
+}
+
+const heartbeatColumns = `id, name, team, severity, interval_seconds, grace_seconds, status, last_ping_at, incident_id, created_at, updated_at`
+
+func scanHeartbeat(row rowScanner) (*model.Heartbeat, error) {
+	heartbeat := &model.Heartbeat{}
+	err := row.Scan(
+		&heartbeat.ID,
+		&heartbeat.Name,
+		&heartbeat.Team,
+		&heartbeat.Severity,
+		&heartbeat.IntervalSeconds,
+		&heartbeat.GraceSeconds,
+		&heartbeat.Status,
+		&heartbeat.LastPingAt,
+		&heartbeat.IncidentID,
+		&heartbeat.CreatedAt,
+		&heartbeat.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return heartbeat, nil
+}
+
+func scanHeartbeats(rows *sql.Rows) ([]*model.Heartbeat, error) {
+	heartbeats := make([]*model.Heartbeat, 0)
+	for rows.Next() {
+		heartbeat, err := scanHeartbeat(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository: failed to scan heartbeat row: %w", err)
+		}
+		heartbeats = append(heartbeats, heartbeat)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
+	}
+
+	return heartbeats, nil
+}
+
+func (r *heartbeatRepository) CreateHeartbeat(ctx context.Context, heartbeat *model.Heartbeat) (*model.Heartbeat, error) {
+	query := `
+		INSERT INTO heartbeats (name, team, severity, interval_seconds, grace_seconds)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + heartbeatColumns
+
+	created, err := scanHeartbeat(r.DB.QueryRowContext(ctx, query, heartbeat.Name, heartbeat.Team, heartbeat.Severity, heartbeat.IntervalSeconds, heartbeat.GraceSeconds))
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to create heartbeat: %w", err)
+	}
+	return created, nil
+}
+
+func (r *heartbeatRepository) GetHeartbeatByID(ctx context.Context, id string) (*model.Heartbeat, error) {
+	query := `SELECT ` + heartbeatColumns + ` FROM heartbeats WHERE id = $1`
+
+	return r.getHeartbeat(ctx, query, id)
+}
+
+// GetHeartbeatByIDForUpdate locks the heartbeat row until the surrounding
+// transaction ends, so pings and the overdue check see each other's changes
+func (r *heartbeatRepository) GetHeartbeatByIDForUpdate(ctx context.Context, id string) (*model.Heartbeat, error) {
+	query := `SELECT ` + heartbeatColumns + ` FROM heartbeats WHERE id = $1 FOR UPDATE`
+
+	return r.getHeartbeat(ctx, query, id)
+}
+
+func (r *heartbeatRepository) GetHeartbeatByName(ctx context.Context, name string) (*model.Heartbeat, error) {
+	query := `SELECT ` + heartbeatColumns + ` FROM heartbeats WHERE name = $1`
+
+	return r.getHeartbeat(ctx, query, name)
+}
+
+func (r *heartbeatRepository) getHeartbeat(ctx context.Context, query string, key string) (*model.Heartbeat, error) {
+	heartbeat, err := scanHeartbeat(r.DB.QueryRowContext(ctx, query, key))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("repository: failed to get heartbeat %s: %w", key, err)
+	}
+	return heartbeat, nil
+}
+
+func (r *heartbeatRepository) GetAllHeartbeats(ctx context.Context) ([]*model.Heartbeat, error) {
+	query := `SELECT ` + heartbeatColumns + ` FROM heartbeats ORDER BY name ASC`
+
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to query heartbeats: %w", err)
+	}
+	defer rows.Close()
+
+	return scanHeartbeats(rows)
+}
+
+// UpdateHeartbeat replaces a heartbeat's settings; its ping state is kept
+func (r *heartbeatRepository) UpdateHeartbeat(ctx context.Context, heartbeat *model.Heartbeat) (*model.Heartbeat, error) {
+	query := `
+		UPDATE heartbeats
+		SET name = $2, team = $3, severity = $4, interval_seconds = $5, grace_seconds = $6, updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + heartbeatColumns
+
+	updated, err := scanHeartbeat(r.DB.QueryRowContext(ctx, query, heartbeat.ID, heartbeat.Name, heartbeat.Team, heartbeat.Severity, heartbeat.IntervalSeconds, heartbeat.GraceSeconds))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("repository: failed to update heartbeat %s: %w", heartbeat.ID, err)
+	}
+	return updated, nil
+}
+
+func (r *heartbeatRepository) DeleteHeartbeat(ctx context.Context, id string) error {
+	query := `DELETE FROM heartbeats WHERE id = $1`
+
+	res, err := r.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("repository: failed to delete heartbeat %s: %w", id, err)
+	}
+	return checkDeleted(res)
+}
+
+// ClaimOverdueHeartbeats marks up to limit heartbeats whose ping is overdue as
+// down and returns them. A new heartbeat is overdue when it has not pinged
+// within an interval and grace of being created. Heartbeats already down
+// without an incident (the claimer failed to open one) are claimed again once
+// retryAfter has passed. Rows locked by another checker are skipped.
+func (r *heartbeatRepository) ClaimOverdueHeartbeats(ctx context.Context, limit int, retryAfter time.Duration) ([]*model.Heartbeat, error) {
+	query := `
+		UPDATE heartbeats
+		SET status = 'down', updated_at = NOW()
+		WHERE id IN (
+			SELECT id
+			FROM heartbeats
+			WHERE (status IN ('new', 'up') AND COALESCE(last_ping_at, created_at) + make_interval(secs => interval_seconds + grace_seconds) < NOW())
+				OR (status = 'down' AND incident_id IS NULL AND updated_at < NOW() - make_interval(secs => $2))
+			ORDER BY COALESCE(last_ping_at, created_at) ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + heartbeatColumns
+
+	rows, err := r.DB.QueryContext(ctx, query, limit, retryAfter.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to claim overdue heartbeats: %w", err)
+	}
+	defer rows.Close()
+
+	return scanHeartbeats(rows)
+}
+
+// SetHeartbeatIncident records the incident opened for a heartbeat that went down
+func (r *heartbeatRepository) SetHeartbeatIncident(ctx context.Context, id string, incidentID string) error {
+	query := `
+		UPDATE heartbeats
+		SET incident_id = $2, updated_at = NOW()
+		WHERE id = $1`
+
+	if _, err := r.DB.ExecContext(ctx, query, id, incidentID); err != nil {
+		return fmt.Errorf("repository: failed to set incident of heartbeat %s: %w", id, err)
+	}
+	return nil
+}
+
+// RecordPing marks the heartbeat up as of now and forgets its incident
+func (r *heartbeatRepository) RecordPing(ctx context.Context, id string) (*model.Heartbeat, error) {
+	query := `
+		UPDATE heartbeats
+		SET status = 'up', last_ping_at = NOW(), incident_id = NULL, updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + heartbeatColumns
+
+	heartbeat, err := scanHeartbeat(r.DB.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("repository: failed to record ping of heartbeat %s: %w", id, err)
+	}
+	return heartbeat, nil
+}
@@ -3,35 +3,90 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/hascho/go-incident-dashboard-api/internal/model"
 )
 
 type IncidentRepository interface {
+	WithTx(tx *sql.Tx) IncidentRepository
 	CreateIncident(ctx context.Context, incident *model.Incident) (*model.Incident, error)
 	GetIncidentByID(ctx context.Context, id string) (*model.Incident, error)
-	GetAllIncidents(ctx context.Context) ([]*model.Incident, error)
+	GetIncidentByIDForUpdate(ctx context.Context, id string) (*model.Incident, error)
+	GetAllIncidents(ctx context.Context, filter model.IncidentFilter) (*model.IncidentPage, error)
 	UpdateIncident(ctx context.Context, incident *model.Incident) (*model.Incident, error)
 	DeleteIncident(ctx context.Context, id string) error
 	UpdateNotificationStatus(ctx context.Context, id string, status string) error
+	SetAssignee(ctx context.Context, id string, assignee *string) error
+	LockFingerprint(ctx context.Context, fingerprint string) error
+	GetGroupableIncident(ctx context.Context, fingerprint string, since time.Time) (*model.Incident, error)
+	RecordOccurrence(ctx context.Context, id string) (*model.Incident, error)
+	GetMTTAStats(ctx context.Context, filter model.MTTAFilter) ([]*model.MTTAStat, error)
+	SearchIncidents(ctx context.Context, search model.IncidentSearch) ([]*model.IncidentSearchHit, error)
 }
 
 type incidentRepository struct {
-	DB *sql.DB
+	DB DBTX
 }
 
 func NewIncidentRepository(db *sql.DB) IncidentRepository {
 	return &incidentRepository{DB: db}
 }
 
+// WithTx returns a copy of the repository whose queries run inside tx
+func (r *incidentRepository) WithTx(tx *sql.Tx) IncidentRepository {
+	return &incidentRepository{DB: tx}
+}
+
+const incidentColumns = `id, title, description, status, severity, team, tags, assignee, acknowledged_at, acknowledged_by, fingerprint, occurrence_count, last_occurred_at, created_at, updated_at`
+
+func scanIncident(row rowScanner) (*model.Incident, error) {
+	incident := &model.Incident{}
+	var tags []byte
+	err := row.Scan(
+		&incident.ID,
+		&incident.Title,
+		&incident.Description,
+		&incident.Status,
+		&incident.Severity,
+		&incident.Team,
+		&tags,
+		&incident.Assignee,
+		&incident.AcknowledgedAt,
+		&incident.AcknowledgedBy,
+		&incident.Fingerprint,
+		&incident.OccurrenceCount,
+		&incident.LastOccurredAt,
+		&incident.CreatedAt,
+		&incident.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(tags, &incident.Tags); err != nil {
+		return nil, fmt.Errorf("failed to decode incident tags: %w", err)
+	}
+	return incident, nil
+}
+
 func (r *incidentRepository) CreateIncident(ctx context.Context, incident *model.Incident) (*model.Incident, error) {
+	if incident.Tags == nil {
+		incident.Tags = []string{}
+	}
+	tags, err := json.Marshal(incident.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal incident tags: %w", err)
+	}
+
 	query := `
-		INSERT INTO incidents (title, description, status, severity, team)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, created_at, updated_at`
-	err := r.DB.QueryRowContext(
+		INSERT INTO incidents (title, description, status, severity, team, tags, fingerprint)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, occurrence_count, last_occurred_at, created_at, updated_at`
+	err = r.DB.QueryRowContext(
 		ctx,
 		query,
 		incident.Title,
@@ -39,7 +94,9 @@ func (r *incidentRepository) CreateIncident(ctx context.Context, incident *model
 		incident.Status,
 		incident.Severity,
 		incident.Team,
-	).Scan(&incident.ID, &incident.CreatedAt, &incident.UpdatedAt)
+		tags,
+		incident.Fingerprint,
+	).Scan(&incident.ID, &incident.OccurrenceCount, &incident.LastOccurredAt, &incident.CreatedAt, &incident.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("repository: failed to create incident: %w", err)
 	}
@@ -49,21 +106,26 @@ func (r *incidentRepository) CreateIncident(ctx context.Context, incident *model
 
 func (r *incidentRepository) GetIncidentByID(ctx context.Context, id string) (*model.Incident, error) {
 	query := `
-		SELECT id, title, description, status, severity, team, created_at, updated_at
+		SELECT ` + incidentColumns + `
 		FROM incidents
 		WHERE id = $1`
 
-	incident := &model.Incident{}
-	err := r.DB.QueryRowContext(ctx, query, id).Scan(
-		&incident.ID,
-		&incident.Title,
-		&incident.Description,
-		&incident.Status,
-		&incident.Severity,
-		&incident.Team,
-		&incident.CreatedAt,
-		&incident.UpdatedAt,
-	)
+	return r.getIncident(ctx, query, id)
+}
+
+// GetIncidentByIDForUpdate locks the incident row until the surrounding transaction ends
+func (r *incidentRepository) GetIncidentByIDForUpdate(ctx context.Context, id string) (*model.Incident, error) {
+	query := `
+		SELECT ` + incidentColumns + `
+		FROM incidents
+		WHERE id = $1
+		FOR UPDATE`
+
+	return r.getIncident(ctx, query, id)
+}
+
+func (r *incidentRepository) getIncident(ctx context.Context, query string, id string) (*model.Incident, error) {
+	incident, err := scanIncident(r.DB.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, sql.ErrNoRows
@@ -74,13 +136,76 @@ func (r *incidentRepository) GetIncidentByID(ctx context.Context, id string) (*m
 	return incident, nil
 }
 
-func (r *incidentRepository) GetAllIncidents(ctx context.Context) ([]*model.Incident, error) {
-	query := `
-		SELECT id, title, description, status, severity, team, created_at, updated_at
-		FROM incidents
-		ORDER BY created_at DESC`
+// GetAllIncidents returns one page of incidents matching filter. Pages are
+// keyed on the sort column and id rather than offsets, so they stay cheap and
+// stable while incidents are created. filter must carry a valid sort,
+// direction and limit.
+func (r *incidentRepository) GetAllIncidents(ctx context.Context, filter model.IncidentFilter) (*model.IncidentPage, error) {
+	column, ok := incidentSortColumns[filter.Sort]
+	if !ok {
+		return nil, fmt.Errorf("repository: unsupported incident sort %q", filter.Sort)
+	}
+	direction, comparison := "DESC", "<"
+	if filter.Direction == model.SortAsc {
+		direction, comparison = "ASC", ">"
+	}
 
-	rows, err := r.DB.QueryContext(ctx, query)
+	var conditions []string
+	var args []any
+	where := func(condition string, values ...any) {
+		placeholders := make([]any, len(values))
+		for i, v := range values {
+			args = append(args, v)
+			placeholders[i] = len(args)
+		}
+		conditions = append(conditions, fmt.Sprintf(condition, placeholders...))
+	}
+
+	if filter.Assignee != "" {
+		where("assignee = $%d", filter.Assignee)
+	}
+	if len(filter.Statuses) > 0 {
+		where("status = ANY($%d)", filter.Statuses)
+	}
+	if len(filter.Severities) > 0 {
+		severities := make([]string, len(filter.Severities))
+		for i, severity := range filter.Severities {
+			severities[i] = strings.ToLower(severity)
+		}
+		where("lower(severity) = ANY($%d)", severities)
+	}
+	if filter.Team != "" {
+		where("team = $%d", filter.Team)
+	}
+	if filter.CreatedAfter != nil {
+		where("created_at >= $%d", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		where("created_at < $%d", *filter.CreatedBefore)
+	}
+	if filter.UpdatedAfter != nil {
+		where("updated_at >= $%d", *filter.UpdatedAfter)
+	}
+	if filter.UpdatedBefore != nil {
+		where("updated_at < $%d", *filter.UpdatedBefore)
+	}
+	if filter.Cursor != "" {
+		cursor, err := decodeIncidentCursor(filter.Cursor, filter.Sort, filter.Direction)
+		if err != nil {
+			return nil, err
+		}
+		where("("+column+", id) "+comparison+" ($%d, $%d)", cursor.Value, cursor.ID)
+	}
+
+	query := `SELECT ` + incidentColumns + ` FROM incidents`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	// one extra row tells us whether there is another page
+	args = append(args, filter.Limit+1)
+	query += fmt.Sprintf(` ORDER BY %s %s, id %s LIMIT $%d`, column, direction, direction, len(args))
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("repository: failed to query incidents: %w", err)
 	}
@@ -89,17 +214,7 @@ func (r *incidentRepository) GetAllIncidents(ctx context.Context) ([]*model.Inci
 	incidents := make([]*model.Incident, 0)
 
 	for rows.Next() {
-		incident := &model.Incident{}
-		err := rows.Scan(
-			&incident.ID,
-			&incident.Title,
-			&incident.Description,
-			&incident.Status,
-			&incident.Severity,
-			&incident.Team,
-			&incident.CreatedAt,
-			&incident.UpdatedAt,
-		)
+		incident, err := scanIncident(rows)
 		if err != nil {
 			return nil, fmt.Errorf("repository: failed to scan incident row: %w", err)
 		}
@@ -110,29 +225,23 @@ func (r *incidentRepository) GetAllIncidents(ctx context.Context) ([]*model.Inci
 		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
 	}
 
-	return incidents, nil
+	page := &model.IncidentPage{Incidents: incidents}
+	if len(incidents) > filter.Limit {
+		page.Incidents = incidents[:filter.Limit]
+		last := page.Incidents[filter.Limit-1]
+		page.NextCursor = encodeIncidentCursor(filter.Sort, filter.Direction, last)
+	}
+	return page, nil
 }
 
 func (r *incidentRepository) UpdateIncident(ctx context.Context, incident *model.Incident) (*model.Incident, error) {
 	query := `
 		UPDATE incidents
-		SET status = $2, description = $3, updated_at = NOW()
+		SET status = $2, severity = $3, description = $4, acknowledged_at = $5, acknowledged_by = $6, updated_at = NOW()
 		WHERE id = $1
-		RETURNING id, title, description, status, severity, team, created_at, updated_at`
-
-	updatedIncident := &model.Incident{}
-
-	err := r.DB.QueryRowContext(ctx, query, incident.ID, incident.Status, incident.Description).Scan(
-		&updatedIncident.ID,
-		&updatedIncident.Title,
-		&updatedIncident.Description,
-		&updatedIncident.Status,
-		&updatedIncident.Severity,
-		&updatedIncident.Team,
-		&updatedIncident.CreatedAt,
-		&updatedIncident.UpdatedAt,
-	)
+		RETURNING ` + incidentColumns
 
+	updatedIncident, err := scanIncident(r.DB.QueryRowContext(ctx, query, incident.ID, incident.Status, incident.Severity, incident.Description, incident.AcknowledgedAt, incident.AcknowledgedBy))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, sql.ErrNoRows
@@ -172,3 +281,180 @@ func (r *incidentRepository) UpdateNotificationStatus(ctx context.Context, id st
 	_, err := r.DB.ExecContext(ctx, query, id, status)
 	return err
 }
+
+// SetAssignee records the incident commander, or clears it when assignee is nil
+func (r *incidentRepository) SetAssignee(ctx context.Context, id string, assignee *string) error {
+	query := `
+		UPDATE incidents
+		SET assignee = $2, updated_at = NOW()
+		WHERE id = $1`
+
+	if _, err := r.DB.ExecContext(ctx, query, id, assignee); err != nil {
+		return fmt.Errorf("repository: failed to set assignee of incident %s: %w", id, err)
+	}
+	return nil
+}
+
+// LockFingerprint serialises incident creation for one fingerprint until the
+// surrounding transaction ends, so simultaneous duplicates cannot each open
+// an incident.
+func (r *incidentRepository) LockFingerprint(ctx context.Context, fingerprint string) error {
+	query := `SELECT pg_advisory_xact_lock(hashtextextended('incident:' || $1, 0))`
+
+	if _, err := r.DB.ExecContext(ctx, query, fingerprint); err != nil {
+		return fmt.Errorf("repository: failed to lock fingerprint %s: %w", fingerprint, err)
+	}
+	return nil
+}
+
+// GetGroupableIncident returns the most recently reported active incident with
+// the fingerprint that last occurred at or after since, locked for update.
+func (r *incidentRepository) GetGroupableIncident(ctx context.Context, fingerprint string, since time.Time) (*model.Incident, error) {
+	query := `
+		SELECT ` + incidentColumns + `
+		FROM incidents
+		WHERE fingerprint = $1
+			AND status NOT IN ('resolved', 'closed')
+			AND last_occurred_at >= $2
+		ORDER BY last_occurred_at DESC
+		LIMIT 1
+		FOR UPDATE`
+
+	incident, err := scanIncident(r.DB.QueryRowContext(ctx, query, fingerprint, since))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("repository: failed to find incident for fingerprint %s: %w", fingerprint, err)
+	}
+	return incident, nil
+}
+
+// RecordOccurrence counts another report of the incident
+func (r *incidentRepository) RecordOccurrence(ctx context.Context, id string) (*model.Incident, error) {
+	query := `
+		UPDATE incidents
+		SET occurrence_count = occurrence_count + 1, last_occurred_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + incidentColumns
+
+	incident, err := scanIncident(r.DB.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("repository: failed to record occurrence of incident %s: %w", id, err)
+	}
+	return incident, nil
+}
+
+// GetMTTAStats averages the time from creation to first acknowledgement per team
+// and severity. Incidents that were never acknowledged are left out.
+func (r *incidentRepository) GetMTTAStats(ctx context.Context, filter model.MTTAFilter) ([]*model.MTTAStat, error) {
+	query := `
+		SELECT team, severity, COUNT(*), AVG(EXTRACT(EPOCH FROM acknowledged_at - created_at))::float8
+		FROM incidents
+		WHERE acknowledged_at IS NOT NULL
+		  AND created_at >= $1
+		  AND ($2::text = '' OR lower(team) = lower($2))
+		  AND ($3::text = '' OR lower(severity) = lower($3))
+		GROUP BY team, severity
+		ORDER BY team, severity`
+
+	rows, err := r.DB.QueryContext(ctx, query, filter.Since, filter.Team, filter.Severity)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to query mtta stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make([]*model.MTTAStat, 0)
+	for rows.Next() {
+		stat := &model.MTTAStat{}
+		if err := rows.Scan(&stat.Team, &stat.Severity, &stat.Acknowledged, &stat.MTTASeconds); err != nil {
+			return nil, fmt.Errorf("repository: failed to scan mtta stat row: %w", err)
+		}
+		stats = append(stats, stat)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
+	}
+
+	return stats, nil
+}
+
+// htmlEscaped wraps a text expression so it evaluates to the text with &, <
+// and > escaped. ts_headline copies the document into the snippet verbatim
+// around its own markup, so the document is escaped before it is highlighted.
+func htmlEscaped(expr string) string {
+	return `replace(replace(replace(` + expr + `, '&', '&amp;'), '<', '&lt;'), '>', '&gt;')`
+}
+
+// SearchIncidents ranks the incidents whose title, description or notes match
+// search.Text (websearch_to_tsquery syntax: quoted phrases, "or", -exclusions)
+// and highlights where they matched. Only the returned page is highlighted.
+func (r *incidentRepository) SearchIncidents(ctx context.Context, search model.IncidentSearch) ([]*model.IncidentSearchHit, error) {
+	conditions := []string{"search_vector @@ websearch_to_tsquery('english', $1)"}
+	args := []any{search.Text}
+	if search.Team != "" {
+		args = append(args, search.Team)
+		conditions = append(conditions, fmt.Sprintf("team = $%d", len(args)))
+	}
+	if len(search.Severities) > 0 {
+		severities := make([]string, len(search.Severities))
+		for i, severity := range search.Severities {
+			severities[i] = strings.ToLower(severity)
+		}
+		args = append(args, severities)
+		conditions = append(conditions, fmt.Sprintf("lower(severity) = ANY($%d)", len(args)))
+	}
+	if len(search.Statuses) > 0 {
+		args = append(args, search.Statuses)
+		conditions = append(conditions, fmt.Sprintf("status = ANY($%d)", len(args)))
+	}
+	args = append(args, search.Limit)
+
+	query := `
+		WITH hits AS (
+			SELECT id AS hit_id, ts_rank_cd(search_vector, websearch_to_tsquery('english', $1)) AS rank
+			FROM incidents
+			WHERE ` + strings.Join(conditions, " AND ") + fmt.Sprintf(`
+			ORDER BY rank DESC, created_at DESC
+			LIMIT $%d
+		)`, len(args)) + `
+		SELECT ` + incidentColumns + `, h.rank::float8,
+			ts_headline('english', ` + htmlEscaped(`title || ' ' || description || ' ' || coalesce(n.notes, '')`) + `,
+				websearch_to_tsquery('english', $1),
+				'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MinWords=5, MaxWords=20, FragmentDelimiter=" … "')
+		FROM hits h
+		JOIN incidents ON incidents.id = h.hit_id
+		LEFT JOIN LATERAL (
+			SELECT string_agg(message, ' ' ORDER BY created_at) AS notes
+			FROM incident_events
+			WHERE incident_id = h.hit_id AND event_type = 'note'
+		) n ON true
+		ORDER BY h.rank DESC, created_at DESC`
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to search incidents: %w", err)
+	}
+	defer rows.Close()
+
+	hits := make([]*model.IncidentSearchHit, 0)
+	for rows.Next() {
+		hit := &model.IncidentSearchHit{}
+		incident, err := scanIncident(withExtraColumns(rows, &hit.Rank, &hit.Snippet))
+		if err != nil {
+			return nil, fmt.Errorf("repository: failed to scan search hit row: %w", err)
+		}
+		hit.Incident = incident
+		hits = append(hits, hit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
+	}
+
+	return hits, nil
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+)
+
+type IntegrationRepository interface {
+	CreateIntegration(ctx context.Context, integration *model.Integration) (*model.Integration, error)
+	GetIntegrationByID(ctx context.Context, id string) (*model.Integration, error)
+	GetIntegrationByName(ctx context.Context, name string) (*model.Integration, error)
+	GetAllIntegrations(ctx context.Context) ([]*model.Integration, error)
+	UpdateIntegration(ctx context.Context, integration *model.Integration) (*model.Integration, error)
+	SetTokenHash(ctx context.Context, id string, tokenHash string) (*model.Integration, error)
+	DeleteIntegration(ctx context.Context, id string) error
+}
+
+type integrationRepository struct {
+	DB DBTX
+}
+
+func NewIntegrationRepository(db *sql.DB) IntegrationRepository {
+	return &integrationRepository{DB: db}
+}
+
+const integrationColumns = `id, name, mapping, token_hash, created_at, updated_at`
+
+func scanIntegration(row rowScanner) (*model.Integration, error) {
+	integration := &model.Integration{}
+	var mapping []byte
+	err := row.Scan(
+		&integration.ID,
+		&integration.Name,
+		&mapping,
+		&integration.TokenHash,
+		&integration.CreatedAt,
+		&integration.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(mapping, &integration.Mapping); err != nil {
+		return nil, fmt.Errorf("failed to decode integration mapping: %w", err)
+	}
+	return integration, nil
+}
+
+func (r *integrationRepository) CreateIntegration(ctx context.Context, integration *model.Integration) (*model.Integration, error) {
+	mapping, err := json.Marshal(integration.Mapping)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal integration mapping: %w", err)
+	}
+
+	query := `
+		INSERT INTO integrations (name, mapping, token_hash)
+		VALUES ($1, $2, $3)
+		RETURNING ` + integrationColumns
+
+	created, err := scanIntegration(r.DB.QueryRowContext(ctx, query, integration.Name, mapping, integration.TokenHash))
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to create integration: %w", err)
+	}
+	return created, nil
+}
+
+func (r *integrationRepository) GetIntegrationByID(ctx context.Context, id string) (*model.Integration, error) {
+	query := `SELECT ` + integrationColumns + ` FROM integrations WHERE id = $1`
+
+	return r.getIntegration(ctx, query, id)
+}
+
+func (r *integrationRepository) GetIntegrationByName(ctx context.Context, name string) (*model.Integration, error) {
+	query := `SELECT ` + integrationColumns + ` FROM integrations WHERE name = $1`
+
+	return r.getIntegration(ctx, query, name)
+}
+
+func (r *integrationRepository) getIntegration(ctx context.Context, query string, key string) (*model.Integration, error) {
+	integration, err := scanIntegration(r.DB.QueryRowContext(ctx, query, key))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("repository: failed to get integration %s: %w", key, err)
+	}
+	return integration, nil
+}
+
+func (r *integrationRepository) GetAllIntegrations(ctx context.Context) ([]*model.Integration, error) {
+	query := `SELECT ` + integrationColumns + ` FROM integrations ORDER BY name ASC`
+
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to query integrations: %w", err)
+	}
+	defer rows.Close()
+
+	integrations := make([]*model.Integration, 0)
+	for rows.Next() {
+		integration, err := scanIntegration(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository: failed to scan integration row: %w", err)
+		}
+		integrations = append(integrations, integration)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
+	}
+
+	return integrations, nil
+}
+
+// UpdateIntegration replaces an integration's name and mapping; its token is kept
+func (r *integrationRepository) UpdateIntegration(ctx context.Context, integration *model.Integration) (*model.Integration, error) {
+	mapping, err := json.Marshal(integration.Mapping)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal integration mapping: %w", err)
+	}
+
+	query := `
+		UPDATE integrations
+		SET name = $2, mapping = $3, updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + integrationColumns
+
+	updated, err := scanIntegration(r.DB.QueryRowContext(ctx, query, integration.ID, integration.Name, mapping))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("repository: failed to update integration %s: %w", integration.ID, err)
+	}
+	return updated, nil
+}
+
+// SetTokenHash replaces an integration's token, invalidating the old one
+func (r *integrationRepository) SetTokenHash(ctx context.Context, id string, tokenHash string) (*model.Integration, error) {
+	query := `
+		UPDATE integrations
+		SET token_hash = $2, updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + integrationColumns
+
+	updated, err := scanIntegration(r.DB.QueryRowContext(ctx, query, id, tokenHash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("repository: failed to rotate token of integration %s: %w", id, err)
+	}
+	return updated, nil
+}
+
+func (r *integrationRepository) DeleteIntegration(ctx context.Context, id string) error {
+	query := `DELETE FROM integrations WHERE id = $1`
+
+	res, err := r.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("repository: failed to delete integration %s: %w", id, err)
+	}
+	return checkDeleted(res)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,110 +12,345 @@ import (
 	"github.com/hascho/go-incident-dashboard-api/internal/model"
 )
 
+// Notification job statuses.
+const (
+	JobStatusPending           = "PENDING"
+	JobStatusProcessing        = "PROCESSING"
+	JobStatusSuccess           = "SUCCESS"
+	JobStatusFailed            = "FAILED"
+	JobStatusPermanentlyFailed = "PERMANENTLY_FAILED"
+	JobStatusDiscarded         = "DISCARDED"
+)
+
+// Job types, each with its own retry policy in the worker.
+const (
+	JobTypeNotification = "notification" // incident notifications to responders
+	JobTypeWebhook      = "webhook"      // event deliveries to webhook subscribers
+)
+
+// JobTarget identifies where a job is delivered: a notifier channel and a
+// channel specific destination (empty means the notifier's default).
+type JobTarget struct {
+	Channel     string
+	Destination string
+}
+
+// JobSpec describes a job to enqueue for an incident event.
+type JobSpec struct {
+	Type      string
+	EventType string
+	Incident  *model.Incident // snapshot delivered as the job payload
+	Changes   []model.FieldChange
+	Target    JobTarget
+}
+
+// ErrLeaseLost is returned when a worker reports on a job whose lease it no longer holds.
+var ErrLeaseLost = errors.New("job lease is no longer held by this worker")
+
 type Job struct {
-	ID         uuid.UUID
-	IncidentID uuid.UUID
-	Status     string
-	Payload    json.RawMessage
-	Retries    int
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
+	ID            uuid.UUID
+	IncidentID    uuid.UUID
+	Type          string
+	EventType     string
+	Channel       string
+	Destination   string
+	Status        string
+	Payload       json.RawMessage
+	Changes       []model.FieldChange
+	Retries       int
+	LastError     *string
+	NextAttemptAt time.Time
+	LockedBy      *string
+	LockedUntil   *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
 }
 
 type JobRepository interface {
-	CreateJob(ctx context.Context, incident *model.Incident) error
-	FetchPendingJobs(ctx context.Context, limit int) ([]*Job, error)
-	UpdateJobStatus(ctx context.Context, jobID uuid.UUID, status string) error
-	FailJobWithRetry(ctx context.Context, jobID uuid.UUID, maxRetries int) error
+	WithTx(tx *sql.Tx) JobRepository
+	CreateJob(ctx context.Context, spec JobSpec) (*Job, error)
+	ClaimJobs(ctx context.Context, workerID string, limit int, lease time.Duration) ([]*Job, error)
+	UpdateJobStatus(ctx context.Context, jobID uuid.UUID, workerID string, status string) error
+	FailJobWithRetry(ctx context.Context, jobID uuid.UUID, workerID string, maxAttempts int, nextAttemptAt time.Time, lastError string) (string, error)
+	ReleaseExpiredLeases(ctx context.Context) (int64, error)
+	GetJobByID(ctx context.Context, jobID uuid.UUID) (*Job, error)
+	ListJobsByStatus(ctx context.Context, status string, limit int) ([]*Job, error)
+	RequeueJobs(ctx context.Context, jobIDs []uuid.UUID) ([]*Job, error)
+	DiscardJobs(ctx context.Context, jobIDs []uuid.UUID) ([]*Job, error)
 }
 
 type jobRepository struct {
-	DB *sql.DB
+	DB DBTX
 }
 
 func NewJobRepository(db *sql.DB) JobRepository {
 	return &jobRepository{DB: db}
 }
 
-func (r *jobRepository) CreateJob(ctx context.Context, incident *model.Incident) error {
-	payload, err := json.Marshal(incident)
-	if err != nil {
-		return fmt.Errorf("failed to marshal incident payload: %w", err)
-	}
+// WithTx returns a copy of the repository whose queries run inside tx
+func (r *jobRepository) WithTx(tx *sql.Tx) JobRepository {
+	return &jobRepository{DB: tx}
+}
 
-	query := `
-		INSERT INTO notification_jobs (incident_id, payload, status)
-		VALUES ($1, $2, 'PENDING')`
+const jobColumns = `id, incident_id, job_type, event_type, channel, destination, payload, changes, retries, last_error, next_attempt_at, locked_by, locked_until, created_at, updated_at, status`
 
-	_, err = r.DB.ExecContext(ctx, query, incident.ID, payload)
+func scanJob(row rowScanner) (*Job, error) {
+	job := &Job{}
+	var changes []byte
+	err := row.Scan(
+		&job.ID,
+		&job.IncidentID,
+		&job.Type,
+		&job.EventType,
+		&job.Channel,
+		&job.Destination,
+		&job.Payload,
+		&changes,
+		&job.Retries,
+		&job.LastError,
+		&job.NextAttemptAt,
+		&job.LockedBy,
+		&job.LockedUntil,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+		&job.Status,
+	)
 	if err != nil {
-		return fmt.Errorf("repository: failed to insert job: %w", err)
+		return nil, err
 	}
-	return nil
+	if err := json.Unmarshal(changes, &job.Changes); err != nil {
+		return nil, fmt.Errorf("failed to decode job changes: %w", err)
+	}
+	return job, nil
 }
 
-func (r *jobRepository) FetchPendingJobs(ctx context.Context, limit int) ([]*Job, error) {
-	query := `
-		SELECT id, incident_id, payload, retries, created_at, updated_at, status
-		FROM notification_jobs
-		WHERE status = 'PENDING' OR (status = 'FAILED' AND retries < 3)
-		ORDER BY created_at ASC
-		FOR UPDATE SKIP LOCKED
-		LIMIT $1`
-
-	rows, err := r.DB.QueryContext(ctx, query, limit)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch pending jobs: %w", err)
-	}
+func scanJobs(rows *sql.Rows) ([]*Job, error) {
 	defer rows.Close()
 
-	var jobs []*Job
+	jobs := make([]*Job, 0)
 	for rows.Next() {
-		job := &Job{}
-		err := rows.Scan(
-			&job.ID,
-			&job.IncidentID,
-			&job.Payload,
-			&job.Retries,
-			&job.CreatedAt,
-			&job.UpdatedAt,
-			&job.Status,
-		)
+		job, err := scanJob(rows)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning job row: %w", err)
 		}
 		jobs = append(jobs, job)
 	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
+	}
+
 	return jobs, nil
 }
 
-func (r *jobRepository) UpdateJobStatus(ctx context.Context, jobID uuid.UUID, status string) error {
+func (r *jobRepository) CreateJob(ctx context.Context, spec JobSpec) (*Job, error) {
+	payload, err := json.Marshal(spec.Incident)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal incident payload: %w", err)
+	}
+	changes, err := json.Marshal(nonNil(spec.Changes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal job changes: %w", err)
+	}
+
+	query := `
+		INSERT INTO notification_jobs (incident_id, job_type, event_type, channel, destination, payload, changes, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, 'PENDING')
+		RETURNING ` + jobColumns
+
+	job, err := scanJob(r.DB.QueryRowContext(
+		ctx,
+		query,
+		spec.Incident.ID,
+		spec.Type,
+		spec.EventType,
+		spec.Target.Channel,
+		spec.Target.Destination,
+		payload,
+		changes,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to insert job: %w", err)
+	}
+	return job, nil
+}
+
+// ClaimJobs atomically leases up to limit runnable jobs to workerID. A job is
+// runnable once its next_attempt_at has passed. The inner SKIP LOCKED select and
+// the outer update run as one statement, so concurrent workers can never claim
+// the same job.
+func (r *jobRepository) ClaimJobs(ctx context.Context, workerID string, limit int, lease time.Duration) ([]*Job, error) {
 	query := `
 		UPDATE notification_jobs
-		SET status = $2, updated_at = NOW()
-		WHERE id = $1`
+		SET status = 'PROCESSING',
+			locked_by = $1,
+			locked_until = NOW() + make_interval(secs => $3),
+			updated_at = NOW()
+		WHERE id IN (
+			SELECT id
+			FROM notification_jobs
+			WHERE status IN ('PENDING', 'FAILED') AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at ASC
+			FOR UPDATE SKIP LOCKED
+			LIMIT $2
+		)
+		RETURNING ` + jobColumns
 
-	_, err := r.DB.ExecContext(ctx, query, jobID, status)
+	rows, err := r.DB.QueryContext(ctx, query, workerID, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim pending jobs: %w", err)
+	}
+	return scanJobs(rows)
+}
+
+// UpdateJobStatus records the final status of a job and releases its lease.
+func (r *jobRepository) UpdateJobStatus(ctx context.Context, jobID uuid.UUID, workerID string, status string) error {
+	query := `
+		UPDATE notification_jobs
+		SET status = $3, locked_by = NULL, locked_until = NULL, updated_at = NOW()
+		WHERE id = $1 AND locked_by = $2`
+
+	res, err := r.DB.ExecContext(ctx, query, jobID, workerID, status)
 	if err != nil {
 		return fmt.Errorf("failed to update job status: %w", err)
 	}
-	return nil
+	return checkLeaseHeld(res)
 }
 
-func (r *jobRepository) FailJobWithRetry(ctx context.Context, jobID uuid.UUID, maxRetries int) error {
+// FailJobWithRetry counts a failed attempt and schedules the next one at
+// nextAttemptAt, or dead-letters the job once maxAttempts is reached. It
+// returns the job's resulting status.
+func (r *jobRepository) FailJobWithRetry(ctx context.Context, jobID uuid.UUID, workerID string, maxAttempts int, nextAttemptAt time.Time, lastError string) (string, error) {
 	query := `
 		UPDATE notification_jobs
 		SET retries = retries + 1,
 			status = CASE
-				WHEN retries + 1 >= $2 THEN 'PERMANENTLY_FAILED'
+				WHEN retries + 1 >= $3 THEN 'PERMANENTLY_FAILED'
 				ELSE 'FAILED'
 			END,
+			next_attempt_at = $4,
+			last_error = $5,
+			locked_by = NULL,
+			locked_until = NULL,
 			updated_at = NOW()
-		WHERE id = $1`
+		WHERE id = $1 AND locked_by = $2
+		RETURNING status`
 
-	_, err := r.DB.ExecContext(ctx, query, jobID, maxRetries)
+	var status string
+	err := r.DB.QueryRowContext(ctx, query, jobID, workerID, maxAttempts, nextAttemptAt, lastError).Scan(&status)
 	if err != nil {
-		return fmt.Errorf("failed to update job retry count: %w", err)
+		// no row means the lease expired and the job was reaped or reclaimed
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrLeaseLost
+		}
+		return "", fmt.Errorf("failed to update job retry count: %w", err)
+	}
+	return status, nil
+}
+
+// ReleaseExpiredLeases returns jobs whose worker died or stalled past its lease
+// to the queue. The abandoned run counts as an attempt so a job that keeps
+// crashing its worker is eventually dead-lettered by the next worker to claim
+// it instead of looping forever.
+func (r *jobRepository) ReleaseExpiredLeases(ctx context.Context) (int64, error) {
+	query := `
+		UPDATE notification_jobs
+		SET retries = retries + 1,
+			status = 'FAILED',
+			last_error = 'lease expired before the job completed',
+			next_attempt_at = NOW(),
+			locked_by = NULL,
+			locked_until = NULL,
+			updated_at = NOW()
+		WHERE status = 'PROCESSING' AND locked_until < NOW()`
+
+	res, err := r.DB.ExecContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to release expired job leases: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+func (r *jobRepository) GetJobByID(ctx context.Context, jobID uuid.UUID) (*Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM notification_jobs WHERE id = $1`
+
+	job, err := scanJob(r.DB.QueryRowContext(ctx, query, jobID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("repository: failed to get job %s: %w", jobID, err)
+	}
+	return job, nil
+}
+
+func (r *jobRepository) ListJobsByStatus(ctx context.Context, status string, limit int) ([]*Job, error) {
+	query := `
+		SELECT ` + jobColumns + `
+		FROM notification_jobs
+		WHERE status = $1
+		ORDER BY updated_at DESC
+		LIMIT $2`
+
+	rows, err := r.DB.QueryContext(ctx, query, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to list %s jobs: %w", status, err)
+	}
+	return scanJobs(rows)
+}
+
+// RequeueJobs gives dead-lettered jobs a fresh set of attempts. Jobs that are
+// not dead-lettered are left untouched and omitted from the result.
+func (r *jobRepository) RequeueJobs(ctx context.Context, jobIDs []uuid.UUID) ([]*Job, error) {
+	query := `
+		UPDATE notification_jobs
+		SET status = 'PENDING',
+			retries = 0,
+			last_error = NULL,
+			next_attempt_at = NOW(),
+			updated_at = NOW()
+		WHERE id = ANY($1) AND status = 'PERMANENTLY_FAILED'
+		RETURNING ` + jobColumns
+
+	rows, err := r.DB.QueryContext(ctx, query, uuidStrings(jobIDs))
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to requeue jobs: %w", err)
+	}
+	return scanJobs(rows)
+}
+
+// DiscardJobs marks dead-lettered jobs as deliberately abandoned. Jobs that are
+// not dead-lettered are left untouched and omitted from the result.
+func (r *jobRepository) DiscardJobs(ctx context.Context, jobIDs []uuid.UUID) ([]*Job, error) {
+	query := `
+		UPDATE notification_jobs
+		SET status = 'DISCARDED', updated_at = NOW()
+		WHERE id = ANY($1) AND status = 'PERMANENTLY_FAILED'
+		RETURNING ` + jobColumns
+
+	rows, err := r.DB.QueryContext(ctx, query, uuidStrings(jobIDs))
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to discard jobs: %w", err)
+	}
+	return scanJobs(rows)
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = id.String()
+	}
+	return out
+}
+
+func checkLeaseHeld(res sql.Result) error {
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("repository: failed to check rows affected: %w", err)
+	}
+	// rowsAffected is 0 when the lease expired and the job was reaped or reclaimed
+	if rowsAffected == 0 {
+		return ErrLeaseLost
 	}
 	return nil
 }
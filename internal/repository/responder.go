@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+)
+
+type ResponderRepository interface {
+	WithTx(tx *sql.Tx) ResponderRepository
+	AssignResponder(ctx context.Context, responder *model.IncidentResponder) (*model.IncidentResponder, error)
+	GetResponders(ctx context.Context, incidentID string) ([]*model.IncidentResponder, error)
+	RemoveResponder(ctx context.Context, incidentID string, responderID string) (*model.IncidentResponder, error)
+}
+
+type responderRepository struct {
+	DB DBTX
+}
+
+func NewResponderRepository(db *sql.DB) ResponderRepository {
+	return &responderRepository{DB: db}
+}
+
+// WithTx returns a copy of the repository whose queries run inside tx
+func (r *responderRepository) WithTx(tx *sql.Tx) ResponderRepository {
+	return &responderRepository{DB: tx}
+}
+
+const responderColumns = `id, incident_id, role, name, channel, destination, assigned_by, assigned_at`
+
+func scanResponder(row rowScanner) (*model.IncidentResponder, error) {
+	responder := &model.IncidentResponder{}
+	err := row.Scan(
+		&responder.ID,
+		&responder.IncidentID,
+		&responder.Role,
+		&responder.Responder.Name,
+		&responder.Responder.Channel,
+		&responder.Responder.Destination,
+		&responder.AssignedBy,
+		&responder.AssignedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return responder, nil
+}
+
+// AssignResponder adds a responder to an incident, or changes the role and
+// contact details of one already on it.
+func (r *responderRepository) AssignResponder(ctx context.Context, responder *model.IncidentResponder) (*model.IncidentResponder, error) {
+	query := `
+		INSERT INTO incident_responders (incident_id, role, name, channel, destination, assigned_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (incident_id, name) DO UPDATE
+		SET role = EXCLUDED.role,
+			channel = EXCLUDED.channel,
+			destination = EXCLUDED.destination,
+			assigned_by = EXCLUDED.assigned_by,
+			assigned_at = NOW()
+		RETURNING ` + responderColumns
+
+	assigned, err := scanResponder(r.DB.QueryRowContext(
+		ctx,
+		query,
+		responder.IncidentID,
+		responder.Role,
+		responder.Responder.Name,
+		responder.Responder.Channel,
+		responder.Responder.Destination,
+		responder.AssignedBy,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to assign responder to incident %s: %w", responder.IncidentID, err)
+	}
+	return assigned, nil
+}
+
+func (r *responderRepository) GetResponders(ctx context.Context, incidentID string) ([]*model.IncidentResponder, error) {
+	query := `
+		SELECT ` + responderColumns + `
+		FROM incident_responders
+		WHERE incident_id = $1
+		ORDER BY assigned_at ASC`
+
+	rows, err := r.DB.QueryContext(ctx, query, incidentID)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to query responders: %w", err)
+	}
+	defer rows.Close()
+
+	responders := make([]*model.IncidentResponder, 0)
+	for rows.Next() {
+		responder, err := scanResponder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository: failed to scan responder row: %w", err)
+		}
+		responders = append(responders, responder)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
+	}
+
+	return responders, nil
+}
+
+// RemoveResponder takes a responder off an incident and returns who was removed
+func (r *responderRepository) RemoveResponder(ctx context.Context, incidentID string, responderID string) (*model.IncidentResponder, error) {
+	query := `
+		DELETE FROM incident_responders
+		WHERE incident_id = $1 AND id = $2
+		RETURNING ` + responderColumns
+
+	removed, err := scanResponder(r.DB.QueryRowContext(ctx, query, incidentID, responderID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("repository: failed to remove responder %s: %w", responderID, err)
+	}
+	return removed, nil
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+)
+
+type RoutingRuleRepository interface {
+	WithTx(tx *sql.Tx) RoutingRuleRepository
+	CreateRoutingRule(ctx context.Context, rule *model.RoutingRule) (*model.RoutingRule, error)
+	GetRoutingRuleByID(ctx context.Context, id string) (*model.RoutingRule, error)
+	GetAllRoutingRules(ctx context.Context) ([]*model.RoutingRule, error)
+	GetEnabledRoutingRulesForEvent(ctx context.Context, eventType string) ([]*model.RoutingRule, error)
+	UpdateRoutingRule(ctx context.Context, rule *model.RoutingRule) (*model.RoutingRule, error)
+	DeleteRoutingRule(ctx context.Context, id string) error
+}
+
+type routingRuleRepository struct {
+	DB DBTX
+}
+
+func NewRoutingRuleRepository(db *sql.DB) RoutingRuleRepository {
+	return &routingRuleRepository{DB: db}
+}
+
+// WithTx returns a copy of the repository whose queries run inside tx
+func (r *routingRuleRepository) WithTx(tx *sql.Tx) RoutingRuleRepository {
+	return &routingRuleRepository{DB: tx}
+}
+
+// a rule for every team has no team; it is read back as an empty name
+const routingRuleColumns = `id, name, COALESCE(team, ''), severity, title_pattern, tags, events, targets, enabled, created_at, updated_at`
+
+func scanRoutingRule(row rowScanner) (*model.RoutingRule, error) {
+	rule := &model.RoutingRule{}
+	var tags, events, targets []byte
+	err := row.Scan(
+		&rule.ID,
+		&rule.Name,
+		&rule.Team,
+		&rule.Severity,
+		&rule.TitlePattern,
+		&tags,
+		&events,
+		&targets,
+		&rule.Enabled,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(tags, &rule.Tags); err != nil {
+		return nil, fmt.Errorf("failed to decode routing rule tags: %w", err)
+	}
+	if err := json.Unmarshal(events, &rule.Events); err != nil {
+		return nil, fmt.Errorf("failed to decode routing rule events: %w", err)
+	}
+	if err := json.Unmarshal(targets, &rule.Targets); err != nil {
+		return nil, fmt.Errorf("failed to decode routing rule targets: %w", err)
+	}
+	return rule, nil
+}
+
+// routingRuleArgs encodes the JSONB columns of a rule
+func routingRuleArgs(rule *model.RoutingRule) (tags, events, targets []byte, err error) {
+	if tags, err = json.Marshal(nonNil(rule.Tags)); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to marshal routing rule tags: %w", err)
+	}
+	if events, err = json.Marshal(nonNil(rule.Events)); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to marshal routing rule events: %w", err)
+	}
+	if targets, err = json.Marshal(nonNil(rule.Targets)); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to marshal routing rule targets: %w", err)
+	}
+	return tags, events, targets, nil
+}
+
+// nonNil makes a nil slice encode as [] rather than null
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+func (r *routingRuleRepository) CreateRoutingRule(ctx context.Context, rule *model.RoutingRule) (*model.RoutingRule, error) {
+	tags, events, targets, err := routingRuleArgs(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO routing_rules (name, team, severity, title_pattern, tags, events, targets, enabled)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7, $8)
+		RETURNING ` + routingRuleColumns
+
+	created, err := scanRoutingRule(r.DB.QueryRowContext(ctx, query,
+		rule.Name, rule.Team, rule.Severity, rule.TitlePattern, tags, events, targets, rule.Enabled))
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to create routing rule: %w", err)
+	}
+	return created, nil
+}
+
+func (r *routingRuleRepository) GetRoutingRuleByID(ctx context.Context, id string) (*model.RoutingRule, error) {
+	query := `SELECT ` + routingRuleColumns + ` FROM routing_rules WHERE id = $1`
+
+	rule, err := scanRoutingRule(r.DB.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("repository: failed to get routing rule %s: %w", id, err)
+	}
+	return rule, nil
+}
+
+func (r *routingRuleRepository) GetAllRoutingRules(ctx context.Context) ([]*model.RoutingRule, error) {
+	query := `SELECT ` + routingRuleColumns + ` FROM routing_rules ORDER BY created_at ASC`
+
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to query routing rules: %w", err)
+	}
+	return scanRoutingRules(rows)
+}
+
+// GetEnabledRoutingRulesForEvent returns the enabled rules listening for
+// eventType. Matching against the incident itself happens in the service.
+func (r *routingRuleRepository) GetEnabledRoutingRulesForEvent(ctx context.Context, eventType string) ([]*model.RoutingRule, error) {
+	query := `
+		SELECT ` + routingRuleColumns + `
+		FROM routing_rules
+		WHERE enabled AND events ? $1
+		ORDER BY created_at ASC`
+
+	rows, err := r.DB.QueryContext(ctx, query, eventType)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to query routing rules: %w", err)
+	}
+	return scanRoutingRules(rows)
+}
+
+func (r *routingRuleRepository) UpdateRoutingRule(ctx context.Context, rule *model.RoutingRule) (*model.RoutingRule, error) {
+	tags, events, targets, err := routingRuleArgs(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE routing_rules
+		SET name = $2, team = NULLIF($3, ''), severity = $4, title_pattern = $5, tags = $6, events = $7, targets = $8, enabled = $9, updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + routingRuleColumns
+
+	updated, err := scanRoutingRule(r.DB.QueryRowContext(ctx, query,
+		rule.ID, rule.Name, rule.Team, rule.Severity, rule.TitlePattern, tags, events, targets, rule.Enabled))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("repository: failed to update routing rule %s: %w", rule.ID, err)
+	}
+	return updated, nil
+}
+
+func (r *routingRuleRepository) DeleteRoutingRule(ctx context.Context, id string) error {
+	query := `DELETE FROM routing_rules WHERE id = $1`
+
+	res, err := r.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("repository: failed to delete routing rule %s: %w", id, err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("repository: failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func scanRoutingRules(rows *sql.Rows) ([]*model.RoutingRule, error) {
+	defer rows.Close()
+
+	rules := make([]*model.RoutingRule, 0)
+	for rows.Next() {
+		rule, err := scanRoutingRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository: failed to scan routing rule row: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
+	}
+
+	return rules, nil
+}
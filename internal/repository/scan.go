@@ -0,0 +1,21 @@
+package repository
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// withExtraColumns lets a scanX helper read a row that carries extra computed
+// columns after the ones it knows about, scanning those into extra.
+func withExtraColumns(row rowScanner, extra ...any) rowScanner {
+	return extraColumnsScanner{row: row, extra: extra}
+}
+
+type extraColumnsScanner struct {
+	row   rowScanner
+	extra []any
+}
+
+func (s extraColumnsScanner) Scan(dest ...any) error {
+	return s.row.Scan(append(dest, s.extra...)...)
+}
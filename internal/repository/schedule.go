@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+)
+
+type ScheduleRepository interface {
+	WithTx(tx *sql.Tx) ScheduleRepository
+	CreateSchedule(ctx context.Context, schedule *model.Schedule) (*model.Schedule, error)
+	GetScheduleByID(ctx context.Context, id string) (*model.Schedule, error)
+	GetAllSchedules(ctx context.Context) ([]*model.Schedule, error)
+	UpdateSchedule(ctx context.Context, schedule *model.Schedule) (*model.Schedule, error)
+	DeleteSchedule(ctx context.Context, id string) error
+	CreateOverride(ctx context.Context, override *model.ScheduleOverride) (*model.ScheduleOverride, error)
+	GetUpcomingOverrides(ctx context.Context, scheduleID string) ([]*model.ScheduleOverride, error)
+	GetActiveOverride(ctx context.Context, scheduleID string, at time.Time) (*model.ScheduleOverride, error)
+	DeleteOverride(ctx context.Context, scheduleID string, overrideID string) error
+}
+
+type scheduleRepository struct {
+	DB DBTX
+}
+
+func NewScheduleRepository(db *sql.DB) ScheduleRepository {
+	return &scheduleRepository{DB: db}
+}
+
+// WithTx returns a copy of the repository whose queries run inside tx
+func (r *scheduleRepository) WithTx(tx *sql.Tx) ScheduleRepository {
+	return &scheduleRepository{DB: tx}
+}
+
+const scheduleColumns = `id, name, team, time_zone, layers, created_at, updated_at`
+
+const overrideColumns = `id, schedule_id, responder, starts_at, ends_at, created_at`
+
+func scanSchedule(row rowScanner) (*model.Schedule, error) {
+	schedule := &model.Schedule{}
+	var layers []byte
+	err := row.Scan(
+		&schedule.ID,
+		&schedule.Name,
+		&schedule.Team,
+		&schedule.TimeZone,
+		&layers,
+		&schedule.CreatedAt,
+		&schedule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(layers, &schedule.Layers); err != nil {
+		return nil, fmt.Errorf("failed to decode schedule layers: %w", err)
+	}
+	return schedule, nil
+}
+
+func scanOverride(row rowScanner) (*model.ScheduleOverride, error) {
+	override := &model.ScheduleOverride{}
+	var responder []byte
+	err := row.Scan(
+		&override.ID,
+		&override.ScheduleID,
+		&responder,
+		&override.StartsAt,
+		&override.EndsAt,
+		&override.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(responder, &override.Responder); err != nil {
+		return nil, fmt.Errorf("failed to decode override responder: %w", err)
+	}
+	return override, nil
+}
+
+func (r *scheduleRepository) CreateSchedule(ctx context.Context, schedule *model.Schedule) (*model.Schedule, error) {
+	layers, err := json.Marshal(nonNil(schedule.Layers))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schedule layers: %w", err)
+	}
+
+	query := `
+		INSERT INTO schedules (name, team, time_zone, layers)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + scheduleColumns
+
+	created, err := scanSchedule(r.DB.QueryRowContext(ctx, query, schedule.Name, schedule.Team, schedule.TimeZone, layers))
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to create schedule: %w", err)
+	}
+	return created, nil
+}
+
+func (r *scheduleRepository) GetScheduleByID(ctx context.Context, id string) (*model.Schedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM schedules WHERE id = $1`
+
+	schedule, err := scanSchedule(r.DB.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("repository: failed to get schedule %s: %w", id, err)
+	}
+	return schedule, nil
+}
+
+func (r *scheduleRepository) GetAllSchedules(ctx context.Context) ([]*model.Schedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM schedules ORDER BY team ASC, name ASC`
+
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to query schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := make([]*model.Schedule, 0)
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository: failed to scan schedule row: %w", err)
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
+	}
+
+	return schedules, nil
+}
+
+func (r *scheduleRepository) UpdateSchedule(ctx context.Context, schedule *model.Schedule) (*model.Schedule, error) {
+	layers, err := json.Marshal(nonNil(schedule.Layers))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schedule layers: %w", err)
+	}
+
+	query := `
+		UPDATE schedules
+		SET name = $2, team = $3, time_zone = $4, layers = $5, updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + scheduleColumns
+
+	updated, err := scanSchedule(r.DB.QueryRowContext(ctx, query, schedule.ID, schedule.Name, schedule.Team, schedule.TimeZone, layers))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("repository: failed to update schedule %s: %w", schedule.ID, err)
+	}
+	return updated, nil
+}
+
+func (r *scheduleRepository) DeleteSchedule(ctx context.Context, id string) error {
+	query := `DELETE FROM schedules WHERE id = $1`
+
+	res, err := r.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("repository: failed to delete schedule %s: %w", id, err)
+	}
+	return checkDeleted(res)
+}
+
+func (r *scheduleRepository) CreateOverride(ctx context.Context, override *model.ScheduleOverride) (*model.ScheduleOverride, error) {
+	responder, err := json.Marshal(override.Responder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal override responder: %w", err)
+	}
+
+	query := `
+		INSERT INTO schedule_overrides (schedule_id, responder, starts_at, ends_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + overrideColumns
+
+	created, err := scanOverride(r.DB.QueryRowContext(ctx, query, override.ScheduleID, responder, override.StartsAt, override.EndsAt))
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to create schedule override: %w", err)
+	}
+	return created, nil
+}
+
+// GetUpcomingOverrides returns the overrides of a schedule that have not ended yet
+func (r *scheduleRepository) GetUpcomingOverrides(ctx context.Context, scheduleID string) ([]*model.ScheduleOverride, error) {
+	query := `
+		SELECT ` + overrideColumns + `
+		FROM schedule_overrides
+		WHERE schedule_id = $1 AND ends_at > NOW()
+		ORDER BY starts_at ASC`
+
+	rows, err := r.DB.QueryContext(ctx, query, scheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to query schedule overrides: %w", err)
+	}
+	defer rows.Close()
+
+	overrides := make([]*model.ScheduleOverride, 0)
+	for rows.Next() {
+		override, err := scanOverride(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository: failed to scan schedule override row: %w", err)
+		}
+		overrides = append(overrides, override)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
+	}
+
+	return overrides, nil
+}
+
+// GetActiveOverride returns the override covering at, the most recently created
+// one when several overlap. It returns sql.ErrNoRows when there is none.
+func (r *scheduleRepository) GetActiveOverride(ctx context.Context, scheduleID string, at time.Time) (*model.ScheduleOverride, error) {
+	query := `
+		SELECT ` + overrideColumns + `
+		FROM schedule_overrides
+		WHERE schedule_id = $1 AND starts_at <= $2 AND ends_at > $2
+		ORDER BY created_at DESC
+		LIMIT 1`
+
+	override, err := scanOverride(r.DB.QueryRowContext(ctx, query, scheduleID, at))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("repository: failed to get active override for schedule %s: %w", scheduleID, err)
+	}
+	return override, nil
+}
+
+func (r *scheduleRepository) DeleteOverride(ctx context.Context, scheduleID string, overrideID string) error {
+	query := `DELETE FROM schedule_overrides WHERE id = $1 AND schedule_id = $2`
+
+	res, err := r.DB.ExecContext(ctx, query, overrideID, scheduleID)
+	if err != nil {
+		return fmt.Errorf("repository: failed to delete schedule override %s: %w", overrideID, err)
+	}
+	return checkDeleted(res)
+}
+
+// checkDeleted turns a delete that matched no row into sql.ErrNoRows
+func checkDeleted(res sql.Result) error {
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("repository: failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
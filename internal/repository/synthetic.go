@@ -0,0 +1,340 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+)
+
+type SyntheticCheckRepository interface {
+	WithTx(tx *sql.Tx) SyntheticCheckRepository
+	CreateSyntheticCheck(ctx context.Context, check *model.SyntheticCheck) (*model.SyntheticCheck, error)
+	GetSyntheticCheckByID(ctx context.Context, id string) (*model.SyntheticCheck, error)
+	GetSyntheticCheckByIDForUpdate(ctx context.Context, id string) (*model.SyntheticCheck, error)
+	GetSyntheticCheckByName(ctx context.Context, name string) (*model.SyntheticCheck, error)
+	GetAllSyntheticChecks(ctx context.Context) ([]*model.SyntheticCheck, error)
+	UpdateSyntheticCheck(ctx context.Context, check *model.SyntheticCheck) (*model.SyntheticCheck, error)
+	DeleteSyntheticCheck(ctx context.Context, id string) error
+	ClaimDueSyntheticChecks(ctx context.Context, limit int) ([]*model.SyntheticCheck, error)
+	RecordSyntheticResult(ctx context.Context, check *model.SyntheticCheck, result *model.SyntheticCheckResult) (*model.SyntheticCheck, error)
+	SetSyntheticCheckIncident(ctx context.Context, id string, incidentID *string) error
+	GetSyntheticResults(ctx context.Context, checkID string, since time.Time, limit int) ([]*model.SyntheticCheckResult, error)
+	PruneSyntheticResults(ctx context.Context, checkID string, before time.Time) error
+}
+
+type syntheticCheckRepository struct {
+	DB DBTX
+}
+
+func NewSyntheticCheckRepository(db *sql.DB) SyntheticCheckRepository {
+	return &syntheticCheckRepository{DB: db}
+}
+
+// WithTx returns a copy of the repository whose queries run inside tx
+func (r *syntheticCheckRepository) WithTx(tx *sql.Tx) SyntheticCheckRepository {
+	return &syntheticCheckRepository{DB: tx}
+}
+
+const syntheticCheckColumns = `id, name, type, url, method, expected_status, body_contains, host, port, interval_seconds, timeout_seconds, failure_threshold, team, severity, status, consecutive_failures, last_checked_at, next_run_at, incident_id, created_at, updated_at`
+
+const syntheticResultColumns = `id, check_id, checked_at, success, duration_ms, status_code, error_message`
+
+func scanSyntheticCheck(row rowScanner) (*model.SyntheticCheck, error) {
+	check := &model.SyntheticCheck{}
+	err := row.Scan(
+		&check.ID,
+		&check.Name,
+		&check.Type,
+		&check.URL,
+		&check.Method,
+		&check.ExpectedStatus,
+		&check.BodyContains,
+		&check.Host,
+		&check.Port,
+		&check.IntervalSeconds,
+		&check.TimeoutSeconds,
+		&check.FailureThreshold,
+		&check.Team,
+		&check.Severity,
+		&check.Status,
+		&check.ConsecutiveFailures,
+		&check.LastCheckedAt,
+		&check.NextRunAt,
+		&check.IncidentID,
+		&check.CreatedAt,
+		&check.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return check, nil
+}
+
+func scanSyntheticChecks(rows *sql.Rows) ([]*model.SyntheticCheck, error) {
+	checks := make([]*model.SyntheticCheck, 0)
+	for rows.Next() {
+		check, err := scanSyntheticCheck(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository: failed to scan synthetic check row: %w", err)
+		}
+		checks = append(checks, check)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
+	}
+
+	return checks, nil
+}
+
+func (r *syntheticCheckRepository) CreateSyntheticCheck(ctx context.Context, check *model.SyntheticCheck) (*model.SyntheticCheck, error) {
+	query := `
+		INSERT INTO synthetic_checks
+			(name, type, url, method, expected_status, body_contains, host, port, interval_seconds, timeout_seconds, failure_threshold, team, severity)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING ` + syntheticCheckColumns
+
+	created, err := scanSyntheticCheck(r.DB.QueryRowContext(
+		ctx,
+		query,
+		check.Name,
+		check.Type,
+		check.URL,
+		check.Method,
+		check.ExpectedStatus,
+		check.BodyContains,
+		check.Host,
+		check.Port,
+		check.IntervalSeconds,
+		check.TimeoutSeconds,
+		check.FailureThreshold,
+		check.Team,
+		check.Severity,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to create synthetic check: %w", err)
+	}
+	return created, nil
+}
+
+func (r *syntheticCheckRepository) GetSyntheticCheckByID(ctx context.Context, id string) (*model.SyntheticCheck, error) {
+	query := `SELECT ` + syntheticCheckColumns + ` FROM synthetic_checks WHERE id = $1`
+
+	return r.getSyntheticCheck(ctx, query, id)
+}
+
+// GetSyntheticCheckByIDForUpdate locks the check row until the surrounding
+// transaction ends, so it cannot be deleted or run concurrently in the meantime
+func (r *syntheticCheckRepository) GetSyntheticCheckByIDForUpdate(ctx context.Context, id string) (*model.SyntheticCheck, error) {
+	query := `SELECT ` + syntheticCheckColumns + ` FROM synthetic_checks WHERE id = $1 FOR UPDATE`
+
+	return r.getSyntheticCheck(ctx, query, id)
+}
+
+func (r *syntheticCheckRepository) GetSyntheticCheckByName(ctx context.Context, name string) (*model.SyntheticCheck, error) {
+	query := `SELECT ` + syntheticCheckColumns + ` FROM synthetic_checks WHERE name = $1`
+
+	return r.getSyntheticCheck(ctx, query, name)
+}
+
+func (r *syntheticCheckRepository) getSyntheticCheck(ctx context.Context, query string, key string) (*model.SyntheticCheck, error) {
+	check, err := scanSyntheticCheck(r.DB.QueryRowContext(ctx, query, key))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("repository: failed to get synthetic check %s: %w", key, err)
+	}
+	return check, nil
+}
+
+func (r *syntheticCheckRepository) GetAllSyntheticChecks(ctx context.Context) ([]*model.SyntheticCheck, error) {
+	query := `SELECT ` + syntheticCheckColumns + ` FROM synthetic_checks ORDER BY name ASC`
+
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to query synthetic checks: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSyntheticChecks(rows)
+}
+
+// UpdateSyntheticCheck replaces a check's definition and runs it again as soon
+// as possible; its failure streak, status and incident are kept
+func (r *syntheticCheckRepository) UpdateSyntheticCheck(ctx context.Context, check *model.SyntheticCheck) (*model.SyntheticCheck, error) {
+	query := `
+		UPDATE synthetic_checks
+		SET name = $2, type = $3, url = $4, method = $5, expected_status = $6, body_contains = $7, host = $8, port = $9,
+			interval_seconds = $10, timeout_seconds = $11, failure_threshold = $12, team = $13, severity = $14,
+			next_run_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + syntheticCheckColumns
+
+	updated, err := scanSyntheticCheck(r.DB.QueryRowContext(
+		ctx,
+		query,
+		check.ID,
+		check.Name,
+		check.Type,
+		check.URL,
+		check.Method,
+		check.ExpectedStatus,
+		check.BodyContains,
+		check.Host,
+		check.Port,
+		check.IntervalSeconds,
+		check.TimeoutSeconds,
+		check.FailureThreshold,
+		check.Team,
+		check.Severity,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("repository: failed to update synthetic check %s: %w", check.ID, err)
+	}
+	return updated, nil
+}
+
+func (r *syntheticCheckRepository) DeleteSyntheticCheck(ctx context.Context, id string) error {
+	query := `DELETE FROM synthetic_checks WHERE id = $1`
+
+	res, err := r.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("repository: failed to delete synthetic check %s: %w", id, err)
+	}
+	return checkDeleted(res)
+}
+
+// ClaimDueSyntheticChecks returns up to limit checks whose next run is due and
+// moves their next run one interval on, so no other scheduler runs them in
+// the meantime. Rows locked by another scheduler are skipped.
+func (r *syntheticCheckRepository) ClaimDueSyntheticChecks(ctx context.Context, limit int) ([]*model.SyntheticCheck, error) {
+	query := `
+		UPDATE synthetic_checks
+		SET next_run_at = NOW() + make_interval(secs => interval_seconds)
+		WHERE id IN (
+			SELECT id
+			FROM synthetic_checks
+			WHERE next_run_at <= NOW()
+			ORDER BY next_run_at ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + syntheticCheckColumns
+
+	rows, err := r.DB.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to claim due synthetic checks: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSyntheticChecks(rows)
+}
+
+// RecordSyntheticResult stores a run's result and the check's failure streak
+// and status after it. The check's row should be locked by the surrounding
+// transaction, so it cannot be deleted under the insert. The updated check is
+// returned.
+func (r *syntheticCheckRepository) RecordSyntheticResult(ctx context.Context, check *model.SyntheticCheck, result *model.SyntheticCheckResult) (*model.SyntheticCheck, error) {
+	query := `
+		WITH result AS (
+			INSERT INTO synthetic_check_results (check_id, checked_at, success, duration_ms, status_code, error_message)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		)
+		UPDATE synthetic_checks
+		SET consecutive_failures = $7, status = $8, last_checked_at = $2, updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + syntheticCheckColumns
+
+	updated, err := scanSyntheticCheck(r.DB.QueryRowContext(
+		ctx,
+		query,
+		check.ID,
+		result.CheckedAt,
+		result.Success,
+		result.DurationMS,
+		result.StatusCode,
+		result.ErrorMessage,
+		check.ConsecutiveFailures,
+		check.Status,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("repository: failed to record result of synthetic check %s: %w", check.ID, err)
+	}
+	return updated, nil
+}
+
+// SetSyntheticCheckIncident links the incident opened for a check that went
+// down, or unlinks it once resolved when incidentID is nil
+func (r *syntheticCheckRepository) SetSyntheticCheckIncident(ctx context.Context, id string, incidentID *string) error {
+	query := `
+		UPDATE synthetic_checks
+		SET incident_id = $2, updated_at = NOW()
+		WHERE id = $1`
+
+	if _, err := r.DB.ExecContext(ctx, query, id, incidentID); err != nil {
+		return fmt.Errorf("repository: failed to set incident of synthetic check %s: %w", id, err)
+	}
+	return nil
+}
+
+// GetSyntheticResults returns up to limit results of a check recorded since
+// the given time, newest first
+func (r *syntheticCheckRepository) GetSyntheticResults(ctx context.Context, checkID string, since time.Time, limit int) ([]*model.SyntheticCheckResult, error) {
+	query := `
+		SELECT ` + syntheticResultColumns + `
+		FROM synthetic_check_results
+		WHERE check_id = $1 AND checked_at >= $2
+		ORDER BY checked_at DESC
+		LIMIT $3`
+
+	rows, err := r.DB.QueryContext(ctx, query, checkID, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to query results of synthetic check %s: %w", checkID, err)
+	}
+	defer rows.Close()
+
+	results := make([]*model.SyntheticCheckResult, 0)
+	for rows.Next() {
+		result := &model.SyntheticCheckResult{}
+		err := rows.Scan(
+			&result.ID,
+			&result.CheckID,
+			&result.CheckedAt,
+			&result.Success,
+			&result.DurationMS,
+			&result.StatusCode,
+			&result.ErrorMessage,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("repository: failed to scan synthetic check result row: %w", err)
+		}
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
+	}
+
+	return results, nil
+}
+
+// PruneSyntheticResults deletes a check's results recorded before the given time
+func (r *syntheticCheckRepository) PruneSyntheticResults(ctx context.Context, checkID string, before time.Time) error {
+	query := `DELETE FROM synthetic_check_results WHERE check_id = $1 AND checked_at < $2`
+
+	if _, err := r.DB.ExecContext(ctx, query, checkID, before); err != nil {
+		return fmt.Errorf("repository: failed to prune results of synthetic check %s: %w", checkID, err)
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+)
+
+type TeamRepository interface {
+	WithTx(tx *sql.Tx) TeamRepository
+	CreateTeam(ctx context.Context, team *model.Team) (*model.Team, error)
+	GetTeamByID(ctx context.Context, id string) (*model.Team, error)
+	GetTeamBySlug(ctx context.Context, slug string) (*model.Team, error)
+	GetAllTeams(ctx context.Context) ([]*model.Team, error)
+	UpdateTeam(ctx context.Context, team *model.Team) (*model.Team, error)
+	DeleteTeam(ctx context.Context, id string) error
+	TeamInUse(ctx context.Context, id string) (bool, error)
+	AddMember(ctx context.Context, teamID string, userID string) error
+	RemoveMember(ctx context.Context, teamID string, userID string) error
+	GetMembers(ctx context.Context, teamID string) ([]*model.User, error)
+}
+
+type teamRepository struct {
+	DB DBTX
+}
+
+func NewTeamRepository(db *sql.DB) TeamRepository {
+	return &teamRepository{DB: db}
+}
+
+// WithTx returns a copy of the repository whose queries run inside tx
+func (r *teamRepository) WithTx(tx *sql.Tx) TeamRepository {
+	return &teamRepository{DB: tx}
+}
+
+const teamColumns = `id, name, slug, description, created_at, updated_at`
+
+func scanTeam(row rowScanner) (*model.Team, error) {
+	team := &model.Team{}
+	err := row.Scan(
+		&team.ID,
+		&team.Name,
+		&team.Slug,
+		&team.Description,
+		&team.CreatedAt,
+		&team.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+func (r *teamRepository) CreateTeam(ctx context.Context, team *model.Team) (*model.Team, error) {
+	query := `
+		INSERT INTO teams (name, slug, description)
+		VALUES ($1, $2, $3)
+		RETURNING ` + teamColumns
+
+	created, err := scanTeam(r.DB.QueryRowContext(ctx, query, team.Name, team.Slug, team.Description))
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to create team: %w", err)
+	}
+	return created, nil
+}
+
+func (r *teamRepository) GetTeamByID(ctx context.Context, id string) (*model.Team, error) {
+	query := `SELECT ` + teamColumns + ` FROM teams WHERE id = $1`
+
+	return r.getTeam(ctx, query, id)
+}
+
+// GetTeamBySlug finds the team a free-text team name refers to
+func (r *teamRepository) GetTeamBySlug(ctx context.Context, slug string) (*model.Team, error) {
+	query := `SELECT ` + teamColumns + ` FROM teams WHERE slug = $1`
+
+	return r.getTeam(ctx, query, slug)
+}
+
+func (r *teamRepository) getTeam(ctx context.Context, query string, key string) (*model.Team, error) {
+	team, err := scanTeam(r.DB.QueryRowContext(ctx, query, key))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("repository: failed to get team %s: %w", key, err)
+	}
+	return team, nil
+}
+
+func (r *teamRepository) GetAllTeams(ctx context.Context) ([]*model.Team, error) {
+	query := `SELECT ` + teamColumns + ` FROM teams ORDER BY name ASC`
+
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to query teams: %w", err)
+	}
+	defer rows.Close()
+
+	teams := make([]*model.Team, 0)
+	for rows.Next() {
+		team, err := scanTeam(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository: failed to scan team row: %w", err)
+		}
+		teams = append(teams, team)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
+	}
+
+	return teams, nil
+}
+
+// UpdateTeam replaces a team. A new name cascades to the team's incidents.
+func (r *teamRepository) UpdateTeam(ctx context.Context, team *model.Team) (*model.Team, error) {
+	query := `
+		UPDATE teams
+		SET name = $2, slug = $3, description = $4, updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + teamColumns
+
+	updated, err := scanTeam(r.DB.QueryRowContext(ctx, query, team.ID, team.Name, team.Slug, team.Description))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("repository: failed to update team %s: %w", team.ID, err)
+	}
+	return updated, nil
+}
+
+func (r *teamRepository) DeleteTeam(ctx context.Context, id string) error {
+	query := `DELETE FROM teams WHERE id = $1`
+
+	res, err := r.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("repository: failed to delete team %s: %w", id, err)
+	}
+	return checkDeleted(res)
+}
+
+// TeamInUse reports whether any incident, heartbeat, synthetic check,
+// escalation policy, routing rule or schedule belongs to the team
+func (r *teamRepository) TeamInUse(ctx context.Context, id string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM incidents i JOIN teams t ON t.name = i.team WHERE t.id = $1
+		) OR EXISTS (
+			SELECT 1 FROM heartbeats h JOIN teams t ON t.name = h.team WHERE t.id = $1
+		) OR EXISTS (
+			SELECT 1 FROM synthetic_checks c JOIN teams t ON t.name = c.team WHERE t.id = $1
+		) OR EXISTS (
+			SELECT 1 FROM escalation_policies p JOIN teams t ON t.name = p.team WHERE t.id = $1
+		) OR EXISTS (
+			SELECT 1 FROM routing_rules r JOIN teams t ON t.name = r.team WHERE t.id = $1
+		) OR EXISTS (
+			SELECT 1 FROM schedules s JOIN teams t ON t.name = s.team WHERE t.id = $1
+		)`
+
+	var exists bool
+	if err := r.DB.QueryRowContext(ctx, query, id).Scan(&exists); err != nil {
+		return false, fmt.Errorf("repository: failed to check whether team %s is in use: %w", id, err)
+	}
+	return exists, nil
+}
+
+// AddMember adds a user to a team; adding an existing member is a no-op
+func (r *teamRepository) AddMember(ctx context.Context, teamID string, userID string) error {
+	query := `
+		INSERT INTO team_members (team_id, user_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING`
+
+	if _, err := r.DB.ExecContext(ctx, query, teamID, userID); err != nil {
+		return fmt.Errorf("repository: failed to add user %s to team %s: %w", userID, teamID, err)
+	}
+	return nil
+}
+
+func (r *teamRepository) RemoveMember(ctx context.Context, teamID string, userID string) error {
+	query := `DELETE FROM team_members WHERE team_id = $1 AND user_id = $2`
+
+	res, err := r.DB.ExecContext(ctx, query, teamID, userID)
+	if err != nil {
+		return fmt.Errorf("repository: failed to remove user %s from team %s: %w", userID, teamID, err)
+	}
+	return checkDeleted(res)
+}
+
+func (r *teamRepository) GetMembers(ctx context.Context, teamID string) ([]*model.User, error) {
+	query := `
+		SELECT ` + prefixedUserColumns + `
+		FROM users u
+		JOIN team_members m ON m.user_id = u.id
+		WHERE m.team_id = $1
+		ORDER BY u.name ASC`
+
+	rows, err := r.DB.QueryContext(ctx, query, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to query team members: %w", err)
+	}
+	defer rows.Close()
+
+	return scanUsers(rows)
+}
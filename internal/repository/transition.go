@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+)
+
+type TransitionRepository interface {
+	WithTx(tx *sql.Tx) TransitionRepository
+	CreateTransition(ctx context.Context, transition *model.StatusTransition) error
+	GetTransitionsByIncidentID(ctx context.Context, incidentID string) ([]*model.StatusTransition, error)
+}
+
+type transitionRepository struct {
+	DB DBTX
+}
+
+func NewTransitionRepository(db *sql.DB) TransitionRepository {
+	return &transitionRepository{DB: db}
+}
+
+// WithTx returns a copy of the repository whose queries run inside tx
+func (r *transitionRepository) WithTx(tx *sql.Tx) TransitionRepository {
+	return &transitionRepository{DB: tx}
+}
+
+func (r *transitionRepository) CreateTransition(ctx context.Context, transition *model.StatusTransition) error {
+	query := `
+		INSERT INTO incident_status_transitions (incident_id, from_status, to_status, actor, reason)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+
+	err := r.DB.QueryRowContext(
+		ctx,
+		query,
+		transition.IncidentID,
+		transition.FromStatus,
+		transition.ToStatus,
+		transition.Actor,
+		transition.Reason,
+	).Scan(&transition.ID, &transition.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("repository: failed to record status transition: %w", err)
+	}
+	return nil
+}
+
+func (r *transitionRepository) GetTransitionsByIncidentID(ctx context.Context, incidentID string) ([]*model.StatusTransition, error) {
+	query := `
+		SELECT id, incident_id, from_status, to_status, actor, reason, created_at
+		FROM incident_status_transitions
+		WHERE incident_id = $1
+		ORDER BY created_at ASC`
+
+	rows, err := r.DB.QueryContext(ctx, query, incidentID)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to query status transitions: %w", err)
+	}
+	defer rows.Close()
+
+	transitions := make([]*model.StatusTransition, 0)
+	for rows.Next() {
+		t := &model.StatusTransition{}
+		if err := rows.Scan(&t.ID, &t.IncidentID, &t.FromStatus, &t.ToStatus, &t.Actor, &t.Reason, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("repository: failed to scan status transition row: %w", err)
+		}
+		transitions = append(transitions, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
+	}
+
+	return transitions, nil
+}
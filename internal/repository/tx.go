@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// DBTX is the subset of *sql.DB and *sql.Tx used by the repositories, so the
+// same queries can run on their own or as part of a larger transaction.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Transactor runs a unit of work inside a single database transaction.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(tx *sql.Tx) error) error
+}
+
+type transactor struct {
+	DB *sql.DB
+}
+
+func NewTransactor(db *sql.DB) Transactor {
+	return &transactor{DB: db}
+}
+
+// WithinTx commits when fn returns nil and rolls back otherwise.
+func (t *transactor) WithinTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := t.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repository: failed to begin transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("repository: rollback failed (%v) after: %w", rbErr, err)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repository: failed to commit transaction: %w", err)
+	}
+	return nil
+}
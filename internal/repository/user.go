@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+)
+
+type UserRepository interface {
+	WithTx(tx *sql.Tx) UserRepository
+	CreateUser(ctx context.Context, user *model.User) (*model.User, error)
+	GetUserByID(ctx context.Context, id string) (*model.User, error)
+	GetUserByName(ctx context.Context, name string) (*model.User, error)
+	GetAllUsers(ctx context.Context) ([]*model.User, error)
+	UpdateUser(ctx context.Context, user *model.User) (*model.User, error)
+	DeleteUser(ctx context.Context, id string) error
+}
+
+type userRepository struct {
+	DB DBTX
+}
+
+func NewUserRepository(db *sql.DB) UserRepository {
+	return &userRepository{DB: db}
+}
+
+// WithTx returns a copy of the repository whose queries run inside tx
+func (r *userRepository) WithTx(tx *sql.Tx) UserRepository {
+	return &userRepository{DB: tx}
+}
+
+const userColumns = `id, name, email, contact_methods, created_at, updated_at`
+
+// prefixedUserColumns is userColumns for queries that join users as u
+const prefixedUserColumns = `u.id, u.name, u.email, u.contact_methods, u.created_at, u.updated_at`
+
+func scanUser(row rowScanner) (*model.User, error) {
+	user := &model.User{}
+	var contactMethods []byte
+	err := row.Scan(
+		&user.ID,
+		&user.Name,
+		&user.Email,
+		&contactMethods,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(contactMethods, &user.ContactMethods); err != nil {
+		return nil, fmt.Errorf("failed to decode user contact methods: %w", err)
+	}
+	return user, nil
+}
+
+func scanUsers(rows *sql.Rows) ([]*model.User, error) {
+	users := make([]*model.User, 0)
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository: failed to scan user row: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
+	}
+
+	return users, nil
+}
+
+func (r *userRepository) CreateUser(ctx context.Context, user *model.User) (*model.User, error) {
+	contactMethods, err := json.Marshal(nonNil(user.ContactMethods))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal user contact methods: %w", err)
+	}
+
+	query := `
+		INSERT INTO users (name, email, contact_methods)
+		VALUES ($1, $2, $3)
+		RETURNING ` + userColumns
+
+	created, err := scanUser(r.DB.QueryRowContext(ctx, query, user.Name, user.Email, contactMethods))
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to create user: %w", err)
+	}
+	return created, nil
+}
+
+func (r *userRepository) GetUserByID(ctx context.Context, id string) (*model.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+
+	return r.getUser(ctx, query, id)
+}
+
+// GetUserByName looks a user up by handle, ignoring case
+func (r *userRepository) GetUserByName(ctx context.Context, name string) (*model.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE lower(name) = lower($1)`
+
+	return r.getUser(ctx, query, name)
+}
+
+func (r *userRepository) getUser(ctx context.Context, query string, key string) (*model.User, error) {
+	user, err := scanUser(r.DB.QueryRowContext(ctx, query, key))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("repository: failed to get user %s: %w", key, err)
+	}
+	return user, nil
+}
+
+func (r *userRepository) GetAllUsers(ctx context.Context) ([]*model.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users ORDER BY name ASC`
+
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	return scanUsers(rows)
+}
+
+func (r *userRepository) UpdateUser(ctx context.Context, user *model.User) (*model.User, error) {
+	contactMethods, err := json.Marshal(nonNil(user.ContactMethods))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal user contact methods: %w", err)
+	}
+
+	query := `
+		UPDATE users
+		SET name = $2, email = $3, contact_methods = $4, updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + userColumns
+
+	updated, err := scanUser(r.DB.QueryRowContext(ctx, query, user.ID, user.Name, user.Email, contactMethods))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("repository: failed to update user %s: %w", user.ID, err)
+	}
+	return updated, nil
+}
+
+func (r *userRepository) DeleteUser(ctx context.Context, id string) error {
+	query := `DELETE FROM users WHERE id = $1`
+
+	res, err := r.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("repository: failed to delete user %s: %w", id, err)
+	}
+	return checkDeleted(res)
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+)
+
+type WebhookRepository interface {
+	WithTx(tx *sql.Tx) WebhookRepository
+	CreateWebhook(ctx context.Context, webhook *model.WebhookSubscription) (*model.WebhookSubscription, error)
+	GetWebhookByID(ctx context.Context, id string) (*model.WebhookSubscription, error)
+	GetAllWebhooks(ctx context.Context) ([]*model.WebhookSubscription, error)
+	GetActiveWebhooksForEvent(ctx context.Context, eventType string) ([]*model.WebhookSubscription, error)
+	DeleteWebhook(ctx context.Context, id string) error
+}
+
+type webhookRepository struct {
+	DB DBTX
+}
+
+func NewWebhookRepository(db *sql.DB) WebhookRepository {
+	return &webhookRepository{DB: db}
+}
+
+// WithTx returns a copy of the repository whose queries run inside tx
+func (r *webhookRepository) WithTx(tx *sql.Tx) WebhookRepository {
+	return &webhookRepository{DB: tx}
+}
+
+const webhookColumns = `id, url, secret, events, active, created_at, updated_at`
+
+func scanWebhook(row rowScanner) (*model.WebhookSubscription, error) {
+	webhook := &model.WebhookSubscription{}
+	var events []byte
+	err := row.Scan(
+		&webhook.ID,
+		&webhook.URL,
+		&webhook.Secret,
+		&events,
+		&webhook.Active,
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(events, &webhook.Events); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook events: %w", err)
+	}
+	return webhook, nil
+}
+
+func (r *webhookRepository) CreateWebhook(ctx context.Context, webhook *model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	events := webhook.Events
+	if events == nil {
+		events = []string{}
+	}
+	encoded, err := json.Marshal(events)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal webhook events: %w", err)
+	}
+
+	query := `
+		INSERT INTO webhook_subscriptions (url, secret, events)
+		VALUES ($1, $2, $3)
+		RETURNING ` + webhookColumns
+
+	created, err := scanWebhook(r.DB.QueryRowContext(ctx, query, webhook.URL, webhook.Secret, encoded))
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to create webhook subscription: %w", err)
+	}
+	return created, nil
+}
+
+func (r *webhookRepository) GetWebhookByID(ctx context.Context, id string) (*model.WebhookSubscription, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhook_subscriptions WHERE id = $1`
+
+	webhook, err := scanWebhook(r.DB.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("repository: failed to get webhook subscription %s: %w", id, err)
+	}
+	return webhook, nil
+}
+
+func (r *webhookRepository) GetAllWebhooks(ctx context.Context) ([]*model.WebhookSubscription, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhook_subscriptions ORDER BY created_at DESC`
+
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to query webhook subscriptions: %w", err)
+	}
+	return scanWebhooks(rows)
+}
+
+// GetActiveWebhooksForEvent returns the subscriptions that should receive eventType
+func (r *webhookRepository) GetActiveWebhooksForEvent(ctx context.Context, eventType string) ([]*model.WebhookSubscription, error) {
+	query := `
+		SELECT ` + webhookColumns + `
+		FROM webhook_subscriptions
+		WHERE active AND (jsonb_array_length(events) = 0 OR events ? $1)
+		ORDER BY created_at ASC`
+
+	rows, err := r.DB.QueryContext(ctx, query, eventType)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to query webhook subscriptions: %w", err)
+	}
+	return scanWebhooks(rows)
+}
+
+func (r *webhookRepository) DeleteWebhook(ctx context.Context, id string) error {
+	query := `DELETE FROM webhook_subscriptions WHERE id = $1`
+
+	res, err := r.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("repository: failed to delete webhook subscription %s: %w", id, err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("repository: failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func scanWebhooks(rows *sql.Rows) ([]*model.WebhookSubscription, error) {
+	defer rows.Close()
+
+	webhooks := make([]*model.WebhookSubscription, 0)
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository: failed to scan webhook subscription row: %w", err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
+	}
+
+	return webhooks, nil
+}
@@ -0,0 +1,468 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+	"github.com/hascho/go-incident-dashboard-api/internal/repository"
+	"github.com/rs/zerolog"
+)
+
+// ErrInvalidAlert is returned when an alert notification cannot be turned into an incident.
+var ErrInvalidAlert = errors.New("invalid alert notification")
+
+const (
+	// alertActor is recorded as the actor of incidents opened and resolved by Alertmanager
+	alertActor = "alertmanager"
+	// defaultAlertSeverity applies when neither a rule nor the alert names a severity
+	defaultAlertSeverity = "high"
+	// maxPayloadDescription caps how much of an unmapped event payload becomes the description
+	maxPayloadDescription = 4000
+)
+
+type AlertService interface {
+	IngestAlertmanager(ctx context.Context, payload model.AlertmanagerPayload) (*model.AlertIngestResult, error)
+	IngestEvent(ctx context.Context, integrationID string, token string, payload []byte) (*model.AlertIngestResult, error)
+}
+
+type alertRule struct {
+	Matchers map[string]*regexp.Regexp
+	Team     string
+	Severity string
+}
+
+type alertService struct {
+	Repo            repository.AlertRepository
+	IncidentRepo    repository.IncidentRepository
+	IntegrationRepo repository.IntegrationRepository
+	Incidents       incidentWriter
+	Tx              repository.Transactor
+	Rules           []alertRule
+	DefaultTeam     string // team for alerts no rule or team label places
+	Logger          zerolog.Logger
+}
+
+// NewAlertService builds the Alertmanager ingestion service. rules must have
+// been checked by ParseAlertRules.
+func NewAlertService(repo repository.AlertRepository, incidentRepo repository.IncidentRepository, integrationRepo repository.IntegrationRepository, incidents incidentWriter, tx repository.Transactor, rules []model.AlertRule, defaultTeam string, logger zerolog.Logger) AlertService {
+	compiled := make([]alertRule, 0, len(rules))
+	for _, rule := range rules {
+		matchers := make(map[string]*regexp.Regexp, len(rule.Matchers))
+		for label, pattern := range rule.Matchers {
+			matchers[label] = regexp.MustCompile(anchorPattern(pattern))
+		}
+		compiled = append(compiled, alertRule{Matchers: matchers, Team: rule.Team, Severity: rule.Severity})
+	}
+
+	return &alertService{
+		Repo:            repo,
+		IncidentRepo:    incidentRepo,
+		IntegrationRepo: integrationRepo,
+		Incidents:       incidents,
+		Tx:              tx,
+		Rules:           compiled,
+		DefaultTeam:     defaultTeam,
+		Logger:          logger,
+	}
+}
+
+// ParseAlertRules decodes a JSON array of alert rules and checks that every
+// matcher is a valid regular expression.
+func ParseAlertRules(raw string) ([]model.AlertRule, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	var rules []model.AlertRule
+	if err := json.Unmarshal([]byte(raw), &rules); err != nil {
+		return nil, fmt.Errorf("failed to decode alert rules: %w", err)
+	}
+	for i, rule := range rules {
+		if len(rule.Matchers) == 0 {
+			return nil, fmt.Errorf("alert rule %d has no matchers", i)
+		}
+		for label, pattern := range rule.Matchers {
+			if _, err := regexp.Compile(anchorPattern(pattern)); err != nil {
+				return nil, fmt.Errorf("alert rule %d: label %s: %w", i, label, err)
+			}
+		}
+	}
+	return rules, nil
+}
+
+func anchorPattern(pattern string) string {
+	return "^(?:" + pattern + ")$"
+}
+
+// IngestAlertmanager turns one alert group notification into incident
+// changes. Each group is identified by its groupKey.
+func (s *alertService) IngestAlertmanager(ctx context.Context, payload model.AlertmanagerPayload) (*model.AlertIngestResult, error) {
+	labels := alertLabels(payload)
+	return s.ingest(ctx, alertNotification{
+		Source:      model.AlertSourceAlertmanager,
+		SourceName:  "Alertmanager",
+		Fingerprint: alertFingerprint(payload.GroupKey),
+		Status:      payload.Status,
+		Labels:      labels,
+		Actor:       alertActor,
+		NewIncident: func() (*model.Incident, error) {
+			return s.alertmanagerIncident(payload, labels)
+		},
+	})
+}
+
+// IngestEvent maps an event posted to an integration onto an incident. Events
+// with the same dedup key are one alert: the resolve condition decides whether
+// the event fires or resolves it.
+func (s *alertService) IngestEvent(ctx context.Context, integrationID string, token string, payload []byte) (*model.AlertIngestResult, error) {
+	integration, err := s.IntegrationRepo.GetIntegrationByID(ctx, integrationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkIntegrationToken(integration, token); err != nil {
+		return nil, err
+	}
+	mapping, err := compileMapping(integration.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: payload is not valid JSON: %v", ErrInvalidAlert, err)
+	}
+
+	title := mapping.Title.value(doc)
+	dedupKey := mapping.DedupKey.value(doc)
+	if dedupKey == "" {
+		dedupKey = title
+	}
+	if dedupKey == "" {
+		return nil, fmt.Errorf("%w: payload has no title or dedup key at the mapped paths", ErrInvalidAlert)
+	}
+
+	status := model.AlertFiring
+	if mapping.Resolve != nil {
+		value, _ := mapping.Resolve.Path.lookup(doc)
+		text := jsonText(value)
+		if slices.ContainsFunc(mapping.Resolve.Values, func(v string) bool { return strings.EqualFold(v, text) }) {
+			status = model.AlertResolved
+		}
+	}
+
+	return s.ingest(ctx, alertNotification{
+		Source:      model.AlertSourceIntegration + ":" + integration.ID,
+		SourceName:  integration.Name,
+		Fingerprint: alertFingerprint(dedupKey),
+		Status:      status,
+		Labels:      map[string]string{"title": title, "dedup_key": dedupKey},
+		Actor:       integration.Name,
+		NewIncident: func() (*model.Incident, error) {
+			return eventIncident(integration, mapping, doc, payload, title, mapping.DedupKey.value(doc))
+		},
+	})
+}
+
+// alertNotification is a firing or resolved notification about one alert,
+// whatever system it came from.
+type alertNotification struct {
+	Source      string // kept with the fingerprint in external_alerts
+	SourceName  string // how the source reads in timeline entries
+	Fingerprint string // identifies the alert within its source
+	Status      string // model.AlertFiring or model.AlertResolved
+	Labels      map[string]string
+	Actor       string
+	// NewIncident builds the incident to open when the alert fires and has
+	// none. It is only called then, so it may fail on data only needed then.
+	NewIncident func() (*model.Incident, error)
+}
+
+// ingest applies a notification to the alert's incident. The first firing
+// notification opens it, a resolved notification resolves it, and an alert
+// that fires again reopens it, or opens a new incident once the old one has
+// been closed. An alert whose incident would duplicate an active one is
+// grouped into it, and that incident resolves once none of its alerts is
+// still firing. Sources repeat notifications, so anything that would not
+// change the incident is acknowledged and dropped.
+func (s *alertService) ingest(ctx context.Context, n alertNotification) (*model.AlertIngestResult, error) {
+	result := &model.AlertIngestResult{Action: model.AlertActionUnchanged}
+	var jobs []*repository.Job
+
+	err := s.Tx.WithinTx(ctx, func(tx *sql.Tx) error {
+		repo := s.Repo.WithTx(tx)
+		if err := repo.LockFingerprint(ctx, n.Source, n.Fingerprint); err != nil {
+			return err
+		}
+
+		existing, err := repo.GetExternalAlert(ctx, n.Source, n.Fingerprint)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		var incident *model.Incident
+		if existing != nil {
+			incident, err = s.IncidentRepo.WithTx(tx).GetIncidentByIDForUpdate(ctx, existing.IncidentID)
+			if err != nil {
+				return err
+			}
+		}
+
+		if n.Status == model.AlertResolved && incident == nil {
+			// we never saw this alert fire, so there is nothing to resolve
+			result.Action = model.AlertActionIgnored
+			return nil
+		}
+
+		switch {
+		case n.Status == model.AlertResolved:
+			result.IncidentID = incident.ID
+
+		case incident == nil || incident.Status == model.StatusClosed:
+			opened, err := n.NewIncident()
+			if err != nil {
+				return err
+			}
+			created, createJobs, err := s.Incidents.createIncident(ctx, tx, n.Actor, opened)
+			if err != nil {
+				return err
+			}
+			jobs = createJobs
+			result.Action = model.AlertActionCreated
+			if created.OccurrenceCount > 1 {
+				result.Action = model.AlertActionGrouped
+			}
+			result.IncidentID = created.ID
+
+		case incident.Status == model.StatusResolved:
+			status, reason := model.StatusOpen, "Firing again in "+n.SourceName
+			if _, jobs, err = s.Incidents.updateIncident(ctx, tx, incident.ID, n.Actor, model.UpdateIncidentRequest{Status: &status, Reason: &reason}); err != nil {
+				return err
+			}
+			result.Action = model.AlertActionReopened
+			result.IncidentID = incident.ID
+
+		default:
+			result.IncidentID = incident.ID
+		}
+
+		if existing == nil {
+			_, err = repo.CreateExternalAlert(ctx, &model.ExternalAlert{
+				Source:      n.Source,
+				Fingerprint: n.Fingerprint,
+				IncidentID:  result.IncidentID,
+				Status:      n.Status,
+				Labels:      n.Labels,
+			})
+		} else {
+			existing.IncidentID = result.IncidentID
+			existing.Status = n.Status
+			existing.Labels = n.Labels
+			err = repo.UpdateExternalAlert(ctx, existing)
+		}
+		if err != nil {
+			return err
+		}
+
+		if n.Status != model.AlertResolved || validateTransition(incident.Status, model.StatusResolved) != nil {
+			return nil
+		}
+		// other alerts may have been grouped into the same incident; it
+		// resolves with the last of them
+		firing, err := repo.CountFiringAlerts(ctx, incident.ID)
+		if err != nil || firing > 0 {
+			return err
+		}
+		status, reason := model.StatusResolved, "Resolved in "+n.SourceName
+		if _, jobs, err = s.Incidents.updateIncident(ctx, tx, incident.ID, n.Actor, model.UpdateIncidentRequest{Status: &status, Reason: &reason}); err != nil {
+			return err
+		}
+		result.Action = model.AlertActionResolved
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.Incidents.publish(ctx, jobs)
+
+	s.Logger.Info().
+		Str("source", n.Source).
+		Str("fingerprint", n.Fingerprint).
+		Str("status", n.Status).
+		Str("action", result.Action).
+		Str("incident_id", result.IncidentID).
+		Msg("Alert notification ingested")
+	return result, nil
+}
+
+// alertmanagerIncident builds the incident for a firing alert group, placing
+// it with the first rule that matches the group's labels.
+func (s *alertService) alertmanagerIncident(payload model.AlertmanagerPayload, labels map[string]string) (*model.Incident, error) {
+	team, severity := s.classify(labels)
+	if team == "" {
+		return nil, fmt.Errorf("%w: no rule or team label places the alert and no default team is configured", ErrInvalidAlert)
+	}
+
+	tags := []string{model.AlertSourceAlertmanager}
+	if name := labels["alertname"]; name != "" {
+		tags = append(tags, name)
+	}
+
+	return &model.Incident{
+		Title:       alertTitle(payload, labels),
+		Description: alertDescription(payload),
+		Status:      model.StatusOpen,
+		Severity:    severity,
+		Team:        team,
+		Tags:        tags,
+	}, nil
+}
+
+// eventIncident builds the incident for a firing integration event. A dedup
+// key from the payload becomes the incident's fingerprint, so events from
+// different sources that share a key group into one incident.
+func eventIncident(integration *model.Integration, mapping *compiledMapping, doc any, payload []byte, title string, dedupKey string) (*model.Incident, error) {
+	if title == "" {
+		return nil, fmt.Errorf("%w: payload has no title at the mapped path", ErrInvalidAlert)
+	}
+	team := mapping.Team.value(doc)
+	if team == "" {
+		return nil, fmt.Errorf("%w: payload has no team at the mapped path", ErrInvalidAlert)
+	}
+
+	severity := mapping.Severity.value(doc)
+	if mapped, ok := mapping.SeverityMap[strings.ToLower(severity)]; ok {
+		severity = mapped
+	}
+	if severity == "" {
+		severity = defaultAlertSeverity
+	}
+
+	description := mapping.Description.value(doc)
+	if description == "" {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, payload, "", "  "); err == nil {
+			description = indented.String()
+		}
+		if len(description) > maxPayloadDescription {
+			description = strings.ToValidUTF8(description[:maxPayloadDescription], "") + "\n…"
+		}
+	}
+
+	return &model.Incident{
+		Title:       title,
+		Description: description,
+		Status:      model.StatusOpen,
+		Severity:    severity,
+		Team:        team,
+		Tags:        []string{model.AlertSourceIntegration, integration.Name},
+		Fingerprint: dedupKey,
+	}, nil
+}
+
+// classify picks the team and severity for an alert. A matching rule wins;
+// whatever it leaves empty comes from the alert's team and severity labels.
+func (s *alertService) classify(labels map[string]string) (string, string) {
+	team, severity := labels["team"], labels["severity"]
+	for _, rule := range s.Rules {
+		if !rule.matches(labels) {
+			continue
+		}
+		if rule.Team != "" {
+			team = rule.Team
+		}
+		if rule.Severity != "" {
+			severity = rule.Severity
+		}
+		break
+	}
+
+	if team == "" {
+		team = s.DefaultTeam
+	}
+	if severity == "" {
+		severity = defaultAlertSeverity
+	}
+	return team, severity
+}
+
+func (r alertRule) matches(labels map[string]string) bool {
+	for label, pattern := range r.Matchers {
+		if !pattern.MatchString(labels[label]) {
+			return false
+		}
+	}
+	return true
+}
+
+// alertFingerprint identifies an alert group. The groupKey is stable for the
+// life of the group but can be long, so it is hashed.
+func alertFingerprint(groupKey string) string {
+	sum := sha256.Sum256([]byte(groupKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// alertLabels are the labels the whole group shares, falling back to the
+// grouping labels for payloads that leave commonLabels out.
+func alertLabels(payload model.AlertmanagerPayload) map[string]string {
+	labels := make(map[string]string, len(payload.CommonLabels)+len(payload.GroupLabels))
+	maps.Copy(labels, payload.GroupLabels)
+	maps.Copy(labels, payload.CommonLabels)
+	return labels
+}
+
+func alertTitle(payload model.AlertmanagerPayload, labels map[string]string) string {
+	if summary := strings.TrimSpace(payload.CommonAnnotations["summary"]); summary != "" {
+		return summary
+	}
+	if name := labels["alertname"]; name != "" {
+		return name
+	}
+	return "Alertmanager alert"
+}
+
+// alertDescription lists the group's description and each alert in it, with
+// links back to the source.
+func alertDescription(payload model.AlertmanagerPayload) string {
+	var b strings.Builder
+	if description := strings.TrimSpace(payload.CommonAnnotations["description"]); description != "" {
+		b.WriteString(description)
+		b.WriteString("\n\n")
+	}
+
+	fmt.Fprintf(&b, "%d alert(s) firing", len(payload.Alerts))
+	if payload.TruncatedAlerts > 0 {
+		fmt.Fprintf(&b, " (%d more truncated)", payload.TruncatedAlerts)
+	}
+	b.WriteString(":\n")
+	for _, alert := range payload.Alerts {
+		keys := slices.Sorted(maps.Keys(alert.Labels))
+		pairs := make([]string, 0, len(keys))
+		for _, key := range keys {
+			pairs = append(pairs, key+"="+alert.Labels[key])
+		}
+		fmt.Fprintf(&b, "- {%s}", strings.Join(pairs, ", "))
+		if alert.GeneratorURL != "" {
+			fmt.Fprintf(&b, " %s", alert.GeneratorURL)
+		}
+		b.WriteString("\n")
+	}
+
+	if payload.ExternalURL != "" {
+		fmt.Fprintf(&b, "\nAlertmanager: %s", payload.ExternalURL)
+	}
+	return strings.TrimSpace(b.String())
+}
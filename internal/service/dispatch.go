@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+	"github.com/hascho/go-incident-dashboard-api/internal/notifier"
+	"github.com/hascho/go-incident-dashboard-api/internal/queue"
+	"github.com/hascho/go-incident-dashboard-api/internal/repository"
+	"github.com/rs/zerolog"
+)
+
+// enqueueEvent writes the jobs announcing an incident event as part of tx: one
+// notification per target of every matching routing rule, plus one delivery per
+// webhook subscribed to the event. The jobs are returned so the caller can
+// signal the worker once the transaction has committed.
+func (s *incidentService) enqueueEvent(ctx context.Context, tx *sql.Tx, eventType string, incident *model.Incident, changes []model.FieldChange) ([]*repository.Job, error) {
+	targets, err := s.routeEvent(ctx, tx, eventType, incident)
+	if err != nil {
+		return nil, err
+	}
+	specs := notificationSpecs(eventType, incident, changes, targets)
+
+	webhooks, err := webhookSpecs(ctx, s.WebhookRepo.WithTx(tx), eventType, incident, changes)
+	if err != nil {
+		return nil, err
+	}
+	specs = append(specs, webhooks...)
+
+	return createJobs(ctx, s.JobRepo.WithTx(tx), specs)
+}
+
+// routeEvent resolves the notification targets for an event from the routing
+// rules. Targets shared by several rules are notified once. An event that no
+// rule claims still goes to the default target, so nothing happens silently.
+func (s *incidentService) routeEvent(ctx context.Context, tx *sql.Tx, eventType string, incident *model.Incident) ([]repository.JobTarget, error) {
+	rules, err := s.RoutingRepo.WithTx(tx).GetEnabledRoutingRulesForEvent(ctx, eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []repository.JobTarget
+	for _, rule := range rules {
+		if !routingRuleMatches(rule, incident) {
+			continue
+		}
+		targets, err = resolveTargets(ctx, s.ScheduleRepo.WithTx(tx), s.Logger, targets, rule.Targets)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(targets) == 0 {
+		targets = append(targets, s.DefaultTarget)
+	}
+	return targets, nil
+}
+
+// publish signals the worker that committed jobs are ready
+func (s *incidentService) publish(ctx context.Context, jobs []*repository.Job) {
+	publishJobs(ctx, s.Queue, s.Logger, jobs)
+}
+
+// resolveTargets appends the job targets for add to targets, skipping ones
+// already present. A schedule target resolves to whoever is on call for it
+// now; a schedule that cannot be resolved is logged and skipped rather than
+// failing the event.
+func resolveTargets(ctx context.Context, scheduleRepo repository.ScheduleRepository, logger zerolog.Logger, targets []repository.JobTarget, add []model.RoutingTarget) ([]repository.JobTarget, error) {
+	for _, t := range add {
+		target := repository.JobTarget{Channel: t.Channel, Destination: t.Destination}
+
+		if t.Channel == TargetSchedule {
+			onCall, err := onCallAt(ctx, scheduleRepo, t.Destination, time.Now())
+			if errors.Is(err, sql.ErrNoRows) || errors.Is(err, ErrNoOnCall) {
+				logger.Warn().Err(err).Str("schedule_id", t.Destination).Msg("Schedule target has no one on call; skipping")
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			target = repository.JobTarget{Channel: onCall.Responder.Channel, Destination: onCall.Responder.Destination}
+		}
+
+		if !slices.Contains(targets, target) {
+			targets = append(targets, target)
+		}
+	}
+	return targets, nil
+}
+
+// notificationSpecs describes one notification job per target
+func notificationSpecs(eventType string, incident *model.Incident, changes []model.FieldChange, targets []repository.JobTarget) []repository.JobSpec {
+	specs := make([]repository.JobSpec, 0, len(targets))
+	for _, target := range targets {
+		specs = append(specs, repository.JobSpec{
+			Type:      repository.JobTypeNotification,
+			EventType: eventType,
+			Incident:  incident,
+			Changes:   changes,
+			Target:    target,
+		})
+	}
+	return specs
+}
+
+// webhookSpecs describes one delivery per webhook subscribed to eventType
+func webhookSpecs(ctx context.Context, webhookRepo repository.WebhookRepository, eventType string, incident *model.Incident, changes []model.FieldChange) ([]repository.JobSpec, error) {
+	webhooks, err := webhookRepo.GetActiveWebhooksForEvent(ctx, eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	specs := make([]repository.JobSpec, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		specs = append(specs, repository.JobSpec{
+			Type:      repository.JobTypeWebhook,
+			EventType: eventType,
+			Incident:  incident,
+			Changes:   changes,
+			Target:    repository.JobTarget{Channel: notifier.ChannelWebhook, Destination: webhook.ID},
+		})
+	}
+	return specs, nil
+}
+
+func createJobs(ctx context.Context, jobRepo repository.JobRepository, specs []repository.JobSpec) ([]*repository.Job, error) {
+	jobs := make([]*repository.Job, 0, len(specs))
+	for _, spec := range specs {
+		job, err := jobRepo.CreateJob(ctx, spec)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// publishJobs signals the worker that committed jobs are ready. Polling is the
+// safety net, so a failed publish is only logged.
+func publishJobs(ctx context.Context, q queue.TaskQueue, logger zerolog.Logger, jobs []*repository.Job) {
+	for _, job := range jobs {
+		if err := q.Publish(ctx, job.ID.String()); err != nil {
+			// if Redis is down, it's okay! polling will catch it.
+			logger.Warn().Err(err).Msg("Redis publish failed - worker will catch up via polling")
+			return
+		}
+		logger.Info().Str("job_id", job.ID.String()).Str("incident_id", job.IncidentID.String()).Msg("Published job to Redis")
+	}
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+	"github.com/hascho/go-incident-dashboard-api/internal/queue"
+	"github.com/hascho/go-incident-dashboard-api/internal/repository"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrInvalidEscalationPolicy  = errors.New("invalid escalation policy")
+	ErrEscalationPolicyConflict = errors.New("team already has an escalation policy")
+)
+
+type EscalationService interface {
+	CreateEscalationPolicy(ctx context.Context, req model.EscalationPolicyRequest) (*model.EscalationPolicy, error)
+	GetEscalationPolicyByID(ctx context.Context, id string) (*model.EscalationPolicy, error)
+	GetAllEscalationPolicies(ctx context.Context) ([]*model.EscalationPolicy, error)
+	UpdateEscalationPolicy(ctx context.Context, id string, req model.EscalationPolicyRequest) (*model.EscalationPolicy, error)
+	DeleteEscalationPolicy(ctx context.Context, id string) error
+	EscalateDue(ctx context.Context, limit int) (int, error)
+}
+
+type escalationService struct {
+	Repo         repository.EscalationRepository
+	IncidentRepo repository.IncidentRepository
+	JobRepo      repository.JobRepository
+	EventRepo    repository.EventRepository
+	WebhookRepo  repository.WebhookRepository
+	ScheduleRepo repository.ScheduleRepository
+	TeamRepo     repository.TeamRepository
+	Tx           repository.Transactor
+	Queue        queue.TaskQueue
+	Logger       zerolog.Logger
+}
+
+func NewEscalationService(repo repository.EscalationRepository, incidentRepo repository.IncidentRepository, jobRepo repository.JobRepository, eventRepo repository.EventRepository, webhookRepo repository.WebhookRepository, scheduleRepo repository.ScheduleRepository, teamRepo repository.TeamRepository, tx repository.Transactor, q queue.TaskQueue, logger zerolog.Logger) EscalationService {
+	return &escalationService{
+		Repo:         repo,
+		IncidentRepo: incidentRepo,
+		JobRepo:      jobRepo,
+		EventRepo:    eventRepo,
+		WebhookRepo:  webhookRepo,
+		ScheduleRepo: scheduleRepo,
+		TeamRepo:     teamRepo,
+		Tx:           tx,
+		Queue:        q,
+		Logger:       logger,
+	}
+}
+
+func (s *escalationService) CreateEscalationPolicy(ctx context.Context, req model.EscalationPolicyRequest) (*model.EscalationPolicy, error) {
+	policy, err := s.escalationPolicyFromRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.Repo.GetEscalationPolicyByTeam(ctx, policy.Team); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrEscalationPolicyConflict, policy.Team)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	created, err := s.Repo.CreateEscalationPolicy(ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Logger.Info().Str("policy_id", created.ID).Str("team", created.Team).Msg("Escalation policy created")
+	return created, nil
+}
+
+func (s *escalationService) GetEscalationPolicyByID(ctx context.Context, id string) (*model.EscalationPolicy, error) {
+	return s.Repo.GetEscalationPolicyByID(ctx, id)
+}
+
+func (s *escalationService) GetAllEscalationPolicies(ctx context.Context) ([]*model.EscalationPolicy, error) {
+	return s.Repo.GetAllEscalationPolicies(ctx)
+}
+
+// UpdateEscalationPolicy replaces every field of the policy with req. Incidents
+// already escalating continue from their current level of the new levels.
+func (s *escalationService) UpdateEscalationPolicy(ctx context.Context, id string, req model.EscalationPolicyRequest) (*model.EscalationPolicy, error) {
+	policy, err := s.escalationPolicyFromRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	policy.ID = id
+
+	existing, err := s.Repo.GetEscalationPolicyByTeam(ctx, policy.Team)
+	if err == nil && existing.ID != id {
+		return nil, fmt.Errorf("%w: %s", ErrEscalationPolicyConflict, policy.Team)
+	} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	updated, err := s.Repo.UpdateEscalationPolicy(ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Logger.Info().Str("policy_id", updated.ID).Str("team", updated.Team).Msg("Escalation policy updated")
+	return updated, nil
+}
+
+func (s *escalationService) DeleteEscalationPolicy(ctx context.Context, id string) error {
+	return s.Repo.DeleteEscalationPolicy(ctx, id)
+}
+
+// EscalateDue pages the next level of up to limit escalations whose delay has
+// passed, and returns how many were processed. Claiming, paging and advancing
+// happen in one transaction, so a level is paged exactly once even with several
+// schedulers running.
+func (s *escalationService) EscalateDue(ctx context.Context, limit int) (int, error) {
+	var processed int
+	var jobs []*repository.Job
+
+	err := s.Tx.WithinTx(ctx, func(tx *sql.Tx) error {
+		due, err := s.Repo.WithTx(tx).ClaimDueEscalations(ctx, limit)
+		if err != nil {
+			return err
+		}
+
+		for _, escalation := range due {
+			levelJobs, err := s.escalate(ctx, tx, escalation)
+			if err != nil {
+				return err
+			}
+			jobs = append(jobs, levelJobs...)
+		}
+		processed = len(due)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	publishJobs(ctx, s.Queue, s.Logger, jobs)
+	return processed, nil
+}
+
+// escalate pages the due level of one escalation and schedules the next, or
+// ends the escalation once the incident is acknowledged or out of levels.
+func (s *escalationService) escalate(ctx context.Context, tx *sql.Tx, escalation *repository.Escalation) ([]*repository.Job, error) {
+	incident, err := s.IncidentRepo.WithTx(tx).GetIncidentByID(ctx, escalation.IncidentID)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := s.Repo.WithTx(tx).GetEscalationPolicyByID(ctx, escalation.PolicyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// acknowledging stops the escalation, this only catches rows left behind
+	// and policies that lost levels since the escalation started
+	if incident.Status != model.StatusOpen || escalation.NextLevel >= len(policy.Levels) {
+		return nil, s.Repo.WithTx(tx).StopEscalation(ctx, incident.ID)
+	}
+
+	level := policy.Levels[escalation.NextLevel]
+	eventType := model.EventTypeIncidentEscalated
+
+	targets, err := resolveTargets(ctx, s.ScheduleRepo.WithTx(tx), s.Logger, nil, level.Targets)
+	if err != nil {
+		return nil, err
+	}
+	specs := notificationSpecs(eventType, incident, nil, targets)
+	webhooks, err := webhookSpecs(ctx, s.WebhookRepo.WithTx(tx), eventType, incident, nil)
+	if err != nil {
+		return nil, err
+	}
+	specs = append(specs, webhooks...)
+
+	jobs, err := createJobs(ctx, s.JobRepo.WithTx(tx), specs)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, _ := json.Marshal(map[string]any{"policy_id": policy.ID, "level": escalation.NextLevel + 1})
+	err = s.EventRepo.WithTx(tx).CreateEvent(ctx, &model.IncidentEvent{
+		IncidentID: incident.ID,
+		Type:       model.EventEscalated,
+		Actor:      "escalation:" + policy.Name,
+		Message:    fmt.Sprintf("Unacknowledged; escalated to level %d of %d", escalation.NextLevel+1, len(policy.Levels)),
+		Metadata:   metadata,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.Logger.Info().
+		Str("incident_id", incident.ID).
+		Str("policy_id", policy.ID).
+		Int("level", escalation.NextLevel+1).
+		Msg("Incident escalated")
+
+	next := escalation.NextLevel + 1
+	if next >= len(policy.Levels) {
+		return jobs, s.Repo.WithTx(tx).StopEscalation(ctx, incident.ID)
+	}
+	return jobs, s.Repo.WithTx(tx).AdvanceEscalation(ctx, incident.ID, next, levelDelay(policy.Levels[next]))
+}
+
+// syncEscalation starts escalating an open incident covered by its team's
+// policy, and stops escalating it otherwise. Starting is a no-op for an
+// incident that is already escalating.
+func syncEscalation(ctx context.Context, repo repository.EscalationRepository, incident *model.Incident) error {
+	if incident.Status != model.StatusOpen {
+		return repo.StopEscalation(ctx, incident.ID)
+	}
+
+	policy, err := repo.GetEscalationPolicyByTeam(ctx, incident.Team)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !escalationPolicyCovers(policy, incident) {
+		return repo.StopEscalation(ctx, incident.ID)
+	}
+	return repo.StartEscalation(ctx, incident.ID, policy.ID, levelDelay(policy.Levels[0]))
+}
+
+func escalationPolicyCovers(policy *model.EscalationPolicy, incident *model.Incident) bool {
+	if len(policy.Levels) == 0 {
+		return false
+	}
+	if len(policy.Severities) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(policy.Severities, func(severity string) bool {
+		return strings.EqualFold(severity, incident.Severity)
+	})
+}
+
+func levelDelay(level model.EscalationLevel) time.Duration {
+	return time.Duration(level.DelayMinutes) * time.Minute
+}
+
+// escalationPolicyFromRequest validates req and resolves its team to the
+// team's canonical name
+func (s *escalationService) escalationPolicyFromRequest(ctx context.Context, req model.EscalationPolicyRequest) (*model.EscalationPolicy, error) {
+	if strings.TrimSpace(req.Team) == "" {
+		return nil, fmt.Errorf("%w: team cannot be empty", ErrInvalidEscalationPolicy)
+	}
+	for i, level := range req.Levels {
+		for _, target := range level.Targets {
+			if err := validateTarget(target); err != nil {
+				return nil, fmt.Errorf("%w: level %d: %v", ErrInvalidEscalationPolicy, i+1, err)
+			}
+		}
+	}
+
+	team, err := resolveTeam(ctx, s.TeamRepo, req.Team)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.EscalationPolicy{
+		Name:       req.Name,
+		Team:       team.Name,
+		Severities: req.Severities,
+		Levels:     req.Levels,
+	}, nil
+}
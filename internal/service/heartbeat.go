@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+	"github.com/hascho/go-incident-dashboard-api/internal/repository"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrInvalidHeartbeat  = errors.New("invalid heartbeat")
+	ErrHeartbeatConflict = errors.New("heartbeat already exists")
+)
+
+const (
+	// heartbeatActor is recorded as the actor of incidents opened and resolved by heartbeats
+	heartbeatActor = "heartbeat"
+	// heartbeatRetryAfter is how long a heartbeat that went down without an
+	// incident waits before another check tries to open one
+	heartbeatRetryAfter = 5 * time.Minute
+)
+
+type HeartbeatService interface {
+	CreateHeartbeat(ctx context.Context, req model.HeartbeatRequest) (*model.Heartbeat, error)
+	GetHeartbeatByID(ctx context.Context, id string) (*model.Heartbeat, error)
+	GetAllHeartbeats(ctx context.Context) ([]*model.Heartbeat, error)
+	UpdateHeartbeat(ctx context.Context, id string, req model.HeartbeatRequest) (*model.Heartbeat, error)
+	DeleteHeartbeat(ctx context.Context, id string) error
+	Ping(ctx context.Context, id string) (*model.Heartbeat, error)
+	CheckOverdue(ctx context.Context, limit int) (int, error)
+}
+
+type heartbeatService struct {
+	Repo      repository.HeartbeatRepository
+	TeamRepo  repository.TeamRepository
+	Incidents incidentWriter
+	Tx        repository.Transactor
+	Logger    zerolog.Logger
+}
+
+func NewHeartbeatService(repo repository.HeartbeatRepository, teamRepo repository.TeamRepository, incidents incidentWriter, tx repository.Transactor, logger zerolog.Logger) HeartbeatService {
+	return &heartbeatService{
+		Repo:      repo,
+		TeamRepo:  teamRepo,
+		Incidents: incidents,
+		Tx:        tx,
+		Logger:    logger,
+	}
+}
+
+func (s *heartbeatService) CreateHeartbeat(ctx context.Context, req model.HeartbeatRequest) (*model.Heartbeat, error) {
+	heartbeat, err := s.heartbeatFromRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.Repo.GetHeartbeatByName(ctx, heartbeat.Name); err == nil {
+		return nil, fmt.Errorf("%w: %q", ErrHeartbeatConflict, heartbeat.Name)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	created, err := s.Repo.CreateHeartbeat(ctx, heartbeat)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Logger.Info().Str("heartbeat_id", created.ID).Str("heartbeat", created.Name).Msg("Heartbeat created")
+	return created, nil
+}
+
+func (s *heartbeatService) GetHeartbeatByID(ctx context.Context, id string) (*model.Heartbeat, error) {
+	return s.Repo.GetHeartbeatByID(ctx, id)
+}
+
+func (s *heartbeatService) GetAllHeartbeats(ctx context.Context) ([]*model.Heartbeat, error) {
+	return s.Repo.GetAllHeartbeats(ctx)
+}
+
+// UpdateHeartbeat replaces the heartbeat's settings. A new interval applies
+// from the last ping.
+func (s *heartbeatService) UpdateHeartbeat(ctx context.Context, id string, req model.HeartbeatRequest) (*model.Heartbeat, error) {
+	heartbeat, err := s.heartbeatFromRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	heartbeat.ID = id
+
+	existing, err := s.Repo.GetHeartbeatByName(ctx, heartbeat.Name)
+	if err == nil && existing.ID != id {
+		return nil, fmt.Errorf("%w: %q", ErrHeartbeatConflict, heartbeat.Name)
+	} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	updated, err := s.Repo.UpdateHeartbeat(ctx, heartbeat)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Logger.Info().Str("heartbeat_id", updated.ID).Str("heartbeat", updated.Name).Msg("Heartbeat updated")
+	return updated, nil
+}
+
+// DeleteHeartbeat removes the heartbeat. An incident it opened stays open.
+func (s *heartbeatService) DeleteHeartbeat(ctx context.Context, id string) error {
+	return s.Repo.DeleteHeartbeat(ctx, id)
+}
+
+// Ping records that the monitored job is alive. The first ping after the
+// heartbeat went down resolves the incident it opened, unless someone has
+// already moved that incident on.
+func (s *heartbeatService) Ping(ctx context.Context, id string) (*model.Heartbeat, error) {
+	var heartbeat *model.Heartbeat
+	var resolved string
+	var jobs []*repository.Job
+	err := s.Tx.WithinTx(ctx, func(tx *sql.Tx) error {
+		repo := s.Repo.WithTx(tx)
+		// lock the row so an overdue check cannot link an incident between
+		// this read and the ping being recorded
+		current, err := repo.GetHeartbeatByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if current.Status == model.HeartbeatDown && current.IncidentID != nil {
+			status, reason := model.StatusResolved, "Heartbeat pings resumed"
+			_, jobs, err = s.Incidents.updateIncident(ctx, tx, *current.IncidentID, heartbeatActor, model.UpdateIncidentRequest{Status: &status, Reason: &reason})
+			if err != nil && !errors.Is(err, ErrInvalidTransition) && !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+			if err == nil {
+				resolved = *current.IncidentID
+			}
+		}
+
+		heartbeat, err = repo.RecordPing(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.Incidents.publish(ctx, jobs)
+
+	if resolved != "" {
+		s.Logger.Info().Str("heartbeat_id", heartbeat.ID).Str("incident_id", resolved).Msg("Heartbeat recovered")
+	}
+	return heartbeat, nil
+}
+
+// CheckOverdue opens an incident for up to limit heartbeats whose ping is
+// overdue and returns how many went down. Claiming marks them down first, so
+// several checkers never open two incidents for one heartbeat. A heartbeat
+// whose incident could not be opened is retried after heartbeatRetryAfter.
+func (s *heartbeatService) CheckOverdue(ctx context.Context, limit int) (int, error) {
+	overdue, err := s.Repo.ClaimOverdueHeartbeats(ctx, limit, heartbeatRetryAfter)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, heartbeat := range overdue {
+		incident, err := s.openIncident(ctx, heartbeat.ID)
+		if err != nil {
+			s.Logger.Error().Err(err).Str("heartbeat_id", heartbeat.ID).Msg("Failed to open incident for missed heartbeat")
+			continue
+		}
+		if incident == nil {
+			s.Logger.Debug().Str("heartbeat_id", heartbeat.ID).Msg("Heartbeat pinged or was deleted before its incident was opened")
+			continue
+		}
+		s.Logger.Warn().Str("heartbeat_id", heartbeat.ID).Str("incident_id", incident.ID).Msg("Heartbeat missed")
+	}
+	return len(overdue), nil
+}
+
+// openIncident opens the incident for a claimed heartbeat and links it in the
+// same transaction, holding the heartbeat's row lock. A ping either lands
+// first, and nothing is opened, or waits and then sees the incident to
+// resolve. It returns nil when the heartbeat no longer needs an incident.
+func (s *heartbeatService) openIncident(ctx context.Context, id string) (*model.Incident, error) {
+	var incident *model.Incident
+	var jobs []*repository.Job
+	err := s.Tx.WithinTx(ctx, func(tx *sql.Tx) error {
+		repo := s.Repo.WithTx(tx)
+		heartbeat, err := repo.GetHeartbeatByIDForUpdate(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if heartbeat.Status != model.HeartbeatDown || heartbeat.IncidentID != nil {
+			return nil
+		}
+
+		incident, jobs, err = s.Incidents.createIncident(ctx, tx, heartbeatActor, heartbeatIncident(heartbeat))
+		if err != nil {
+			return err
+		}
+		return repo.SetHeartbeatIncident(ctx, heartbeat.ID, incident.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.Incidents.publish(ctx, jobs)
+	return incident, nil
+}
+
+// heartbeatIncident is the incident opened when a heartbeat misses its ping.
+// Its fingerprint is the heartbeat's, so with grouping enabled a heartbeat that
+// goes down again while its last incident is active adds an occurrence to it.
+func heartbeatIncident(heartbeat *model.Heartbeat) *model.Incident {
+	since := "it was created at " + heartbeat.CreatedAt.UTC().Format(time.RFC3339)
+	if heartbeat.LastPingAt != nil {
+		since = heartbeat.LastPingAt.UTC().Format(time.RFC3339)
+	}
+
+	return &model.Incident{
+		Title: fmt.Sprintf("Heartbeat %s missed", heartbeat.Name),
+		Description: fmt.Sprintf("No ping received from %s since %s; it is expected every %s (grace %s).",
+			heartbeat.Name, since,
+			time.Duration(heartbeat.IntervalSeconds)*time.Second,
+			time.Duration(heartbeat.GraceSeconds)*time.Second),
+		Status:      model.StatusOpen,
+		Severity:    heartbeat.Severity,
+		Team:        heartbeat.Team,
+		Tags:        []string{heartbeatActor},
+		Fingerprint: "heartbeat:" + heartbeat.ID,
+	}
+}
+
+func (s *heartbeatService) heartbeatFromRequest(ctx context.Context, req model.HeartbeatRequest) (*model.Heartbeat, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name cannot be empty", ErrInvalidHeartbeat)
+	}
+	if req.IntervalSeconds < 1 || req.GraceSeconds < 0 {
+		return nil, fmt.Errorf("%w: interval_seconds must be positive and grace_seconds not negative", ErrInvalidHeartbeat)
+	}
+
+	team, err := resolveTeam(ctx, s.TeamRepo, req.Team)
+	if err != nil {
+		return nil, err
+	}
+
+	severity := strings.TrimSpace(req.Severity)
+	if severity == "" {
+		severity = defaultAlertSeverity
+	}
+
+	return &model.Heartbeat{
+		Name:            name,
+		Team:            team.Name,
+		Severity:        severity,
+		IntervalSeconds: req.IntervalSeconds,
+		GraceSeconds:    req.GraceSeconds,
+	}, nil
+}
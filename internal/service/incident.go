@@ -2,6 +2,14 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/hascho/go-incident-dashboard-api/internal/model"
 	"github.com/hascho/go-incident-dashboard-api/internal/queue"
@@ -9,75 +17,501 @@ import (
 	"github.com/rs/zerolog"
 )
 
+var (
+	// ErrInvalidUpdate is returned when an update carries an unusable field value.
+	ErrInvalidUpdate = errors.New("invalid incident update")
+	// ErrInvalidFilter is returned when an incident listing is asked for with unusable parameters.
+	ErrInvalidFilter = errors.New("invalid incident filter")
+)
+
+const (
+	defaultIncidentPageSize = 50
+	maxIncidentPageSize     = 200
+)
+
+// incidentWriter is the part of the incident service that alert ingestion,
+// heartbeats and synthetic checks drive inside their own transactions. It is
+// part of IncidentService, so only this package can implement that.
+type incidentWriter interface {
+	createIncident(ctx context.Context, tx *sql.Tx, actor string, incident *model.Incident) (*model.Incident, []*repository.Job, error)
+	updateIncident(ctx context.Context, tx *sql.Tx, incidentID string, actor string, req model.UpdateIncidentRequest) (*model.Incident, []*repository.Job, error)
+	publish(ctx context.Context, jobs []*repository.Job)
+}
+
 type IncidentService interface {
-	CreateIncident(ctx context.Context, incident *model.Incident) (*model.Incident, error)
+	CreateIncident(ctx context.Context, actor string, incident *model.Incident) (*model.Incident, error)
 	GetIncidentByID(ctx context.Context, id string) (*model.Incident, error)
-	GetAllIncidents(ctx context.Context) ([]*model.Incident, error)
-	UpdateIncident(ctx context.Context, incidentID string, req model.UpdateIncidentRequest) (*model.Incident, error)
+	GetAllIncidents(ctx context.Context, filter model.IncidentFilter) (*model.IncidentPage, error)
+	UpdateIncident(ctx context.Context, incidentID string, actor string, req model.UpdateIncidentRequest) (*model.Incident, error)
 	DeleteIncident(ctx context.Context, incidentID string) error
+	AcknowledgeIncident(ctx context.Context, incidentID string, actor string) (*model.Incident, error)
+	GetMTTAStats(ctx context.Context, filter model.MTTAFilter) ([]*model.MTTAStat, error)
+	SearchIncidents(ctx context.Context, q string, limit int) ([]*model.IncidentSearchHit, error)
+	incidentWriter
 }
 
 type incidentService struct {
-	Repo    repository.IncidentRepository
-	Logger  zerolog.Logger
-	JobRepo repository.JobRepository
-	Queue   queue.TaskQueue
+	Repo           repository.IncidentRepository
+	Logger         zerolog.Logger
+	JobRepo        repository.JobRepository
+	TransitionRepo repository.TransitionRepository
+	EventRepo      repository.EventRepository
+	WebhookRepo    repository.WebhookRepository
+	RoutingRepo    repository.RoutingRuleRepository
+	EscalationRepo repository.EscalationRepository
+	ScheduleRepo   repository.ScheduleRepository
+	TeamRepo       repository.TeamRepository
+	Tx             repository.Transactor
+	Queue          queue.TaskQueue
+	DefaultTarget  repository.JobTarget // where new incidents are announced when no routing rule matches
+	GroupingWindow time.Duration        // how long after its last occurrence an incident absorbs duplicates; 0 disables grouping
 }
 
-func NewIncidentService(repo repository.IncidentRepository, logger zerolog.Logger, jobRepo repository.JobRepository, q queue.TaskQueue) IncidentService {
+func NewIncidentService(repo repository.IncidentRepository, logger zerolog.Logger, jobRepo repository.JobRepository, transitionRepo repository.TransitionRepository, eventRepo repository.EventRepository, webhookRepo repository.WebhookRepository, routingRepo repository.RoutingRuleRepository, escalationRepo repository.EscalationRepository, scheduleRepo repository.ScheduleRepository, teamRepo repository.TeamRepository, tx repository.Transactor, q queue.TaskQueue, defaultTarget repository.JobTarget, groupingWindow time.Duration) IncidentService {
 	return &incidentService{
-		Repo:    repo,
-		Logger:  logger,
-		JobRepo: jobRepo,
-		Queue:   q,
+		Repo:           repo,
+		Logger:         logger,
+		JobRepo:        jobRepo,
+		TransitionRepo: transitionRepo,
+		EventRepo:      eventRepo,
+		WebhookRepo:    webhookRepo,
+		RoutingRepo:    routingRepo,
+		EscalationRepo: escalationRepo,
+		ScheduleRepo:   scheduleRepo,
+		TeamRepo:       teamRepo,
+		Tx:             tx,
+		Queue:          q,
+		DefaultTarget:  defaultTarget,
+		GroupingWindow: groupingWindow,
 	}
 }
 
-func (s *incidentService) CreateIncident(ctx context.Context, incident *model.Incident) (*model.Incident, error) {
-	createdIncident, err := s.Repo.CreateIncident(ctx, incident)
+// CreateIncident persists the incident, its timeline entry and its notification
+// jobs in one transaction (a transactional outbox), so an incident can never exist
+// without the jobs that announce it. Redis is only signalled after commit.
+// The team must be a known team; it is stored under the team's canonical name.
+// A duplicate of an active incident reported within the grouping window is
+// recorded as another occurrence of it instead; the returned incident then
+// has an OccurrenceCount above one.
+func (s *incidentService) CreateIncident(ctx context.Context, actor string, incident *model.Incident) (*model.Incident, error) {
+	var createdIncident *model.Incident
+	var jobs []*repository.Job
+
+	err := s.Tx.WithinTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		createdIncident, jobs, err = s.createIncident(ctx, tx, actor, incident)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if err := s.JobRepo.CreateJob(ctx, createdIncident); err != nil {
-		// Log the failure but do NOT fail the HTTP request
-		s.Logger.Error().Err(err).Msg("Failed to create job entry for notification. Incident created but notification is missing.")
+	s.publish(ctx, jobs)
+
+	return createdIncident, nil
+}
+
+// createIncident is CreateIncident as part of tx. The returned jobs are to be
+// published once tx has committed.
+func (s *incidentService) createIncident(ctx context.Context, tx *sql.Tx, actor string, incident *model.Incident) (*model.Incident, []*repository.Job, error) {
+	team, err := resolveTeam(ctx, s.TeamRepo.WithTx(tx), incident.Team)
+	if err != nil {
+		return nil, nil, err
+	}
+	incident.Team = team.Name
+
+	incident.Fingerprint = strings.TrimSpace(incident.Fingerprint)
+	if incident.Fingerprint == "" {
+		incident.Fingerprint = incidentFingerprint(incident.Team, incident.Title)
+	}
+	if s.GroupingWindow > 0 {
+		grouped, jobs, err := s.groupOccurrence(ctx, tx, actor, incident)
+		if err == nil || !errors.Is(err, sql.ErrNoRows) {
+			return grouped, jobs, err
+		}
 	}
 
-	if err := s.Queue.Publish(ctx, createdIncident.ID); err != nil {
-		// if Redis is down, it's okay! polling will catch it.
-		s.Logger.Warn().Err(err).Msg("Redis publish failed - worker will catch up via polling")
-	} else {
-		s.Logger.Info().Str("incident_id", createdIncident.ID).Msg("Published job to Redis")
+	createdIncident, err := s.Repo.WithTx(tx).CreateIncident(ctx, incident)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return createdIncident, nil
+	err = s.EventRepo.WithTx(tx).CreateEvent(ctx, &model.IncidentEvent{
+		IncidentID: createdIncident.ID,
+		Type:       model.EventCreated,
+		Actor:      actor,
+		Message:    fmt.Sprintf("Incident created with severity %s for team %s", createdIncident.Severity, createdIncident.Team),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := syncEscalation(ctx, s.EscalationRepo.WithTx(tx), createdIncident); err != nil {
+		return nil, nil, err
+	}
+
+	jobs, err := s.enqueueEvent(ctx, tx, model.EventTypeIncidentCreated, createdIncident, nil)
+	return createdIncident, jobs, err
+}
+
+// groupOccurrence records incident as another occurrence of the active
+// incident with the same fingerprint, if one occurred within the grouping
+// window. Occurrences go on the timeline but notify no one, unless the
+// occurrence is more severe: then the incident's severity is raised to it,
+// which notifies and escalates like any severity change. It returns
+// sql.ErrNoRows when there is nothing to group into.
+func (s *incidentService) groupOccurrence(ctx context.Context, tx *sql.Tx, actor string, incident *model.Incident) (*model.Incident, []*repository.Job, error) {
+	repo := s.Repo.WithTx(tx)
+	// held until commit, so a concurrent duplicate waits and then groups into
+	// whichever incident this transaction creates
+	if err := repo.LockFingerprint(ctx, incident.Fingerprint); err != nil {
+		return nil, nil, err
+	}
+
+	existing, err := repo.GetGroupableIncident(ctx, incident.Fingerprint, time.Now().Add(-s.GroupingWindow))
+	if err != nil {
+		return nil, nil, err
+	}
+	grouped, err := repo.RecordOccurrence(ctx, existing.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	metadata, _ := json.Marshal(map[string]any{
+		"occurrence":  grouped.OccurrenceCount,
+		"title":       incident.Title,
+		"description": incident.Description,
+		"severity":    incident.Severity,
+	})
+	err = s.EventRepo.WithTx(tx).CreateEvent(ctx, &model.IncidentEvent{
+		IncidentID: grouped.ID,
+		Type:       model.EventOccurred,
+		Actor:      actor,
+		Message:    fmt.Sprintf("Occurred again (%d occurrences): %s", grouped.OccurrenceCount, incident.Title),
+		Metadata:   metadata,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var jobs []*repository.Job
+	if moreSevere(incident.Severity, grouped.Severity) {
+		severity := incident.Severity
+		grouped, jobs, err = s.updateIncident(ctx, tx, grouped.ID, actor, model.UpdateIncidentRequest{Severity: &severity})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	s.Logger.Info().
+		Str("incident_id", grouped.ID).
+		Str("fingerprint", grouped.Fingerprint).
+		Int("occurrence_count", grouped.OccurrenceCount).
+		Str("severity", grouped.Severity).
+		Msg("Duplicate incident grouped")
+	return grouped, jobs, nil
+}
+
+// severityRanks orders the severities that have a well-known meaning, least
+// severe first. Others are free text and never compare as more severe.
+var severityRanks = map[string]int{
+	"low": 1, "info": 1, "sev4": 1, "p4": 1,
+	"medium": 2, "warning": 2, "sev3": 2, "p3": 2,
+	"high": 3, "sev2": 3, "p2": 3,
+	"critical": 4, "sev1": 4, "p1": 4,
+}
+
+// moreSevere reports whether severity outranks than, ignoring case
+func moreSevere(severity string, than string) bool {
+	rank, thanRank := severityRanks[strings.ToLower(severity)], severityRanks[strings.ToLower(than)]
+	return rank > 0 && thanRank > 0 && rank > thanRank
+}
+
+// incidentFingerprint is the fingerprint of an incident reported without one:
+// its team and title, ignoring case and spacing. The fingerprint migration
+// computes the same value in SQL for incidents that predate it.
+func incidentFingerprint(team string, title string) string {
+	normalized := teamSlug(team) + "\n" + strings.ToLower(strings.Join(strings.Fields(title), " "))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
 }
 
 func (s *incidentService) GetIncidentByID(ctx context.Context, id string) (*model.Incident, error) {
 	return s.Repo.GetIncidentByID(ctx, id)
 }
 
-func (s *incidentService) GetAllIncidents(ctx context.Context) ([]*model.Incident, error) {
-	return s.Repo.GetAllIncidents(ctx)
+// GetAllIncidents returns one page of the incidents matching filter, newest
+// first unless filter says otherwise.
+func (s *incidentService) GetAllIncidents(ctx context.Context, filter model.IncidentFilter) (*model.IncidentPage, error) {
+	filter, err := s.normalizeFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.Repo.GetAllIncidents(ctx, filter)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return nil, fmt.Errorf("%w: cursor is malformed or was issued for another sort order", ErrInvalidFilter)
+	}
+	return page, err
 }
 
-func (s *incidentService) UpdateIncident(ctx context.Context, incidentID string, req model.UpdateIncidentRequest) (*model.Incident, error) {
-	existingIncident, err := s.Repo.GetIncidentByID(ctx, incidentID)
+// normalizeFilter validates a listing filter and fills in its defaults
+func (s *incidentService) normalizeFilter(ctx context.Context, filter model.IncidentFilter) (model.IncidentFilter, error) {
+	for i, status := range filter.Statuses {
+		normalized, err := normalizeStatus(status)
+		if err != nil {
+			return filter, fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+		}
+		filter.Statuses[i] = normalized
+	}
+
+	// a known team is matched whatever its spelling; an unknown one matches nothing
+	if filter.Team != "" {
+		team, err := resolveTeam(ctx, s.TeamRepo, filter.Team)
+		if err != nil && !errors.Is(err, ErrUnknownTeam) {
+			return filter, err
+		}
+		if team != nil {
+			filter.Team = team.Name
+		}
+	}
+
+	switch filter.Sort {
+	case "":
+		filter.Sort = model.SortCreatedAt
+	case model.SortCreatedAt, model.SortUpdatedAt:
+	default:
+		return filter, fmt.Errorf("%w: cannot sort by %q (expected %s or %s)", ErrInvalidFilter, filter.Sort, model.SortCreatedAt, model.SortUpdatedAt)
+	}
+
+	switch strings.ToLower(filter.Direction) {
+	case "", model.SortDesc:
+		filter.Direction = model.SortDesc
+	case model.SortAsc:
+		filter.Direction = model.SortAsc
+	default:
+		return filter, fmt.Errorf("%w: direction must be %s or %s", ErrInvalidFilter, model.SortAsc, model.SortDesc)
+	}
+
+	switch {
+	case filter.Limit == 0:
+		filter.Limit = defaultIncidentPageSize
+	case filter.Limit < 0 || filter.Limit > maxIncidentPageSize:
+		return filter, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidFilter, maxIncidentPageSize)
+	}
+	return filter, nil
+}
+
+// UpdateIncident applies req and announces it as a single event: resolved or
+// reopened when the status moved that way, updated otherwise. The notifications
+// carry the list of changed fields.
+func (s *incidentService) UpdateIncident(ctx context.Context, incidentID string, actor string, req model.UpdateIncidentRequest) (*model.Incident, error) {
+	var updatedIncident *model.Incident
+	var jobs []*repository.Job
+
+	err := s.Tx.WithinTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		updatedIncident, jobs, err = s.updateIncident(ctx, tx, incidentID, actor, req)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	s.publish(ctx, jobs)
+
+	return updatedIncident, nil
+}
+
+// updateIncident is UpdateIncident as part of tx. The returned jobs are to be
+// published once tx has committed.
+func (s *incidentService) updateIncident(ctx context.Context, tx *sql.Tx, incidentID string, actor string, req model.UpdateIncidentRequest) (*model.Incident, []*repository.Job, error) {
+	// lock the row so concurrent updates validate against the status they will overwrite
+	existingIncident, err := s.Repo.WithTx(tx).GetIncidentByIDForUpdate(ctx, incidentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var changes []model.FieldChange
+	var transition *model.StatusTransition
 	if req.Status != nil {
-		existingIncident.Status = *req.Status
+		newStatus, err := normalizeStatus(*req.Status)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// re-sending the current status is a no-op rather than a transition
+		if newStatus != existingIncident.Status {
+			if err := validateTransition(existingIncident.Status, newStatus); err != nil {
+				return nil, nil, err
+			}
+			transition = &model.StatusTransition{
+				IncidentID: existingIncident.ID,
+				FromStatus: existingIncident.Status,
+				ToStatus:   newStatus,
+				Actor:      actor,
+			}
+			if req.Reason != nil {
+				transition.Reason = *req.Reason
+			}
+			changes = append(changes, model.FieldChange{Field: "status", From: existingIncident.Status, To: newStatus})
+			existingIncident.Status = newStatus
+
+			// only the first acknowledgement counts towards time-to-acknowledge
+			if newStatus == model.StatusAcknowledged && existingIncident.AcknowledgedAt == nil {
+				now := time.Now()
+				existingIncident.AcknowledgedAt = &now
+				existingIncident.AcknowledgedBy = &actor
+			}
+		}
 	}
-	if req.Description != nil {
+
+	var severityChange *model.FieldChange
+	if req.Severity != nil {
+		severity := strings.TrimSpace(*req.Severity)
+		if severity == "" {
+			return nil, nil, fmt.Errorf("%w: severity cannot be empty", ErrInvalidUpdate)
+		}
+		if severity != existingIncident.Severity {
+			severityChange = &model.FieldChange{Field: "severity", From: existingIncident.Severity, To: severity}
+			changes = append(changes, *severityChange)
+			existingIncident.Severity = severity
+		}
+	}
+
+	descriptionChanged := req.Description != nil && *req.Description != existingIncident.Description
+	if descriptionChanged {
+		changes = append(changes, model.FieldChange{Field: "description", From: existingIncident.Description, To: *req.Description})
 		existingIncident.Description = *req.Description
 	}
 
-	return s.Repo.UpdateIncident(ctx, existingIncident)
+	updatedIncident, err := s.Repo.WithTx(tx).UpdateIncident(ctx, existingIncident)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if descriptionChanged {
+		err = s.EventRepo.WithTx(tx).CreateEvent(ctx, &model.IncidentEvent{
+			IncidentID: incidentID,
+			Type:       model.EventDescriptionUpdated,
+			Actor:      actor,
+			Message:    updatedIncident.Description,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if severityChange != nil {
+		metadata, _ := json.Marshal(map[string]string{"from": severityChange.From, "to": severityChange.To})
+		err = s.EventRepo.WithTx(tx).CreateEvent(ctx, &model.IncidentEvent{
+			IncidentID: incidentID,
+			Type:       model.EventSeverityChanged,
+			Actor:      actor,
+			Message:    fmt.Sprintf("Severity changed from %s to %s", severityChange.From, severityChange.To),
+			Metadata:   metadata,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if transition != nil {
+		if err := s.TransitionRepo.WithTx(tx).CreateTransition(ctx, transition); err != nil {
+			return nil, nil, err
+		}
+
+		metadata, _ := json.Marshal(map[string]string{"from": transition.FromStatus, "to": transition.ToStatus})
+		err = s.EventRepo.WithTx(tx).CreateEvent(ctx, &model.IncidentEvent{
+			IncidentID: incidentID,
+			Type:       model.EventStatusChanged,
+			Actor:      actor,
+			Message:    statusChangeMessage(transition),
+			Metadata:   metadata,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		s.Logger.Info().
+			Str("incident_id", incidentID).
+			Str("from", transition.FromStatus).
+			Str("to", transition.ToStatus).
+			Str("actor", actor).
+			Msg("Incident status transitioned")
+	}
+
+	if len(changes) == 0 {
+		return updatedIncident, nil, nil
+	}
+
+	// acknowledging stops the escalation, reopening or a severity change may start it
+	if transition != nil || severityChange != nil {
+		if err := syncEscalation(ctx, s.EscalationRepo.WithTx(tx), updatedIncident); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	jobs, err := s.enqueueEvent(ctx, tx, updateEventType(transition), updatedIncident, changes)
+	return updatedIncident, jobs, err
 }
 
 func (s *incidentService) DeleteIncident(ctx context.Context, incidentID string) error {
-	return s.Repo.DeleteIncident(ctx, incidentID)
+	var jobs []*repository.Job
+
+	err := s.Tx.WithinTx(ctx, func(tx *sql.Tx) error {
+		// keep a snapshot so subscribers learn what was deleted
+		incident, err := s.Repo.WithTx(tx).GetIncidentByIDForUpdate(ctx, incidentID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.Repo.WithTx(tx).DeleteIncident(ctx, incidentID); err != nil {
+			return err
+		}
+
+		jobs, err = s.enqueueEvent(ctx, tx, model.EventTypeIncidentDeleted, incident, nil)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.publish(ctx, jobs)
+	return nil
+}
+
+// AcknowledgeIncident moves an open incident to acknowledged, recording who
+// acknowledged it and when, and stops its escalation. Acknowledging an already
+// acknowledged incident changes nothing.
+func (s *incidentService) AcknowledgeIncident(ctx context.Context, incidentID string, actor string) (*model.Incident, error) {
+	status := model.StatusAcknowledged
+	return s.UpdateIncident(ctx, incidentID, actor, model.UpdateIncidentRequest{Status: &status})
+}
+
+func (s *incidentService) GetMTTAStats(ctx context.Context, filter model.MTTAFilter) ([]*model.MTTAStat, error) {
+	return s.Repo.GetMTTAStats(ctx, filter)
+}
+
+// updateEventType names the event an update is announced as
+func updateEventType(transition *model.StatusTransition) string {
+	switch {
+	case transition == nil:
+		return model.EventTypeIncidentUpdated
+	case transition.ToStatus == model.StatusResolved:
+		return model.EventTypeIncidentResolved
+	case transition.ToStatus == model.StatusOpen:
+		// open is only reachable again from resolved or closed
+		return model.EventTypeIncidentReopened
+	default:
+		return model.EventTypeIncidentUpdated
+	}
+}
+
+func statusChangeMessage(t *model.StatusTransition) string {
+	msg := fmt.Sprintf("Status changed from %s to %s", t.FromStatus, t.ToStatus)
+	if t.Reason != "" {
+		msg += ": " + t.Reason
+	}
+	return msg
 }
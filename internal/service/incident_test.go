@@ -0,0 +1,25 @@
+package service
+
+import "testing"
+
+func TestMoreSevere(t *testing.T) {
+	tests := []struct {
+		severity string
+		than     string
+		want     bool
+	}{
+		{"critical", "high", true},
+		{"high", "critical", false},
+		{"high", "high", false},
+		{"SEV1", "medium", true},
+		{"p2", "warning", true},
+		{"info", "low", false},
+		{"urgent", "low", false},
+		{"critical", "custom", false},
+	}
+	for _, tt := range tests {
+		if got := moreSevere(tt.severity, tt.than); got != tt.want {
+			t.Errorf("moreSevere(%q, %q) = %v, want %v", tt.severity, tt.than, got, tt.want)
+		}
+	}
+}
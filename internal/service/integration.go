@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+	"github.com/hascho/go-incident-dashboard-api/internal/repository"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrInvalidIntegration  = errors.New("invalid integration")
+	ErrIntegrationConflict = errors.New("integration already exists")
+	// ErrIntegrationUnauthorized is returned when an event arrives without the integration's token.
+	ErrIntegrationUnauthorized = errors.New("missing or invalid integration token")
+)
+
+type IntegrationService interface {
+	CreateIntegration(ctx context.Context, req model.IntegrationRequest) (*model.IntegrationWithToken, error)
+	GetIntegrationByID(ctx context.Context, id string) (*model.Integration, error)
+	GetAllIntegrations(ctx context.Context) ([]*model.Integration, error)
+	UpdateIntegration(ctx context.Context, id string, req model.IntegrationRequest) (*model.Integration, error)
+	RotateToken(ctx context.Context, id string) (*model.IntegrationWithToken, error)
+	DeleteIntegration(ctx context.Context, id string) error
+}
+
+type integrationService struct {
+	Repo     repository.IntegrationRepository
+	TeamRepo repository.TeamRepository
+	Logger   zerolog.Logger
+}
+
+func NewIntegrationService(repo repository.IntegrationRepository, teamRepo repository.TeamRepository, logger zerolog.Logger) IntegrationService {
+	return &integrationService{
+		Repo:     repo,
+		TeamRepo: teamRepo,
+		Logger:   logger,
+	}
+}
+
+// CreateIntegration stores the integration with a new token. The token is
+// only returned here and by RotateToken.
+func (s *integrationService) CreateIntegration(ctx context.Context, req model.IntegrationRequest) (*model.IntegrationWithToken, error) {
+	integration, err := s.integrationFromRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.Repo.GetIntegrationByName(ctx, integration.Name); err == nil {
+		return nil, fmt.Errorf("%w: %q", ErrIntegrationConflict, integration.Name)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	token, err := newIntegrationToken()
+	if err != nil {
+		return nil, err
+	}
+	integration.TokenHash = hashIntegrationToken(token)
+
+	created, err := s.Repo.CreateIntegration(ctx, integration)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Logger.Info().Str("integration_id", created.ID).Str("integration", created.Name).Msg("Integration created")
+	return withToken(created, token), nil
+}
+
+func (s *integrationService) GetIntegrationByID(ctx context.Context, id string) (*model.Integration, error) {
+	return s.Repo.GetIntegrationByID(ctx, id)
+}
+
+func (s *integrationService) GetAllIntegrations(ctx context.Context) ([]*model.Integration, error) {
+	return s.Repo.GetAllIntegrations(ctx)
+}
+
+// UpdateIntegration replaces the integration's name and mapping. The token
+// stays valid.
+func (s *integrationService) UpdateIntegration(ctx context.Context, id string, req model.IntegrationRequest) (*model.Integration, error) {
+	integration, err := s.integrationFromRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	integration.ID = id
+
+	existing, err := s.Repo.GetIntegrationByName(ctx, integration.Name)
+	if err == nil && existing.ID != id {
+		return nil, fmt.Errorf("%w: %q", ErrIntegrationConflict, integration.Name)
+	} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	updated, err := s.Repo.UpdateIntegration(ctx, integration)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Logger.Info().Str("integration_id", updated.ID).Str("integration", updated.Name).Msg("Integration updated")
+	return updated, nil
+}
+
+// RotateToken issues a new token; the old one stops working immediately
+func (s *integrationService) RotateToken(ctx context.Context, id string) (*model.IntegrationWithToken, error) {
+	token, err := newIntegrationToken()
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.Repo.SetTokenHash(ctx, id, hashIntegrationToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	s.Logger.Info().Str("integration_id", updated.ID).Str("integration", updated.Name).Msg("Integration token rotated")
+	return withToken(updated, token), nil
+}
+
+// DeleteIntegration removes the integration. Incidents it opened stay.
+func (s *integrationService) DeleteIntegration(ctx context.Context, id string) error {
+	return s.Repo.DeleteIntegration(ctx, id)
+}
+
+func (s *integrationService) integrationFromRequest(ctx context.Context, req model.IntegrationRequest) (*model.Integration, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name cannot be empty", ErrInvalidIntegration)
+	}
+
+	if _, err := compileMapping(req.Mapping); err != nil {
+		return nil, err
+	}
+	// a fixed team can be checked now rather than on every event
+	if !isJSONPath(req.Mapping.Team) {
+		team, err := resolveTeam(ctx, s.TeamRepo, req.Mapping.Team)
+		if err != nil {
+			return nil, err
+		}
+		req.Mapping.Team = team.Name
+	}
+
+	return &model.Integration{Name: name, Mapping: req.Mapping}, nil
+}
+
+// compiledMapping is an IntegrationMapping with its paths compiled. A field
+// with a nil path holds a literal.
+type compiledMapping struct {
+	Title       mappedField
+	Description mappedField
+	Severity    mappedField
+	SeverityMap map[string]string
+	Team        mappedField
+	DedupKey    mappedField
+	Resolve     *compiledResolve
+}
+
+type mappedField struct {
+	Literal string
+	Path    jsonPath
+}
+
+type compiledResolve struct {
+	Path   jsonPath
+	Values []string
+}
+
+func compileMapping(mapping model.IntegrationMapping) (*compiledMapping, error) {
+	if strings.TrimSpace(mapping.Title) == "" {
+		return nil, fmt.Errorf("%w: mapping.title is required", ErrInvalidIntegration)
+	}
+	if strings.TrimSpace(mapping.Team) == "" {
+		return nil, fmt.Errorf("%w: mapping.team is required", ErrInvalidIntegration)
+	}
+
+	compiled := &compiledMapping{SeverityMap: make(map[string]string, len(mapping.SeverityMap))}
+	fields := []struct {
+		name  string
+		expr  string
+		field *mappedField
+	}{
+		{"title", mapping.Title, &compiled.Title},
+		{"description", mapping.Description, &compiled.Description},
+		{"severity", mapping.Severity, &compiled.Severity},
+		{"team", mapping.Team, &compiled.Team},
+		{"dedup_key", mapping.DedupKey, &compiled.DedupKey},
+	}
+	for _, f := range fields {
+		if !isJSONPath(f.expr) {
+			f.field.Literal = strings.TrimSpace(f.expr)
+			continue
+		}
+		path, err := compileJSONPath(f.expr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: mapping.%s: %v", ErrInvalidIntegration, f.name, err)
+		}
+		f.field.Path = path
+	}
+
+	for from, to := range mapping.SeverityMap {
+		compiled.SeverityMap[strings.ToLower(from)] = to
+	}
+
+	if mapping.Resolve != nil {
+		path, err := compileJSONPath(mapping.Resolve.Path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: mapping.resolve.path: %v", ErrInvalidIntegration, err)
+		}
+		if len(mapping.Resolve.Values) == 0 {
+			return nil, fmt.Errorf("%w: mapping.resolve.values cannot be empty", ErrInvalidIntegration)
+		}
+		compiled.Resolve = &compiledResolve{Path: path, Values: mapping.Resolve.Values}
+	}
+	return compiled, nil
+}
+
+// value reads the field from a decoded payload; a missing path gives ""
+func (f mappedField) value(doc any) string {
+	if f.Path == nil {
+		return f.Literal
+	}
+	value, _ := f.Path.lookup(doc)
+	return strings.TrimSpace(jsonText(value))
+}
+
+func newIntegrationToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate integration token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func hashIntegrationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// checkIntegrationToken compares in constant time so response timing does not
+// reveal how much of a guessed token is right
+func checkIntegrationToken(integration *model.Integration, token string) error {
+	if token == "" || subtle.ConstantTimeCompare([]byte(hashIntegrationToken(token)), []byte(integration.TokenHash)) != 1 {
+		return ErrIntegrationUnauthorized
+	}
+	return nil
+}
+
+func withToken(integration *model.Integration, token string) *model.IntegrationWithToken {
+	return &model.IntegrationWithToken{
+		Integration: integration,
+		Token:       token,
+		IngestURL:   fmt.Sprintf("/integrations/%s/events", integration.ID),
+	}
+}
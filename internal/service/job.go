@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+	"github.com/hascho/go-incident-dashboard-api/internal/queue"
+	"github.com/hascho/go-incident-dashboard-api/internal/repository"
+	"github.com/rs/zerolog"
+)
+
+// JobService exposes the dead-letter queue of notification jobs to operators.
+type JobService interface {
+	ListDeadLetteredJobs(ctx context.Context, limit int) ([]*model.NotificationJob, error)
+	GetJob(ctx context.Context, jobID uuid.UUID) (*model.NotificationJobDetail, error)
+	GetIncidentAttempts(ctx context.Context, incidentID string) ([]*model.NotificationAttempt, error)
+	RequeueJobs(ctx context.Context, jobIDs []uuid.UUID) ([]*model.NotificationJob, error)
+	DiscardJobs(ctx context.Context, jobIDs []uuid.UUID) ([]*model.NotificationJob, error)
+}
+
+type jobService struct {
+	JobRepo      repository.JobRepository
+	IncidentRepo repository.IncidentRepository
+	AttemptRepo  repository.AttemptRepository
+	Tx           repository.Transactor
+	Queue        queue.TaskQueue
+	Logger       zerolog.Logger
+}
+
+func NewJobService(jobRepo repository.JobRepository, incidentRepo repository.IncidentRepository, attemptRepo repository.AttemptRepository, tx repository.Transactor, q queue.TaskQueue, logger zerolog.Logger) JobService {
+	return &jobService{
+		JobRepo:      jobRepo,
+		IncidentRepo: incidentRepo,
+		AttemptRepo:  attemptRepo,
+		Tx:           tx,
+		Queue:        q,
+		Logger:       logger,
+	}
+}
+
+func (s *jobService) ListDeadLetteredJobs(ctx context.Context, limit int) ([]*model.NotificationJob, error) {
+	jobs, err := s.JobRepo.ListJobsByStatus(ctx, repository.JobStatusPermanentlyFailed, limit)
+	if err != nil {
+		return nil, err
+	}
+	return toNotificationJobs(jobs), nil
+}
+
+func (s *jobService) GetJob(ctx context.Context, jobID uuid.UUID) (*model.NotificationJobDetail, error) {
+	job, err := s.JobRepo.GetJobByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts, err := s.AttemptRepo.GetAttemptsByJobID(ctx, jobID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.NotificationJobDetail{
+		NotificationJob: toNotificationJob(job),
+		Attempts:        attempts,
+	}, nil
+}
+
+// GetIncidentAttempts returns every delivery attempt made for an incident's notifications
+func (s *jobService) GetIncidentAttempts(ctx context.Context, incidentID string) ([]*model.NotificationAttempt, error) {
+	// surface sql.ErrNoRows for unknown incidents instead of an empty list
+	if _, err := s.IncidentRepo.GetIncidentByID(ctx, incidentID); err != nil {
+		return nil, err
+	}
+	return s.AttemptRepo.GetAttemptsByIncidentID(ctx, incidentID)
+}
+
+// RequeueJobs resets dead-lettered jobs and flags their incidents as pending
+// again in one transaction, then wakes the workers.
+func (s *jobService) RequeueJobs(ctx context.Context, jobIDs []uuid.UUID) ([]*model.NotificationJob, error) {
+	var jobs []*repository.Job
+
+	err := s.Tx.WithinTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		jobs, err = s.JobRepo.WithTx(tx).RequeueJobs(ctx, jobIDs)
+		if err != nil {
+			return err
+		}
+
+		for _, job := range jobs {
+			if err := s.IncidentRepo.WithTx(tx).UpdateNotificationStatus(ctx, job.IncidentID.String(), model.NotificationPending); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, job := range jobs {
+		if err := s.Queue.Publish(ctx, job.ID.String()); err != nil {
+			s.Logger.Warn().Err(err).Msg("Redis publish failed - worker will catch up via polling")
+		}
+	}
+	s.Logger.Info().Int("count", len(jobs)).Msg("Requeued dead-lettered jobs")
+
+	return toNotificationJobs(jobs), nil
+}
+
+func (s *jobService) DiscardJobs(ctx context.Context, jobIDs []uuid.UUID) ([]*model.NotificationJob, error) {
+	jobs, err := s.JobRepo.DiscardJobs(ctx, jobIDs)
+	if err != nil {
+		return nil, err
+	}
+	s.Logger.Info().Int("count", len(jobs)).Msg("Discarded dead-lettered jobs")
+	return toNotificationJobs(jobs), nil
+}
+
+func toNotificationJob(job *repository.Job) model.NotificationJob {
+	view := model.NotificationJob{
+		ID:            job.ID.String(),
+		IncidentID:    job.IncidentID.String(),
+		Type:          job.Type,
+		EventType:     job.EventType,
+		Channel:       job.Channel,
+		Status:        job.Status,
+		Attempts:      job.Retries,
+		NextAttemptAt: job.NextAttemptAt,
+		Payload:       job.Payload,
+		CreatedAt:     job.CreatedAt,
+		UpdatedAt:     job.UpdatedAt,
+	}
+	if job.LastError != nil {
+		view.LastError = *job.LastError
+	}
+	return view
+}
+
+func toNotificationJobs(jobs []*repository.Job) []*model.NotificationJob {
+	views := make([]*model.NotificationJob, len(jobs))
+	for i, job := range jobs {
+		view := toNotificationJob(job)
+		views[i] = &view
+	}
+	return views
+}
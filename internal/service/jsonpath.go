@@ -0,0 +1,120 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is a compiled JSONPath-style expression. Only the parts that pick a
+// single value are supported: the root "$", member access (".name",
+// "['name']" or "[\"name\"]") and array indexes ("[0]", or "[-1]" for the
+// last element).
+type jsonPath []pathStep
+
+type pathStep struct {
+	Key   string
+	Index int
+	IsKey bool
+}
+
+// isJSONPath reports whether a mapping field is an expression rather than a literal
+func isJSONPath(expr string) bool {
+	return strings.HasPrefix(expr, "$")
+}
+
+func compileJSONPath(expr string) (jsonPath, error) {
+	if !isJSONPath(expr) {
+		return nil, fmt.Errorf("path %q must start with $", expr)
+	}
+
+	path := jsonPath{} // non-nil even for "$", so it never reads as a literal
+	rest := expr[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			if key == "" {
+				return nil, fmt.Errorf("path %q has an empty member name", expr)
+			}
+			path = append(path, pathStep{Key: key, IsKey: true})
+			rest = rest[end+1:]
+
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("path %q has an unclosed [", expr)
+			}
+			inner := rest[1:end]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				path = append(path, pathStep{Key: inner[1 : len(inner)-1], IsKey: true})
+			} else {
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("path %q: [%s] is neither an index nor a quoted name", expr, inner)
+				}
+				path = append(path, pathStep{Index: index})
+			}
+			rest = rest[end+1:]
+
+		default:
+			return nil, fmt.Errorf("path %q: unexpected %q", expr, rest[0])
+		}
+	}
+	return path, nil
+}
+
+// lookup returns the value at the path in a document decoded with UseNumber,
+// and whether there is one.
+func (p jsonPath) lookup(doc any) (any, bool) {
+	current := doc
+	for _, step := range p {
+		if step.IsKey {
+			object, ok := current.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if current, ok = object[step.Key]; !ok {
+				return nil, false
+			}
+			continue
+		}
+
+		array, ok := current.([]any)
+		if !ok {
+			return nil, false
+		}
+		index := step.Index
+		if index < 0 {
+			index += len(array)
+		}
+		if index < 0 || index >= len(array) {
+			return nil, false
+		}
+		current = array[index]
+	}
+	return current, true
+}
+
+// jsonText renders a looked-up value as text: strings as they are, null as
+// empty, and objects and arrays as JSON.
+func jsonText(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		encoded, _ := json.Marshal(v)
+		return string(encoded)
+	}
+}
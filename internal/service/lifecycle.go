@@ -0,0 +1,46 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+)
+
+var (
+	ErrInvalidStatus     = errors.New("invalid incident status")
+	ErrInvalidTransition = errors.New("invalid incident status transition")
+)
+
+// allowedTransitions is the incident lifecycle. Incidents normally move
+// open -> acknowledged -> investigating -> mitigated -> resolved -> closed,
+// may be resolved early from any active state, and may be reopened once
+// resolved or closed.
+var allowedTransitions = map[string][]string{
+	model.StatusOpen:          {model.StatusAcknowledged, model.StatusResolved},
+	model.StatusAcknowledged:  {model.StatusInvestigating, model.StatusResolved},
+	model.StatusInvestigating: {model.StatusMitigated, model.StatusResolved},
+	model.StatusMitigated:     {model.StatusInvestigating, model.StatusResolved},
+	model.StatusResolved:      {model.StatusClosed, model.StatusOpen},
+	model.StatusClosed:        {model.StatusOpen},
+}
+
+// normalizeStatus canonicalises a client supplied status and rejects unknown values.
+func normalizeStatus(status string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(status))
+	if _, ok := allowedTransitions[normalized]; !ok {
+		return "", fmt.Errorf("%w: %q", ErrInvalidStatus, status)
+	}
+	return normalized, nil
+}
+
+// validateTransition reports whether an incident may move from one status to another.
+func validateTransition(from, to string) error {
+	for _, next := range allowedTransitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: cannot move from %q to %q", ErrInvalidTransition, from, to)
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	_ "time/tzdata" // schedules name IANA zones; don't depend on the host's zoneinfo
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+)
+
+// ErrNoOnCall is returned when no layer or override covers the requested moment.
+var ErrNoOnCall = errors.New("no one is on call")
+
+const (
+	dateLayout  = "2006-01-02"
+	clockLayout = "15:04"
+)
+
+// resolveOnCall works out who is on call for schedule at the given moment. An
+// active override wins; otherwise the last layer covering the moment decides.
+func resolveOnCall(schedule *model.Schedule, override *model.ScheduleOverride, at time.Time) (*model.OnCall, error) {
+	if override != nil {
+		return &model.OnCall{
+			ScheduleID: schedule.ID,
+			At:         at,
+			Responder:  override.Responder,
+			Source:     "override",
+			ShiftStart: override.StartsAt,
+			ShiftEnd:   override.EndsAt,
+		}, nil
+	}
+
+	loc, err := time.LoadLocation(schedule.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("schedule %s: %w", schedule.ID, err)
+	}
+
+	for i := len(schedule.Layers) - 1; i >= 0; i-- {
+		layer := schedule.Layers[i]
+		if !restrictionCovers(layer.Restriction, at.In(loc)) {
+			continue
+		}
+		participant, start, end, ok, err := layerShift(layer, loc, at)
+		if err != nil {
+			return nil, fmt.Errorf("schedule %s: %w", schedule.ID, err)
+		}
+		if !ok {
+			continue
+		}
+		return &model.OnCall{
+			ScheduleID: schedule.ID,
+			At:         at,
+			Responder:  layer.Participants[participant],
+			Source:     layerName(layer, i),
+			ShiftStart: start,
+			ShiftEnd:   end,
+		}, nil
+	}
+	return nil, ErrNoOnCall
+}
+
+// layerShift finds the rotation shift of layer containing at: the index of the
+// participant on call and the shift's bounds. ok is false before the layer's
+// first shift. Shifts are counted in calendar days in loc, so handoffs stay at
+// the same wall-clock time across DST changes.
+func layerShift(layer model.ScheduleLayer, loc *time.Location, at time.Time) (participant int, start, end time.Time, ok bool, err error) {
+	startDate, err := time.ParseInLocation(dateLayout, layer.StartDate, loc)
+	if err != nil {
+		return 0, time.Time{}, time.Time{}, false, fmt.Errorf("invalid start_date %q", layer.StartDate)
+	}
+	handoff, err := time.Parse(clockLayout, layer.HandoffTime)
+	if err != nil {
+		return 0, time.Time{}, time.Time{}, false, fmt.Errorf("invalid handoff_time %q", layer.HandoffTime)
+	}
+	if len(layer.Participants) == 0 {
+		return 0, time.Time{}, time.Time{}, false, nil
+	}
+
+	period := 1
+	if layer.Rotation == model.RotationWeekly {
+		period = 7
+	}
+	shiftStart := func(day int) time.Time {
+		return time.Date(startDate.Year(), startDate.Month(), startDate.Day()+day, handoff.Hour(), handoff.Minute(), 0, 0, loc)
+	}
+
+	if at.Before(shiftStart(0)) {
+		return 0, time.Time{}, time.Time{}, false, nil
+	}
+
+	// whole calendar days from the start date to at, rounded down to a shift boundary
+	local := at.In(loc)
+	days := int(civilDate(local).Sub(civilDate(startDate)).Hours() / 24)
+	day := days - days%period
+	if shiftStart(day).After(at) {
+		day -= period // at is on a handoff day, before the handoff
+	}
+
+	shift := day / period
+	return shift % len(layer.Participants), shiftStart(day), shiftStart(day + period), true, nil
+}
+
+// restrictionCovers reports whether local falls in the restriction's daily window
+func restrictionCovers(r *model.ScheduleRestriction, local time.Time) bool {
+	if r == nil {
+		return true
+	}
+	from, err1 := time.Parse(clockLayout, r.StartTime)
+	to, err2 := time.Parse(clockLayout, r.EndTime)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+
+	minute := local.Hour()*60 + local.Minute()
+	start := from.Hour()*60 + from.Minute()
+	end := to.Hour()*60 + to.Minute()
+	switch {
+	case start < end:
+		return minute >= start && minute < end
+	case start > end: // wraps past midnight
+		return minute >= start || minute < end
+	default:
+		return true
+	}
+}
+
+// civilDate drops the time and zone of t, keeping its calendar date
+func civilDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func layerName(layer model.ScheduleLayer, index int) string {
+	if layer.Name != "" {
+		return layer.Name
+	}
+	return fmt.Sprintf("layer %d", index+1)
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+)
+
+func TestResolveOnCall(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("loading zone: %v", err)
+	}
+	local := func(value string) time.Time {
+		at, err := time.ParseInLocation("2006-01-02 15:04", value, loc)
+		if err != nil {
+			t.Fatalf("parsing %q: %v", value, err)
+		}
+		return at
+	}
+	responders := func(names ...string) []model.Responder {
+		participants := make([]model.Responder, len(names))
+		for i, name := range names {
+			participants[i] = model.Responder{Name: name, Channel: "email", Destination: name + "@example.com"}
+		}
+		return participants
+	}
+
+	daily := model.ScheduleLayer{
+		Name:         "primary",
+		Rotation:     model.RotationDaily,
+		StartDate:    "2026-03-02",
+		HandoffTime:  "09:00",
+		Participants: responders("alice", "bob", "carol"),
+	}
+	weekly := model.ScheduleLayer{
+		Name:         "primary",
+		Rotation:     model.RotationWeekly,
+		StartDate:    "2026-03-02", // a Monday
+		HandoffTime:  "09:00",
+		Participants: responders("alice", "bob"),
+	}
+	nights := model.ScheduleLayer{
+		Name:         "nights",
+		Rotation:     model.RotationDaily,
+		StartDate:    "2026-03-01",
+		HandoffTime:  "22:00",
+		Restriction:  &model.ScheduleRestriction{StartTime: "22:00", EndTime: "06:00"},
+		Participants: responders("nina"),
+	}
+	override := &model.ScheduleOverride{
+		Responder: model.Responder{Name: "olga", Channel: "slack"},
+		StartsAt:  local("2026-03-03 08:00"),
+		EndsAt:    local("2026-03-03 18:00"),
+	}
+
+	tests := []struct {
+		name      string
+		layers    []model.ScheduleLayer
+		override  *model.ScheduleOverride
+		at        string
+		responder string
+		source    string
+		start     string
+		end       string
+		err       error
+	}{
+		{name: "daily, first shift", layers: []model.ScheduleLayer{daily}, at: "2026-03-02 12:00", responder: "alice", source: "primary", start: "2026-03-02 09:00", end: "2026-03-03 09:00"},
+		{name: "daily, third shift", layers: []model.ScheduleLayer{daily}, at: "2026-03-04 23:59", responder: "carol", source: "primary", start: "2026-03-04 09:00", end: "2026-03-05 09:00"},
+		{name: "daily, back to the first participant", layers: []model.ScheduleLayer{daily}, at: "2026-03-05 09:00", responder: "alice", source: "primary", start: "2026-03-05 09:00", end: "2026-03-06 09:00"},
+		{name: "daily, handoff day before the handoff", layers: []model.ScheduleLayer{daily}, at: "2026-03-04 08:59", responder: "bob", source: "primary", start: "2026-03-03 09:00", end: "2026-03-04 09:00"},
+		{name: "before the first shift", layers: []model.ScheduleLayer{daily}, at: "2026-03-02 08:59", err: ErrNoOnCall},
+		{name: "weekly, second week", layers: []model.ScheduleLayer{weekly}, at: "2026-03-12 03:00", responder: "bob", source: "primary", start: "2026-03-09 09:00", end: "2026-03-16 09:00"},
+		{name: "weekly, handoff day before the handoff", layers: []model.ScheduleLayer{weekly}, at: "2026-03-09 08:00", responder: "alice", source: "primary", start: "2026-03-02 09:00", end: "2026-03-09 09:00"},
+		{name: "weekly, third week wraps", layers: []model.ScheduleLayer{weekly}, at: "2026-03-16 09:00", responder: "alice", source: "primary", start: "2026-03-16 09:00", end: "2026-03-23 09:00"},
+		// clocks go forward at 02:00 on 8 March: a 23-hour shift, still handing off at 09:00
+		{name: "shift across the start of DST", layers: []model.ScheduleLayer{daily}, at: "2026-03-08 08:30", responder: "carol", source: "primary", start: "2026-03-07 09:00", end: "2026-03-08 09:00"},
+		{name: "first shift after the start of DST", layers: []model.ScheduleLayer{daily}, at: "2026-03-08 09:00", responder: "alice", source: "primary", start: "2026-03-08 09:00", end: "2026-03-09 09:00"},
+		// clocks go back at 02:00 on 1 November: a 25-hour shift
+		{name: "shift across the end of DST", layers: []model.ScheduleLayer{daily}, at: "2026-11-01 03:00", responder: "alice", source: "primary", start: "2026-10-31 09:00", end: "2026-11-01 09:00"},
+		{name: "restriction wrapping midnight, before midnight", layers: []model.ScheduleLayer{daily, nights}, at: "2026-03-03 23:00", responder: "nina", source: "nights", start: "2026-03-03 22:00", end: "2026-03-04 22:00"},
+		{name: "restriction wrapping midnight, after midnight", layers: []model.ScheduleLayer{daily, nights}, at: "2026-03-03 05:59", responder: "nina", source: "nights", start: "2026-03-02 22:00", end: "2026-03-03 22:00"},
+		{name: "restriction wrapping midnight, at its end", layers: []model.ScheduleLayer{daily, nights}, at: "2026-03-03 06:00", responder: "alice", source: "primary", start: "2026-03-02 09:00", end: "2026-03-03 09:00"},
+		{name: "restriction wrapping midnight, midday", layers: []model.ScheduleLayer{daily, nights}, at: "2026-03-03 12:00", responder: "bob", source: "primary", start: "2026-03-03 09:00", end: "2026-03-04 09:00"},
+		{name: "restricted layer only, outside its window", layers: []model.ScheduleLayer{nights}, at: "2026-03-03 12:00", err: ErrNoOnCall},
+		{name: "override wins over the layers", layers: []model.ScheduleLayer{daily, nights}, override: override, at: "2026-03-03 12:00", responder: "olga", source: "override", start: "2026-03-03 08:00", end: "2026-03-03 18:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := &model.Schedule{ID: "schedule-1", TimeZone: loc.String(), Layers: tt.layers}
+			// resolved from UTC, as the API receives it
+			at := local(tt.at).UTC()
+
+			onCall, err := resolveOnCall(schedule, tt.override, at)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("resolveOnCall() error = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveOnCall() error = %v", err)
+			}
+			if onCall.Responder.Name != tt.responder || onCall.Source != tt.source {
+				t.Errorf("on call = %s from %s, want %s from %s", onCall.Responder.Name, onCall.Source, tt.responder, tt.source)
+			}
+			if !onCall.ShiftStart.Equal(local(tt.start)) || !onCall.ShiftEnd.Equal(local(tt.end)) {
+				t.Errorf("shift = %s to %s, want %s to %s", onCall.ShiftStart.In(loc), onCall.ShiftEnd.In(loc), tt.start, tt.end)
+			}
+		})
+	}
+}
+
+func TestRestrictionCovers(t *testing.T) {
+	tests := []struct {
+		name        string
+		restriction *model.ScheduleRestriction
+		clock       string
+		want        bool
+	}{
+		{"no restriction", nil, "03:00", true},
+		{"business hours, inside", &model.ScheduleRestriction{StartTime: "09:00", EndTime: "17:00"}, "09:00", true},
+		{"business hours, at the end", &model.ScheduleRestriction{StartTime: "09:00", EndTime: "17:00"}, "17:00", false},
+		{"business hours, before", &model.ScheduleRestriction{StartTime: "09:00", EndTime: "17:00"}, "08:59", false},
+		{"wraps midnight, late evening", &model.ScheduleRestriction{StartTime: "22:00", EndTime: "06:00"}, "23:30", true},
+		{"wraps midnight, early morning", &model.ScheduleRestriction{StartTime: "22:00", EndTime: "06:00"}, "05:59", true},
+		{"wraps midnight, at the end", &model.ScheduleRestriction{StartTime: "22:00", EndTime: "06:00"}, "06:00", false},
+		{"wraps midnight, midday", &model.ScheduleRestriction{StartTime: "22:00", EndTime: "06:00"}, "12:00", false},
+		{"same start and end", &model.ScheduleRestriction{StartTime: "08:00", EndTime: "08:00"}, "20:00", true},
+		{"malformed", &model.ScheduleRestriction{StartTime: "9am", EndTime: "17:00"}, "12:00", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			local, err := time.Parse("2006-01-02 15:04", "2026-03-03 "+tt.clock)
+			if err != nil {
+				t.Fatalf("parsing %q: %v", tt.clock, err)
+			}
+			if got := restrictionCovers(tt.restriction, local); got != tt.want {
+				t.Errorf("restrictionCovers() at %s = %v, want %v", tt.clock, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+	"github.com/hascho/go-incident-dashboard-api/internal/queue"
+	"github.com/hascho/go-incident-dashboard-api/internal/repository"
+	"github.com/rs/zerolog"
+)
+
+var ErrInvalidResponder = errors.New("invalid incident responder")
+
+type ResponderService interface {
+	GetResponders(ctx context.Context, incidentID string) ([]*model.IncidentResponder, error)
+	AssignResponder(ctx context.Context, incidentID string, actor string, req model.AssignResponderRequest) (*model.IncidentResponder, error)
+	RemoveResponder(ctx context.Context, incidentID string, responderID string, actor string) error
+}
+
+type responderService struct {
+	Repo         repository.ResponderRepository
+	IncidentRepo repository.IncidentRepository
+	JobRepo      repository.JobRepository
+	EventRepo    repository.EventRepository
+	WebhookRepo  repository.WebhookRepository
+	Tx           repository.Transactor
+	Queue        queue.TaskQueue
+	Logger       zerolog.Logger
+}
+
+func NewResponderService(repo repository.ResponderRepository, incidentRepo repository.IncidentRepository, jobRepo repository.JobRepository, eventRepo repository.EventRepository, webhookRepo repository.WebhookRepository, tx repository.Transactor, q queue.TaskQueue, logger zerolog.Logger) ResponderService {
+	return &responderService{
+		Repo:         repo,
+		IncidentRepo: incidentRepo,
+		JobRepo:      jobRepo,
+		EventRepo:    eventRepo,
+		WebhookRepo:  webhookRepo,
+		Tx:           tx,
+		Queue:        q,
+		Logger:       logger,
+	}
+}
+
+func (s *responderService) GetResponders(ctx context.Context, incidentID string) ([]*model.IncidentResponder, error) {
+	// an unknown incident is a 404 rather than an empty list
+	if _, err := s.IncidentRepo.GetIncidentByID(ctx, incidentID); err != nil {
+		return nil, err
+	}
+	return s.Repo.GetResponders(ctx, incidentID)
+}
+
+// AssignResponder puts a person on the incident in a role and tells them
+// through the worker. Assigning a new commander hands over command: the
+// previous commander comes off the incident and the assignee follows.
+// Re-sending an existing assignment changes nothing and notifies no one.
+func (s *responderService) AssignResponder(ctx context.Context, incidentID string, actor string, req model.AssignResponderRequest) (*model.IncidentResponder, error) {
+	role, responder, err := normalizeAssignment(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var assigned *model.IncidentResponder
+	var jobs []*repository.Job
+
+	err = s.Tx.WithinTx(ctx, func(tx *sql.Tx) error {
+		// lock the incident so concurrent assignments cannot leave two commanders
+		incident, err := s.IncidentRepo.WithTx(tx).GetIncidentByIDForUpdate(ctx, incidentID)
+		if err != nil {
+			return err
+		}
+
+		current, err := s.Repo.WithTx(tx).GetResponders(ctx, incidentID)
+		if err != nil {
+			return err
+		}
+		var previous, commander *model.IncidentResponder
+		for _, r := range current {
+			if r.Responder.Name == responder.Name {
+				previous = r
+			}
+			if r.Role == model.RoleCommander {
+				commander = r
+			}
+		}
+
+		if previous != nil && previous.Role == role && previous.Responder == responder {
+			assigned = previous
+			return nil
+		}
+
+		change := model.FieldChange{Field: roleLabel(role), To: responder.Name}
+		if role == model.RoleCommander && commander != nil && commander.Responder.Name != responder.Name {
+			if _, err := s.Repo.WithTx(tx).RemoveResponder(ctx, incidentID, commander.ID); err != nil {
+				return err
+			}
+			change.From = commander.Responder.Name
+		}
+
+		assigned, err = s.Repo.WithTx(tx).AssignResponder(ctx, &model.IncidentResponder{
+			IncidentID: incidentID,
+			Role:       role,
+			Responder:  responder,
+			AssignedBy: actor,
+		})
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case role == model.RoleCommander:
+			incident.Assignee = &responder.Name
+		case previous != nil && previous.Role == model.RoleCommander:
+			// the commander moved to another role and nobody commands now
+			incident.Assignee = nil
+		}
+		if err := s.IncidentRepo.WithTx(tx).SetAssignee(ctx, incidentID, incident.Assignee); err != nil {
+			return err
+		}
+
+		metadata, _ := json.Marshal(map[string]string{"name": responder.Name, "role": role})
+		err = s.EventRepo.WithTx(tx).CreateEvent(ctx, &model.IncidentEvent{
+			IncidentID: incidentID,
+			Type:       model.EventResponderAssigned,
+			Actor:      actor,
+			Message:    assignmentMessage(change),
+			Metadata:   metadata,
+		})
+		if err != nil {
+			return err
+		}
+
+		jobs, err = s.announceAssignment(ctx, tx, incident, assigned, change)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	publishJobs(ctx, s.Queue, s.Logger, jobs)
+
+	if len(jobs) > 0 {
+		s.Logger.Info().
+			Str("incident_id", incidentID).
+			Str("responder", assigned.Responder.Name).
+			Str("role", assigned.Role).
+			Str("actor", actor).
+			Msg("Responder assigned")
+	}
+	return assigned, nil
+}
+
+// announceAssignment notifies the newly assigned person directly, bypassing
+// routing rules, and delivers the event to subscribed webhooks.
+func (s *responderService) announceAssignment(ctx context.Context, tx *sql.Tx, incident *model.Incident, assigned *model.IncidentResponder, change model.FieldChange) ([]*repository.Job, error) {
+	eventType := model.EventTypeIncidentAssigned
+	changes := []model.FieldChange{change}
+	target := repository.JobTarget{Channel: assigned.Responder.Channel, Destination: assigned.Responder.Destination}
+
+	specs := notificationSpecs(eventType, incident, changes, []repository.JobTarget{target})
+	webhooks, err := webhookSpecs(ctx, s.WebhookRepo.WithTx(tx), eventType, incident, changes)
+	if err != nil {
+		return nil, err
+	}
+	specs = append(specs, webhooks...)
+
+	return createJobs(ctx, s.JobRepo.WithTx(tx), specs)
+}
+
+// RemoveResponder takes a responder off the incident. Removing the commander
+// leaves the incident unassigned.
+func (s *responderService) RemoveResponder(ctx context.Context, incidentID string, responderID string, actor string) error {
+	return s.Tx.WithinTx(ctx, func(tx *sql.Tx) error {
+		if _, err := s.IncidentRepo.WithTx(tx).GetIncidentByIDForUpdate(ctx, incidentID); err != nil {
+			return err
+		}
+
+		removed, err := s.Repo.WithTx(tx).RemoveResponder(ctx, incidentID, responderID)
+		if err != nil {
+			return err
+		}
+
+		if removed.Role == model.RoleCommander {
+			if err := s.IncidentRepo.WithTx(tx).SetAssignee(ctx, incidentID, nil); err != nil {
+				return err
+			}
+		}
+
+		metadata, _ := json.Marshal(map[string]string{"name": removed.Responder.Name, "role": removed.Role})
+		err = s.EventRepo.WithTx(tx).CreateEvent(ctx, &model.IncidentEvent{
+			IncidentID: incidentID,
+			Type:       model.EventResponderRemoved,
+			Actor:      actor,
+			Message:    fmt.Sprintf("%s removed as %s", removed.Responder.Name, roleLabel(removed.Role)),
+			Metadata:   metadata,
+		})
+		if err != nil {
+			return err
+		}
+
+		s.Logger.Info().
+			Str("incident_id", incidentID).
+			Str("responder", removed.Responder.Name).
+			Str("role", removed.Role).
+			Str("actor", actor).
+			Msg("Responder removed")
+		return nil
+	})
+}
+
+func normalizeAssignment(req model.AssignResponderRequest) (string, model.Responder, error) {
+	role := strings.ToLower(strings.TrimSpace(req.Role))
+	if !slices.Contains(model.ResponderRoles, role) {
+		return "", model.Responder{}, fmt.Errorf("%w: unknown role %q (expected one of %s)", ErrInvalidResponder, req.Role, strings.Join(model.ResponderRoles, ", "))
+	}
+
+	responder := req.Responder
+	responder.Name = strings.TrimSpace(responder.Name)
+	if responder.Name == "" {
+		return "", model.Responder{}, fmt.Errorf("%w: responder name cannot be empty", ErrInvalidResponder)
+	}
+	if !slices.Contains(routableChannels, responder.Channel) {
+		return "", model.Responder{}, fmt.Errorf("%w: responder %s: unsupported channel %q", ErrInvalidResponder, responder.Name, responder.Channel)
+	}
+	return role, responder, nil
+}
+
+// roleLabel is how a role reads in messages, e.g. "comms lead"
+func roleLabel(role string) string {
+	return strings.ReplaceAll(role, "_", " ")
+}
+
+func assignmentMessage(change model.FieldChange) string {
+	if change.From != "" {
+		return fmt.Sprintf("%s took over as %s from %s", change.To, change.Field, change.From)
+	}
+	return fmt.Sprintf("%s assigned as %s", change.To, change.Field)
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+	"github.com/hascho/go-incident-dashboard-api/internal/notifier"
+	"github.com/hascho/go-incident-dashboard-api/internal/repository"
+	"github.com/rs/zerolog"
+)
+
+var ErrInvalidRoutingRule = errors.New("invalid routing rule")
+
+// TargetSchedule is a pseudo channel for routing and escalation targets: the
+// destination is a schedule ID, and the job goes to whoever is on call for it
+// when the event is enqueued.
+const TargetSchedule = "schedule"
+
+// routableChannels are the notifier channels a target may use. Webhook jobs are
+// created from subscriptions, never from rules.
+var routableChannels = []string{
+	notifier.ChannelLog,
+	notifier.ChannelSlack,
+	notifier.ChannelEmail,
+	notifier.ChannelPagerDuty,
+}
+
+type RoutingService interface {
+	CreateRoutingRule(ctx context.Context, req model.RoutingRuleRequest) (*model.RoutingRule, error)
+	GetRoutingRuleByID(ctx context.Context, id string) (*model.RoutingRule, error)
+	GetAllRoutingRules(ctx context.Context) ([]*model.RoutingRule, error)
+	UpdateRoutingRule(ctx context.Context, id string, req model.RoutingRuleRequest) (*model.RoutingRule, error)
+	DeleteRoutingRule(ctx context.Context, id string) error
+}
+
+type routingService struct {
+	Repo     repository.RoutingRuleRepository
+	TeamRepo repository.TeamRepository
+	Logger   zerolog.Logger
+}
+
+func NewRoutingService(repo repository.RoutingRuleRepository, teamRepo repository.TeamRepository, logger zerolog.Logger) RoutingService {
+	return &routingService{
+		Repo:     repo,
+		TeamRepo: teamRepo,
+		Logger:   logger,
+	}
+}
+
+func (s *routingService) CreateRoutingRule(ctx context.Context, req model.RoutingRuleRequest) (*model.RoutingRule, error) {
+	rule, err := s.routingRuleFromRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.Repo.CreateRoutingRule(ctx, rule)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Logger.Info().Str("rule_id", created.ID).Str("name", created.Name).Msg("Routing rule created")
+	return created, nil
+}
+
+func (s *routingService) GetRoutingRuleByID(ctx context.Context, id string) (*model.RoutingRule, error) {
+	return s.Repo.GetRoutingRuleByID(ctx, id)
+}
+
+func (s *routingService) GetAllRoutingRules(ctx context.Context) ([]*model.RoutingRule, error) {
+	return s.Repo.GetAllRoutingRules(ctx)
+}
+
+// UpdateRoutingRule replaces every field of the rule with req
+func (s *routingService) UpdateRoutingRule(ctx context.Context, id string, req model.RoutingRuleRequest) (*model.RoutingRule, error) {
+	rule, err := s.routingRuleFromRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	rule.ID = id
+
+	updated, err := s.Repo.UpdateRoutingRule(ctx, rule)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Logger.Info().Str("rule_id", updated.ID).Str("name", updated.Name).Msg("Routing rule updated")
+	return updated, nil
+}
+
+func (s *routingService) DeleteRoutingRule(ctx context.Context, id string) error {
+	return s.Repo.DeleteRoutingRule(ctx, id)
+}
+
+// routingRuleFromRequest validates req and applies the defaults for omitted fields
+func (s *routingService) routingRuleFromRequest(ctx context.Context, req model.RoutingRuleRequest) (*model.RoutingRule, error) {
+	if req.TitlePattern != "" {
+		if _, err := regexp.Compile(req.TitlePattern); err != nil {
+			return nil, fmt.Errorf("%w: title_pattern: %v", ErrInvalidRoutingRule, err)
+		}
+	}
+
+	events := req.Events
+	if len(events) == 0 {
+		events = slices.Clone(model.IncidentEventTypes)
+	}
+	for _, event := range events {
+		if !slices.Contains(model.IncidentEventTypes, event) {
+			return nil, fmt.Errorf("%w: unknown event type %q", ErrInvalidRoutingRule, event)
+		}
+	}
+
+	for _, target := range req.Targets {
+		if err := validateTarget(target); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRoutingRule, err)
+		}
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	// no team matches every team; a named one is stored as the team's canonical name
+	team := strings.TrimSpace(req.Team)
+	if team != "" {
+		resolved, err := resolveTeam(ctx, s.TeamRepo, team)
+		if err != nil {
+			return nil, err
+		}
+		team = resolved.Name
+	}
+
+	return &model.RoutingRule{
+		Name:         req.Name,
+		Team:         team,
+		Severity:     req.Severity,
+		TitlePattern: req.TitlePattern,
+		Tags:         req.Tags,
+		Events:       events,
+		Targets:      req.Targets,
+		Enabled:      enabled,
+	}, nil
+}
+
+// validateTarget checks that target names a notifier channel, or a schedule by ID
+func validateTarget(target model.RoutingTarget) error {
+	if target.Channel == TargetSchedule {
+		if _, err := uuid.Parse(target.Destination); err != nil {
+			return fmt.Errorf("schedule target destination %q is not a schedule ID", target.Destination)
+		}
+		return nil
+	}
+	if !slices.Contains(routableChannels, target.Channel) {
+		return fmt.Errorf("unknown channel %q, expected one of %s or %s", target.Channel, strings.Join(routableChannels, ", "), TargetSchedule)
+	}
+	return nil
+}
+
+// routingRuleMatches reports whether incident satisfies every condition of rule.
+// Both carry canonical team names; severity compares case-insensitively and
+// every rule tag must be present on the incident.
+func routingRuleMatches(rule *model.RoutingRule, incident *model.Incident) bool {
+	if rule.Team != "" && rule.Team != incident.Team {
+		return false
+	}
+	if rule.Severity != "" && !strings.EqualFold(rule.Severity, incident.Severity) {
+		return false
+	}
+	if rule.TitlePattern != "" {
+		// patterns are validated on write, so a failure here means a hand-edited row
+		pattern, err := regexp.Compile(rule.TitlePattern)
+		if err != nil || !pattern.MatchString(incident.Title) {
+			return false
+		}
+	}
+	for _, tag := range rule.Tags {
+		if !slices.Contains(incident.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+	"github.com/hascho/go-incident-dashboard-api/internal/repository"
+	"github.com/rs/zerolog"
+)
+
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+type ScheduleService interface {
+	CreateSchedule(ctx context.Context, req model.ScheduleRequest) (*model.Schedule, error)
+	GetScheduleByID(ctx context.Context, id string) (*model.Schedule, error)
+	GetAllSchedules(ctx context.Context) ([]*model.Schedule, error)
+	UpdateSchedule(ctx context.Context, id string, req model.ScheduleRequest) (*model.Schedule, error)
+	DeleteSchedule(ctx context.Context, id string) error
+	CreateOverride(ctx context.Context, scheduleID string, req model.CreateOverrideRequest) (*model.ScheduleOverride, error)
+	GetUpcomingOverrides(ctx context.Context, scheduleID string) ([]*model.ScheduleOverride, error)
+	DeleteOverride(ctx context.Context, scheduleID string, overrideID string) error
+	GetOnCall(ctx context.Context, scheduleID string, at time.Time) (*model.OnCall, error)
+}
+
+type scheduleService struct {
+	Repo     repository.ScheduleRepository
+	TeamRepo repository.TeamRepository
+	Logger   zerolog.Logger
+}
+
+func NewScheduleService(repo repository.ScheduleRepository, teamRepo repository.TeamRepository, logger zerolog.Logger) ScheduleService {
+	return &scheduleService{
+		Repo:     repo,
+		TeamRepo: teamRepo,
+		Logger:   logger,
+	}
+}
+
+func (s *scheduleService) CreateSchedule(ctx context.Context, req model.ScheduleRequest) (*model.Schedule, error) {
+	schedule, err := s.scheduleFromRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.Repo.CreateSchedule(ctx, schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Logger.Info().Str("schedule_id", created.ID).Str("team", created.Team).Msg("Schedule created")
+	return created, nil
+}
+
+func (s *scheduleService) GetScheduleByID(ctx context.Context, id string) (*model.Schedule, error) {
+	return s.Repo.GetScheduleByID(ctx, id)
+}
+
+func (s *scheduleService) GetAllSchedules(ctx context.Context) ([]*model.Schedule, error) {
+	return s.Repo.GetAllSchedules(ctx)
+}
+
+// UpdateSchedule replaces every field of the schedule with req; overrides are kept
+func (s *scheduleService) UpdateSchedule(ctx context.Context, id string, req model.ScheduleRequest) (*model.Schedule, error) {
+	schedule, err := s.scheduleFromRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	schedule.ID = id
+
+	updated, err := s.Repo.UpdateSchedule(ctx, schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Logger.Info().Str("schedule_id", updated.ID).Str("team", updated.Team).Msg("Schedule updated")
+	return updated, nil
+}
+
+func (s *scheduleService) DeleteSchedule(ctx context.Context, id string) error {
+	return s.Repo.DeleteSchedule(ctx, id)
+}
+
+func (s *scheduleService) CreateOverride(ctx context.Context, scheduleID string, req model.CreateOverrideRequest) (*model.ScheduleOverride, error) {
+	if !req.EndsAt.After(req.StartsAt) {
+		return nil, fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidSchedule)
+	}
+	if err := validateResponder(req.Responder); err != nil {
+		return nil, err
+	}
+
+	// surface a missing schedule as a 404 rather than a foreign key error
+	if _, err := s.Repo.GetScheduleByID(ctx, scheduleID); err != nil {
+		return nil, err
+	}
+
+	created, err := s.Repo.CreateOverride(ctx, &model.ScheduleOverride{
+		ScheduleID: scheduleID,
+		Responder:  req.Responder,
+		StartsAt:   req.StartsAt,
+		EndsAt:     req.EndsAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.Logger.Info().
+		Str("schedule_id", scheduleID).
+		Str("responder", created.Responder.Name).
+		Time("starts_at", created.StartsAt).
+		Time("ends_at", created.EndsAt).
+		Msg("Schedule override created")
+	return created, nil
+}
+
+func (s *scheduleService) GetUpcomingOverrides(ctx context.Context, scheduleID string) ([]*model.ScheduleOverride, error) {
+	if _, err := s.Repo.GetScheduleByID(ctx, scheduleID); err != nil {
+		return nil, err
+	}
+	return s.Repo.GetUpcomingOverrides(ctx, scheduleID)
+}
+
+func (s *scheduleService) DeleteOverride(ctx context.Context, scheduleID string, overrideID string) error {
+	return s.Repo.DeleteOverride(ctx, scheduleID, overrideID)
+}
+
+// GetOnCall returns who is on call for the schedule at the given moment
+func (s *scheduleService) GetOnCall(ctx context.Context, scheduleID string, at time.Time) (*model.OnCall, error) {
+	return onCallAt(ctx, s.Repo, scheduleID, at)
+}
+
+// onCallAt loads the schedule and its active override and resolves the responder
+func onCallAt(ctx context.Context, repo repository.ScheduleRepository, scheduleID string, at time.Time) (*model.OnCall, error) {
+	schedule, err := repo.GetScheduleByID(ctx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	override, err := repo.GetActiveOverride(ctx, scheduleID, at)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	return resolveOnCall(schedule, override, at)
+}
+
+// scheduleFromRequest validates req and resolves its team to the team's
+// canonical name
+func (s *scheduleService) scheduleFromRequest(ctx context.Context, req model.ScheduleRequest) (*model.Schedule, error) {
+	if _, err := time.LoadLocation(req.TimeZone); err != nil || req.TimeZone == "" {
+		return nil, fmt.Errorf("%w: unknown time_zone %q", ErrInvalidSchedule, req.TimeZone)
+	}
+
+	for i, layer := range req.Layers {
+		if _, err := time.Parse(dateLayout, layer.StartDate); err != nil {
+			return nil, fmt.Errorf("%w: layer %d: start_date must be YYYY-MM-DD", ErrInvalidSchedule, i+1)
+		}
+		if _, err := time.Parse(clockLayout, layer.HandoffTime); err != nil {
+			return nil, fmt.Errorf("%w: layer %d: handoff_time must be HH:MM", ErrInvalidSchedule, i+1)
+		}
+		if r := layer.Restriction; r != nil {
+			_, err1 := time.Parse(clockLayout, r.StartTime)
+			_, err2 := time.Parse(clockLayout, r.EndTime)
+			if err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("%w: layer %d: restriction times must be HH:MM", ErrInvalidSchedule, i+1)
+			}
+		}
+		for _, participant := range layer.Participants {
+			if err := validateResponder(participant); err != nil {
+				return nil, fmt.Errorf("layer %d: %w", i+1, err)
+			}
+		}
+	}
+
+	team, err := resolveTeam(ctx, s.TeamRepo, req.Team)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Schedule{
+		Name:     req.Name,
+		Team:     team.Name,
+		TimeZone: req.TimeZone,
+		Layers:   req.Layers,
+	}, nil
+}
+
+// validateResponder checks that a responder is reached over a notifier channel
+func validateResponder(responder model.Responder) error {
+	if !slices.Contains(routableChannels, responder.Channel) {
+		return fmt.Errorf("%w: responder %s: unsupported channel %q", ErrInvalidSchedule, responder.Name, responder.Channel)
+	}
+	return nil
+}
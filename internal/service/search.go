@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+)
+
+var ErrInvalidSearch = errors.New("invalid incident search")
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// SearchIncidents runs a full-text search. q is free text in web search
+// syntax ("quoted phrases", or, -excluded) plus team:, severity: and status:
+// qualifiers, e.g. `"redis timeout" team:devops severity:high,critical`.
+func (s *incidentService) SearchIncidents(ctx context.Context, q string, limit int) ([]*model.IncidentSearchHit, error) {
+	search, err := parseSearchQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case limit == 0:
+		search.Limit = defaultSearchLimit
+	case limit < 0 || limit > maxSearchLimit:
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidSearch, maxSearchLimit)
+	default:
+		search.Limit = limit
+	}
+
+	// a known team is matched whatever its spelling; an unknown one matches nothing
+	if search.Team != "" {
+		team, err := resolveTeam(ctx, s.TeamRepo, search.Team)
+		if err != nil && !errors.Is(err, ErrUnknownTeam) {
+			return nil, err
+		}
+		if team != nil {
+			search.Team = team.Name
+		}
+	}
+
+	return s.Repo.SearchIncidents(ctx, search)
+}
+
+// parseSearchQuery pulls the qualifiers out of q and leaves the rest, quotes
+// and operators included, as the text to match.
+func parseSearchQuery(q string) (model.IncidentSearch, error) {
+	var search model.IncidentSearch
+	var text []string
+
+	for _, token := range splitSearchTokens(q) {
+		key, value, ok := strings.Cut(token, ":")
+		value = strings.Trim(value, `"`)
+		if !ok || value == "" {
+			text = append(text, token)
+			continue
+		}
+
+		switch strings.ToLower(key) {
+		case "team":
+			search.Team = value
+		case "severity":
+			search.Severities = append(search.Severities, strings.Split(value, ",")...)
+		case "status":
+			for _, status := range strings.Split(value, ",") {
+				normalized, err := normalizeStatus(status)
+				if err != nil {
+					return search, fmt.Errorf("%w: %v", ErrInvalidSearch, err)
+				}
+				search.Statuses = append(search.Statuses, normalized)
+			}
+		default:
+			// not a qualifier we know, e.g. a host:port in the text
+			text = append(text, token)
+		}
+	}
+
+	search.Text = strings.Join(text, " ")
+	if strings.Trim(search.Text, `"- `) == "" {
+		return search, fmt.Errorf("%w: q needs at least one search term besides qualifiers", ErrInvalidSearch)
+	}
+	return search, nil
+}
+
+// splitSearchTokens splits q on whitespace outside double quotes, keeping the
+// quotes so phrases reach websearch_to_tsquery intact.
+func splitSearchTokens(q string) []string {
+	var tokens []string
+	var current strings.Builder
+	inQuotes := false
+
+	for _, r := range q {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			current.WriteRune(r)
+		case unicode.IsSpace(r) && !inQuotes:
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
@@ -0,0 +1,412 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+	"github.com/hascho/go-incident-dashboard-api/internal/probe"
+	"github.com/hascho/go-incident-dashboard-api/internal/repository"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrInvalidSyntheticCheck  = errors.New("invalid synthetic check")
+	ErrSyntheticCheckConflict = errors.New("synthetic check already exists")
+)
+
+const (
+	// syntheticCheckActor is recorded as the actor of incidents opened and resolved by synthetic checks
+	syntheticCheckActor = "synthetic-check"
+	// minSyntheticCheckInterval keeps checks from hammering their target
+	minSyntheticCheckInterval = 10
+	// maxSyntheticCheckTimeout bounds how long one run can hold up a batch
+	maxSyntheticCheckTimeout     = 60
+	defaultSyntheticCheckTimeout = 10
+	defaultFailureThreshold      = 3
+	// syntheticResultRetention is how long results are kept
+	syntheticResultRetention = 30 * 24 * time.Hour
+)
+
+type SyntheticCheckService interface {
+	CreateSyntheticCheck(ctx context.Context, req model.SyntheticCheckRequest) (*model.SyntheticCheck, error)
+	GetSyntheticCheckByID(ctx context.Context, id string) (*model.SyntheticCheck, error)
+	GetAllSyntheticChecks(ctx context.Context) ([]*model.SyntheticCheck, error)
+	UpdateSyntheticCheck(ctx context.Context, id string, req model.SyntheticCheckRequest) (*model.SyntheticCheck, error)
+	DeleteSyntheticCheck(ctx context.Context, id string) error
+	GetResults(ctx context.Context, id string, since time.Time, limit int) ([]*model.SyntheticCheckResult, error)
+	RunDue(ctx context.Context, limit int) (int, error)
+}
+
+type syntheticCheckService struct {
+	Repo      repository.SyntheticCheckRepository
+	TeamRepo  repository.TeamRepository
+	Incidents incidentWriter
+	Tx        repository.Transactor
+	Prober    *probe.Prober
+	Logger    zerolog.Logger
+}
+
+func NewSyntheticCheckService(repo repository.SyntheticCheckRepository, teamRepo repository.TeamRepository, incidents incidentWriter, tx repository.Transactor, prober *probe.Prober, logger zerolog.Logger) SyntheticCheckService {
+	return &syntheticCheckService{
+		Repo:      repo,
+		TeamRepo:  teamRepo,
+		Incidents: incidents,
+		Tx:        tx,
+		Prober:    prober,
+		Logger:    logger,
+	}
+}
+
+func (s *syntheticCheckService) CreateSyntheticCheck(ctx context.Context, req model.SyntheticCheckRequest) (*model.SyntheticCheck, error) {
+	check, err := s.syntheticCheckFromRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.Repo.GetSyntheticCheckByName(ctx, check.Name); err == nil {
+		return nil, fmt.Errorf("%w: %q", ErrSyntheticCheckConflict, check.Name)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	created, err := s.Repo.CreateSyntheticCheck(ctx, check)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Logger.Info().Str("check_id", created.ID).Str("check", created.Name).Msg("Synthetic check created")
+	return created, nil
+}
+
+func (s *syntheticCheckService) GetSyntheticCheckByID(ctx context.Context, id string) (*model.SyntheticCheck, error) {
+	return s.Repo.GetSyntheticCheckByID(ctx, id)
+}
+
+func (s *syntheticCheckService) GetAllSyntheticChecks(ctx context.Context) ([]*model.SyntheticCheck, error) {
+	return s.Repo.GetAllSyntheticChecks(ctx)
+}
+
+// UpdateSyntheticCheck replaces the check's definition. The check runs again
+// straight away; its failure streak carries over.
+func (s *syntheticCheckService) UpdateSyntheticCheck(ctx context.Context, id string, req model.SyntheticCheckRequest) (*model.SyntheticCheck, error) {
+	check, err := s.syntheticCheckFromRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	check.ID = id
+
+	existing, err := s.Repo.GetSyntheticCheckByName(ctx, check.Name)
+	if err == nil && existing.ID != id {
+		return nil, fmt.Errorf("%w: %q", ErrSyntheticCheckConflict, check.Name)
+	} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	updated, err := s.Repo.UpdateSyntheticCheck(ctx, check)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Logger.Info().Str("check_id", updated.ID).Str("check", updated.Name).Msg("Synthetic check updated")
+	return updated, nil
+}
+
+// DeleteSyntheticCheck removes the check and its results. An incident it
+// opened stays open.
+func (s *syntheticCheckService) DeleteSyntheticCheck(ctx context.Context, id string) error {
+	return s.Repo.DeleteSyntheticCheck(ctx, id)
+}
+
+// GetResults returns up to limit of the check's results since the given
+// time, newest first
+func (s *syntheticCheckService) GetResults(ctx context.Context, id string, since time.Time, limit int) ([]*model.SyntheticCheckResult, error) {
+	if _, err := s.Repo.GetSyntheticCheckByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.Repo.GetSyntheticResults(ctx, id, since, limit)
+}
+
+// RunDue runs up to limit checks that are due, in parallel, and returns how
+// many ran. Claiming moves each check's next run on first, so several
+// schedulers never run one check twice in an interval.
+func (s *syntheticCheckService) RunDue(ctx context.Context, limit int) (int, error) {
+	due, err := s.Repo.ClaimDueSyntheticChecks(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for _, check := range due {
+		wg.Add(1)
+		go func(check *model.SyntheticCheck) {
+			defer wg.Done()
+			s.runCheck(ctx, check)
+		}(check)
+	}
+	wg.Wait()
+
+	return len(due), nil
+}
+
+// runCheck probes the check, records the result and opens or resolves its
+// incident. The incident is acted on from the recorded state rather than the
+// transition, so a run that failed to open or resolve one is retried by the
+// next run.
+func (s *syntheticCheckService) runCheck(ctx context.Context, check *model.SyntheticCheck) {
+	result := s.Prober.Probe(ctx, check)
+	// a run cut short by shutdown says nothing about the target
+	if ctx.Err() != nil {
+		return
+	}
+
+	updated, err := s.recordResult(ctx, result)
+	if err != nil {
+		// a check deleted while it ran has nothing left to record
+		if !errors.Is(err, sql.ErrNoRows) {
+			s.Logger.Error().Err(err).Str("check_id", check.ID).Msg("Failed to record synthetic check result")
+		}
+		return
+	}
+
+	if err := s.Repo.PruneSyntheticResults(ctx, check.ID, time.Now().Add(-syntheticResultRetention)); err != nil {
+		s.Logger.Error().Err(err).Str("check_id", check.ID).Msg("Failed to prune synthetic check results")
+	}
+
+	switch {
+	case updated.Status == model.SyntheticCheckDown && updated.IncidentID == nil:
+		s.openIncident(ctx, updated.ID, result)
+	case updated.Status == model.SyntheticCheckUp && updated.IncidentID != nil:
+		s.resolveIncident(ctx, updated.ID)
+	}
+}
+
+// recordResult stores a run's result and moves the check's failure streak on.
+// The check is locked while it does, so a check deleted during the run is
+// reported as sql.ErrNoRows rather than failing the insert.
+func (s *syntheticCheckService) recordResult(ctx context.Context, result *model.SyntheticCheckResult) (*model.SyntheticCheck, error) {
+	var updated *model.SyntheticCheck
+	err := s.Tx.WithinTx(ctx, func(tx *sql.Tx) error {
+		repo := s.Repo.WithTx(tx)
+		check, err := repo.GetSyntheticCheckByIDForUpdate(ctx, result.CheckID)
+		if err != nil {
+			return err
+		}
+
+		recordRun(check, result.Success)
+		updated, err = repo.RecordSyntheticResult(ctx, check, result)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// recordRun moves the check's failure streak on by one run. A success marks
+// the check up; a failure that reaches the failure threshold marks it down.
+func recordRun(check *model.SyntheticCheck, success bool) {
+	if success {
+		check.ConsecutiveFailures = 0
+		check.Status = model.SyntheticCheckUp
+		return
+	}
+	check.ConsecutiveFailures++
+	if check.ConsecutiveFailures >= check.FailureThreshold {
+		check.Status = model.SyntheticCheckDown
+	}
+}
+
+// openIncident opens the incident for a check that went down and links it in
+// the same transaction, holding the check's row lock, so no other run can
+// open a second one in between
+func (s *syntheticCheckService) openIncident(ctx context.Context, id string, result *model.SyntheticCheckResult) {
+	var incident *model.Incident
+	var jobs []*repository.Job
+	err := s.Tx.WithinTx(ctx, func(tx *sql.Tx) error {
+		repo := s.Repo.WithTx(tx)
+		check, err := repo.GetSyntheticCheckByIDForUpdate(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// another run has already opened one, or the check recovered
+		if check.Status != model.SyntheticCheckDown || check.IncidentID != nil {
+			return nil
+		}
+
+		incident, jobs, err = s.Incidents.createIncident(ctx, tx, syntheticCheckActor, syntheticCheckIncident(check, result))
+		if err != nil {
+			return err
+		}
+		return repo.SetSyntheticCheckIncident(ctx, check.ID, &incident.ID)
+	})
+	if err != nil {
+		s.Logger.Error().Err(err).Str("check_id", id).Msg("Failed to open incident for failing synthetic check")
+		return
+	}
+	s.Incidents.publish(ctx, jobs)
+
+	if incident != nil {
+		s.Logger.Warn().Str("check_id", id).Str("incident_id", incident.ID).Msg("Synthetic check down")
+	}
+}
+
+// resolveIncident resolves the incident the check opened, unless someone has
+// already moved that incident on, and unlinks it in the same transaction
+func (s *syntheticCheckService) resolveIncident(ctx context.Context, id string) {
+	var resolved string
+	var jobs []*repository.Job
+	err := s.Tx.WithinTx(ctx, func(tx *sql.Tx) error {
+		repo := s.Repo.WithTx(tx)
+		check, err := repo.GetSyntheticCheckByIDForUpdate(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if check.Status != model.SyntheticCheckUp || check.IncidentID == nil {
+			return nil
+		}
+
+		status, reason := model.StatusResolved, "Synthetic check passing again"
+		_, jobs, err = s.Incidents.updateIncident(ctx, tx, *check.IncidentID, syntheticCheckActor, model.UpdateIncidentRequest{Status: &status, Reason: &reason})
+		if err != nil && !errors.Is(err, ErrInvalidTransition) && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		resolved = *check.IncidentID
+		return repo.SetSyntheticCheckIncident(ctx, check.ID, nil)
+	})
+	if err != nil {
+		s.Logger.Error().Err(err).Str("check_id", id).Msg("Failed to resolve incident of recovered synthetic check")
+		return
+	}
+	s.Incidents.publish(ctx, jobs)
+
+	if resolved != "" {
+		s.Logger.Info().Str("check_id", id).Str("incident_id", resolved).Msg("Synthetic check recovered")
+	}
+}
+
+// syntheticCheckIncident is the incident opened when a check reaches its
+// failure threshold. Its fingerprint is the check's, so with grouping enabled
+// a check that goes down again while its last incident is active adds an
+// occurrence to it.
+func syntheticCheckIncident(check *model.SyntheticCheck, result *model.SyntheticCheckResult) *model.Incident {
+	return &model.Incident{
+		Title: fmt.Sprintf("Synthetic check %s failing", check.Name),
+		Description: fmt.Sprintf("%s failed %d times in a row (threshold %d). Last error: %s",
+			syntheticCheckTarget(check), check.ConsecutiveFailures, check.FailureThreshold, result.ErrorMessage),
+		Status:      model.StatusOpen,
+		Severity:    check.Severity,
+		Team:        check.Team,
+		Tags:        []string{syntheticCheckActor},
+		Fingerprint: "synthetic-check:" + check.ID,
+	}
+}
+
+// syntheticCheckTarget describes what the check probes, e.g. "GET https://example.com/health"
+func syntheticCheckTarget(check *model.SyntheticCheck) string {
+	if check.Type == model.SyntheticCheckTCP && check.Port != nil {
+		return "tcp://" + net.JoinHostPort(check.Host, strconv.Itoa(*check.Port))
+	}
+	return check.Method + " " + check.URL
+}
+
+func (s *syntheticCheckService) syntheticCheckFromRequest(ctx context.Context, req model.SyntheticCheckRequest) (*model.SyntheticCheck, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name cannot be empty", ErrInvalidSyntheticCheck)
+	}
+
+	check := &model.SyntheticCheck{Name: name, Type: req.Type}
+	switch req.Type {
+	case model.SyntheticCheckHTTP:
+		if req.Host != "" || req.Port != nil {
+			return nil, fmt.Errorf("%w: host and port only apply to tcp checks", ErrInvalidSyntheticCheck)
+		}
+		target, err := url.Parse(strings.TrimSpace(req.URL))
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return nil, fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidSyntheticCheck)
+		}
+		check.URL = target.String()
+
+		check.Method = strings.ToUpper(strings.TrimSpace(req.Method))
+		switch check.Method {
+		case "":
+			check.Method = http.MethodGet
+		case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		default:
+			return nil, fmt.Errorf("%w: unsupported method %q", ErrInvalidSyntheticCheck, req.Method)
+		}
+
+		if req.ExpectedStatus != nil && (*req.ExpectedStatus < 100 || *req.ExpectedStatus > 599) {
+			return nil, fmt.Errorf("%w: expected_status must be between 100 and 599", ErrInvalidSyntheticCheck)
+		}
+		check.ExpectedStatus = req.ExpectedStatus
+		check.BodyContains = req.BodyContains
+
+	case model.SyntheticCheckTCP:
+		if req.URL != "" || req.Method != "" || req.ExpectedStatus != nil || req.BodyContains != "" {
+			return nil, fmt.Errorf("%w: url, method, expected_status and body_contains only apply to http checks", ErrInvalidSyntheticCheck)
+		}
+		check.Host = strings.TrimSpace(req.Host)
+		if check.Host == "" {
+			return nil, fmt.Errorf("%w: host cannot be empty", ErrInvalidSyntheticCheck)
+		}
+		if req.Port == nil || *req.Port < 1 || *req.Port > 65535 {
+			return nil, fmt.Errorf("%w: port must be between 1 and 65535", ErrInvalidSyntheticCheck)
+		}
+		check.Port = req.Port
+
+	default:
+		return nil, fmt.Errorf("%w: type must be http or tcp", ErrInvalidSyntheticCheck)
+	}
+
+	if req.IntervalSeconds < minSyntheticCheckInterval {
+		return nil, fmt.Errorf("%w: interval_seconds must be at least %d", ErrInvalidSyntheticCheck, minSyntheticCheckInterval)
+	}
+	check.IntervalSeconds = req.IntervalSeconds
+
+	// a run must finish before the next one is due
+	check.TimeoutSeconds = req.TimeoutSeconds
+	if check.TimeoutSeconds == 0 {
+		check.TimeoutSeconds = min(defaultSyntheticCheckTimeout, check.IntervalSeconds-1)
+	}
+	if check.TimeoutSeconds < 1 || check.TimeoutSeconds > maxSyntheticCheckTimeout || check.TimeoutSeconds >= check.IntervalSeconds {
+		return nil, fmt.Errorf("%w: timeout_seconds must be between 1 and %d and less than interval_seconds", ErrInvalidSyntheticCheck, maxSyntheticCheckTimeout)
+	}
+
+	check.FailureThreshold = req.FailureThreshold
+	if check.FailureThreshold == 0 {
+		check.FailureThreshold = defaultFailureThreshold
+	}
+	if check.FailureThreshold < 1 {
+		return nil, fmt.Errorf("%w: failure_threshold must be positive", ErrInvalidSyntheticCheck)
+	}
+
+	team, err := resolveTeam(ctx, s.TeamRepo, req.Team)
+	if err != nil {
+		return nil, err
+	}
+	check.Team = team.Name
+
+	check.Severity = strings.TrimSpace(req.Severity)
+	if check.Severity == "" {
+		check.Severity = defaultAlertSeverity
+	}
+
+	return check, nil
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+	"github.com/hascho/go-incident-dashboard-api/internal/probe"
+	"github.com/hascho/go-incident-dashboard-api/internal/repository"
+	"github.com/rs/zerolog"
+)
+
+// fakeSyntheticRepo keeps one check in memory. Methods the service does not
+// call during a run are left to the embedded nil interface.
+type fakeSyntheticRepo struct {
+	repository.SyntheticCheckRepository
+	mu      sync.Mutex
+	check   *model.SyntheticCheck // nil once deleted
+	results []*model.SyntheticCheckResult
+}
+
+func (r *fakeSyntheticRepo) WithTx(tx *sql.Tx) repository.SyntheticCheckRepository {
+	return r
+}
+
+func (r *fakeSyntheticRepo) GetSyntheticCheckByIDForUpdate(ctx context.Context, id string) (*model.SyntheticCheck, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.check == nil || r.check.ID != id {
+		return nil, sql.ErrNoRows
+	}
+	copied := *r.check
+	return &copied, nil
+}
+
+func (r *fakeSyntheticRepo) RecordSyntheticResult(ctx context.Context, check *model.SyntheticCheck, result *model.SyntheticCheckResult) (*model.SyntheticCheck, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.check == nil {
+		return nil, sql.ErrNoRows
+	}
+	r.check.ConsecutiveFailures = check.ConsecutiveFailures
+	r.check.Status = check.Status
+	r.check.LastCheckedAt = &result.CheckedAt
+	r.results = append(r.results, result)
+	copied := *r.check
+	return &copied, nil
+}
+
+func (r *fakeSyntheticRepo) SetSyntheticCheckIncident(ctx context.Context, id string, incidentID *string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.check != nil {
+		r.check.IncidentID = incidentID
+	}
+	return nil
+}
+
+func (r *fakeSyntheticRepo) PruneSyntheticResults(ctx context.Context, checkID string, before time.Time) error {
+	return nil
+}
+
+func (r *fakeSyntheticRepo) current() model.SyntheticCheck {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return *r.check
+}
+
+// fakeTransactor runs the unit of work without a database
+type fakeTransactor struct{}
+
+func (fakeTransactor) WithinTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	return fn(nil)
+}
+
+// fakeIncidents records the incidents opened and the status changes made
+type fakeIncidents struct {
+	mu       sync.Mutex
+	opened   []*model.Incident
+	resolved []string
+}
+
+func (f *fakeIncidents) createIncident(ctx context.Context, tx *sql.Tx, actor string, incident *model.Incident) (*model.Incident, []*repository.Job, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	incident.ID = fmt.Sprintf("incident-%d", len(f.opened)+1)
+	f.opened = append(f.opened, incident)
+	return incident, nil, nil
+}
+
+func (f *fakeIncidents) updateIncident(ctx context.Context, tx *sql.Tx, incidentID string, actor string, req model.UpdateIncidentRequest) (*model.Incident, []*repository.Job, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if req.Status != nil && *req.Status == model.StatusResolved {
+		f.resolved = append(f.resolved, incidentID)
+	}
+	return &model.Incident{ID: incidentID}, nil, nil
+}
+
+func (f *fakeIncidents) publish(ctx context.Context, jobs []*repository.Job) {}
+
+func TestRunCheckOpensAndResolvesAtThreshold(t *testing.T) {
+	var healthy atomic.Bool
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer target.Close()
+
+	check := &model.SyntheticCheck{
+		ID:               "check-1",
+		Name:             "checkout",
+		Type:             model.SyntheticCheckHTTP,
+		URL:              target.URL,
+		Method:           http.MethodGet,
+		TimeoutSeconds:   5,
+		FailureThreshold: 2,
+		Team:             "payments",
+		Severity:         "high",
+		Status:           model.SyntheticCheckNew,
+	}
+	repo := &fakeSyntheticRepo{check: check}
+	incidents := &fakeIncidents{}
+	s := &syntheticCheckService{
+		Repo:      repo,
+		Incidents: incidents,
+		Tx:        fakeTransactor{},
+		Prober:    probe.NewProber(),
+		Logger:    zerolog.Nop(),
+	}
+
+	steps := []struct {
+		healthy  bool
+		status   string
+		failures int
+		opened   int
+		resolved int
+		linked   bool
+	}{
+		{healthy: true, status: model.SyntheticCheckUp},
+		{healthy: false, status: model.SyntheticCheckUp, failures: 1},
+		{healthy: false, status: model.SyntheticCheckDown, failures: 2, opened: 1, linked: true},
+		{healthy: false, status: model.SyntheticCheckDown, failures: 3, opened: 1, linked: true},
+		{healthy: true, status: model.SyntheticCheckUp, opened: 1, resolved: 1},
+		{healthy: false, status: model.SyntheticCheckUp, failures: 1, opened: 1, resolved: 1},
+	}
+
+	for i, step := range steps {
+		healthy.Store(step.healthy)
+		claimed := repo.current()
+		s.runCheck(context.Background(), &claimed)
+
+		got := repo.current()
+		if got.Status != step.status || got.ConsecutiveFailures != step.failures {
+			t.Errorf("run %d: status %s with %d failures, want %s with %d", i+1, got.Status, got.ConsecutiveFailures, step.status, step.failures)
+		}
+		if len(incidents.opened) != step.opened || len(incidents.resolved) != step.resolved {
+			t.Errorf("run %d: %d incidents opened and %d resolved, want %d and %d", i+1, len(incidents.opened), len(incidents.resolved), step.opened, step.resolved)
+		}
+		if (got.IncidentID != nil) != step.linked {
+			t.Errorf("run %d: incident_id = %v, want linked %v", i+1, got.IncidentID, step.linked)
+		}
+	}
+
+	if len(repo.results) != len(steps) {
+		t.Errorf("recorded %d results, want %d", len(repo.results), len(steps))
+	}
+	opened := incidents.opened[0]
+	if opened.Team != "payments" || opened.Severity != "high" || opened.Fingerprint != "synthetic-check:check-1" {
+		t.Errorf("opened incident = %+v", opened)
+	}
+	if incidents.resolved[0] != opened.ID {
+		t.Errorf("resolved %s, want the incident the check opened (%s)", incidents.resolved[0], opened.ID)
+	}
+}
+
+func TestRunCheckIgnoresDeletedCheck(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer target.Close()
+
+	repo := &fakeSyntheticRepo{}
+	incidents := &fakeIncidents{}
+	s := &syntheticCheckService{
+		Repo:      repo,
+		Incidents: incidents,
+		Tx:        fakeTransactor{},
+		Prober:    probe.NewProber(),
+		Logger:    zerolog.Nop(),
+	}
+
+	// claimed, then deleted while it ran
+	s.runCheck(context.Background(), &model.SyntheticCheck{
+		ID:               "check-1",
+		Type:             model.SyntheticCheckHTTP,
+		URL:              target.URL,
+		Method:           http.MethodGet,
+		TimeoutSeconds:   5,
+		FailureThreshold: 1,
+	})
+
+	if len(repo.results) != 0 || len(incidents.opened) != 0 {
+		t.Errorf("recorded %d results and opened %d incidents for a deleted check", len(repo.results), len(incidents.opened))
+	}
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+	"github.com/hascho/go-incident-dashboard-api/internal/repository"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrInvalidTeam  = errors.New("invalid team")
+	ErrTeamConflict = errors.New("team already exists")
+	ErrTeamInUse    = errors.New("team still owns incidents, heartbeats, synthetic checks, escalation policies, routing rules or schedules")
+	ErrUnknownTeam  = errors.New("unknown team")
+	ErrUnknownUser  = errors.New("unknown user")
+)
+
+type TeamService interface {
+	CreateTeam(ctx context.Context, req model.TeamRequest) (*model.Team, error)
+	GetTeamByID(ctx context.Context, id string) (*model.Team, error)
+	GetAllTeams(ctx context.Context) ([]*model.Team, error)
+	UpdateTeam(ctx context.Context, id string, req model.TeamRequest) (*model.Team, error)
+	DeleteTeam(ctx context.Context, id string) error
+	GetMembers(ctx context.Context, teamID string) ([]*model.User, error)
+	AddMember(ctx context.Context, teamID string, userID string) error
+	RemoveMember(ctx context.Context, teamID string, userID string) error
+}
+
+type teamService struct {
+	Repo     repository.TeamRepository
+	UserRepo repository.UserRepository
+	Logger   zerolog.Logger
+}
+
+func NewTeamService(repo repository.TeamRepository, userRepo repository.UserRepository, logger zerolog.Logger) TeamService {
+	return &teamService{
+		Repo:     repo,
+		UserRepo: userRepo,
+		Logger:   logger,
+	}
+}
+
+func (s *teamService) CreateTeam(ctx context.Context, req model.TeamRequest) (*model.Team, error) {
+	team, err := teamFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing, err := s.Repo.GetTeamBySlug(ctx, team.Slug); err == nil {
+		return nil, fmt.Errorf("%w: %q is taken by %q", ErrTeamConflict, team.Name, existing.Name)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	created, err := s.Repo.CreateTeam(ctx, team)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Logger.Info().Str("team_id", created.ID).Str("team", created.Name).Msg("Team created")
+	return created, nil
+}
+
+func (s *teamService) GetTeamByID(ctx context.Context, id string) (*model.Team, error) {
+	return s.Repo.GetTeamByID(ctx, id)
+}
+
+func (s *teamService) GetAllTeams(ctx context.Context) ([]*model.Team, error) {
+	return s.Repo.GetAllTeams(ctx)
+}
+
+// UpdateTeam replaces the team with req. A rename is carried over to the
+// team's incidents by the database.
+func (s *teamService) UpdateTeam(ctx context.Context, id string, req model.TeamRequest) (*model.Team, error) {
+	team, err := teamFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	team.ID = id
+
+	existing, err := s.Repo.GetTeamBySlug(ctx, team.Slug)
+	if err == nil && existing.ID != id {
+		return nil, fmt.Errorf("%w: %q is taken by %q", ErrTeamConflict, team.Name, existing.Name)
+	} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	updated, err := s.Repo.UpdateTeam(ctx, team)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Logger.Info().Str("team_id", updated.ID).Str("team", updated.Name).Msg("Team updated")
+	return updated, nil
+}
+
+// DeleteTeam removes a team that owns no incidents. Memberships go with it.
+func (s *teamService) DeleteTeam(ctx context.Context, id string) error {
+	inUse, err := s.Repo.TeamInUse(ctx, id)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return ErrTeamInUse
+	}
+	return s.Repo.DeleteTeam(ctx, id)
+}
+
+func (s *teamService) GetMembers(ctx context.Context, teamID string) ([]*model.User, error) {
+	if _, err := s.Repo.GetTeamByID(ctx, teamID); err != nil {
+		return nil, err
+	}
+	return s.Repo.GetMembers(ctx, teamID)
+}
+
+func (s *teamService) AddMember(ctx context.Context, teamID string, userID string) error {
+	if _, err := s.Repo.GetTeamByID(ctx, teamID); err != nil {
+		return err
+	}
+	if _, err := s.UserRepo.GetUserByID(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: %s", ErrUnknownUser, userID)
+		}
+		return err
+	}
+	return s.Repo.AddMember(ctx, teamID, userID)
+}
+
+func (s *teamService) RemoveMember(ctx context.Context, teamID string, userID string) error {
+	return s.Repo.RemoveMember(ctx, teamID, userID)
+}
+
+func teamFromRequest(req model.TeamRequest) (*model.Team, error) {
+	name := strings.TrimSpace(req.Name)
+	slug := teamSlug(name)
+	if slug == "" {
+		return nil, fmt.Errorf("%w: name must contain a letter or digit", ErrInvalidTeam)
+	}
+	return &model.Team{Name: name, Slug: slug, Description: req.Description}, nil
+}
+
+// teamSlug reduces a team name to its lower-cased letters and digits, so that
+// "DevOps", "devops" and "Dev Ops" all name the same team. The migration that
+// introduced teams applies the same rule in SQL.
+func teamSlug(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// resolveTeam returns the team a free-text team name refers to
+func resolveTeam(ctx context.Context, repo repository.TeamRepository, name string) (*model.Team, error) {
+	slug := teamSlug(name)
+	if slug == "" {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTeam, name)
+	}
+	team, err := repo.GetTeamBySlug(ctx, slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTeam, name)
+	}
+	return team, err
+}
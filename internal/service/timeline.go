@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+	"github.com/hascho/go-incident-dashboard-api/internal/repository"
+	"github.com/rs/zerolog"
+)
+
+type TimelineService interface {
+	GetTimeline(ctx context.Context, incidentID string) ([]*model.IncidentEvent, error)
+	AddNote(ctx context.Context, incidentID string, actor string, message string) (*model.IncidentEvent, error)
+}
+
+type timelineService struct {
+	IncidentRepo repository.IncidentRepository
+	EventRepo    repository.EventRepository
+	Logger       zerolog.Logger
+}
+
+func NewTimelineService(incidentRepo repository.IncidentRepository, eventRepo repository.EventRepository, logger zerolog.Logger) TimelineService {
+	return &timelineService{
+		IncidentRepo: incidentRepo,
+		EventRepo:    eventRepo,
+		Logger:       logger,
+	}
+}
+
+func (s *timelineService) GetTimeline(ctx context.Context, incidentID string) ([]*model.IncidentEvent, error) {
+	// surface sql.ErrNoRows for unknown incidents instead of an empty timeline
+	if _, err := s.IncidentRepo.GetIncidentByID(ctx, incidentID); err != nil {
+		return nil, err
+	}
+	return s.EventRepo.GetEventsByIncidentID(ctx, incidentID)
+}
+
+func (s *timelineService) AddNote(ctx context.Context, incidentID string, actor string, message string) (*model.IncidentEvent, error) {
+	if _, err := s.IncidentRepo.GetIncidentByID(ctx, incidentID); err != nil {
+		return nil, err
+	}
+
+	event := &model.IncidentEvent{
+		IncidentID: incidentID,
+		Type:       model.EventNote,
+		Actor:      actor,
+		Message:    message,
+	}
+	if err := s.EventRepo.CreateEvent(ctx, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+	"github.com/hascho/go-incident-dashboard-api/internal/repository"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrInvalidUser  = errors.New("invalid user")
+	ErrUserConflict = errors.New("user already exists")
+)
+
+type UserService interface {
+	CreateUser(ctx context.Context, req model.UserRequest) (*model.User, error)
+	GetUserByID(ctx context.Context, id string) (*model.User, error)
+	GetAllUsers(ctx context.Context) ([]*model.User, error)
+	UpdateUser(ctx context.Context, id string, req model.UserRequest) (*model.User, error)
+	DeleteUser(ctx context.Context, id string) error
+}
+
+type userService struct {
+	Repo   repository.UserRepository
+	Logger zerolog.Logger
+}
+
+func NewUserService(repo repository.UserRepository, logger zerolog.Logger) UserService {
+	return &userService{Repo: repo, Logger: logger}
+}
+
+func (s *userService) CreateUser(ctx context.Context, req model.UserRequest) (*model.User, error) {
+	user, err := userFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.Repo.GetUserByName(ctx, user.Name); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUserConflict, user.Name)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	created, err := s.Repo.CreateUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Logger.Info().Str("user_id", created.ID).Str("user", created.Name).Msg("User created")
+	return created, nil
+}
+
+func (s *userService) GetUserByID(ctx context.Context, id string) (*model.User, error) {
+	return s.Repo.GetUserByID(ctx, id)
+}
+
+func (s *userService) GetAllUsers(ctx context.Context) ([]*model.User, error) {
+	return s.Repo.GetAllUsers(ctx)
+}
+
+func (s *userService) UpdateUser(ctx context.Context, id string, req model.UserRequest) (*model.User, error) {
+	user, err := userFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	user.ID = id
+
+	existing, err := s.Repo.GetUserByName(ctx, user.Name)
+	if err == nil && existing.ID != id {
+		return nil, fmt.Errorf("%w: %s", ErrUserConflict, user.Name)
+	} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	updated, err := s.Repo.UpdateUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Logger.Info().Str("user_id", updated.ID).Str("user", updated.Name).Msg("User updated")
+	return updated, nil
+}
+
+// DeleteUser removes a user and their team memberships. Incidents they
+// responded to keep the recorded names.
+func (s *userService) DeleteUser(ctx context.Context, id string) error {
+	return s.Repo.DeleteUser(ctx, id)
+}
+
+func userFromRequest(req model.UserRequest) (*model.User, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name cannot be empty", ErrInvalidUser)
+	}
+	for _, method := range req.ContactMethods {
+		if !slices.Contains(routableChannels, method.Channel) {
+			return nil, fmt.Errorf("%w: unsupported contact channel %q", ErrInvalidUser, method.Channel)
+		}
+	}
+	return &model.User{
+		Name:           name,
+		Email:          strings.TrimSpace(req.Email),
+		ContactMethods: req.ContactMethods,
+	}, nil
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+	"github.com/hascho/go-incident-dashboard-api/internal/repository"
+	"github.com/rs/zerolog"
+)
+
+var ErrInvalidWebhook = errors.New("invalid webhook subscription")
+
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, req model.CreateWebhookRequest) (*model.WebhookSubscription, error)
+	GetWebhookByID(ctx context.Context, id string) (*model.WebhookSubscription, error)
+	GetAllWebhooks(ctx context.Context) ([]*model.WebhookSubscription, error)
+	DeleteWebhook(ctx context.Context, id string) error
+}
+
+type webhookService struct {
+	Repo   repository.WebhookRepository
+	Logger zerolog.Logger
+}
+
+func NewWebhookService(repo repository.WebhookRepository, logger zerolog.Logger) WebhookService {
+	return &webhookService{
+		Repo:   repo,
+		Logger: logger,
+	}
+}
+
+// CreateWebhook registers a subscription. The secret is returned only here;
+// later reads omit it.
+func (s *webhookService) CreateWebhook(ctx context.Context, req model.CreateWebhookRequest) (*model.WebhookSubscription, error) {
+	if err := validateWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+	for _, event := range req.Events {
+		if !slices.Contains(model.IncidentEventTypes, event) {
+			return nil, fmt.Errorf("%w: unknown event type %q", ErrInvalidWebhook, event)
+		}
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = generateWebhookSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	created, err := s.Repo.CreateWebhook(ctx, &model.WebhookSubscription{
+		URL:    req.URL,
+		Secret: secret,
+		Events: req.Events,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.Logger.Info().Str("webhook_id", created.ID).Str("url", created.URL).Msg("Webhook subscription created")
+	return created, nil
+}
+
+func (s *webhookService) GetWebhookByID(ctx context.Context, id string) (*model.WebhookSubscription, error) {
+	webhook, err := s.Repo.GetWebhookByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+func (s *webhookService) GetAllWebhooks(ctx context.Context) ([]*model.WebhookSubscription, error) {
+	webhooks, err := s.Repo.GetAllWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, webhook := range webhooks {
+		webhook.Secret = ""
+	}
+	return webhooks, nil
+}
+
+func (s *webhookService) DeleteWebhook(ctx context.Context, id string) error {
+	return s.Repo.DeleteWebhook(ctx, id)
+}
+
+// validateWebhookURL only accepts HTTPS endpoints. Plain HTTP is allowed for
+// loopback hosts so local receivers can be used during development.
+func validateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("%w: %q is not an absolute URL", ErrInvalidWebhook, raw)
+	}
+
+	switch u.Scheme {
+	case "https":
+		return nil
+	case "http":
+		host := u.Hostname()
+		if ip := net.ParseIP(host); host == "localhost" || (ip != nil && ip.IsLoopback()) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: URL must use https", ErrInvalidWebhook)
+}
+
+func generateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
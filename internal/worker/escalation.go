@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/service"
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultEscalationInterval = 30 * time.Second // how often due escalations are checked
+	escalationBatchSize       = 20
+)
+
+// EscalationScheduler periodically pages the next level of unacknowledged
+// incidents. Every worker replica runs one; the service's row locking keeps
+// them from paging the same level twice.
+type EscalationScheduler struct {
+	Service  service.EscalationService
+	Logger   zerolog.Logger
+	Interval time.Duration
+}
+
+func NewEscalationScheduler(svc service.EscalationService, logger zerolog.Logger) *EscalationScheduler {
+	return &EscalationScheduler{
+		Service:  svc,
+		Logger:   logger,
+		Interval: defaultEscalationInterval,
+	}
+}
+
+func (s *EscalationScheduler) Start(ctx context.Context) {
+	s.Logger.Info().Dur("interval", s.Interval).Msg("Escalation scheduler started")
+
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce works through every escalation that is currently due
+func (s *EscalationScheduler) RunOnce(ctx context.Context) {
+	for {
+		processed, err := s.Service.EscalateDue(ctx, escalationBatchSize)
+		if err != nil {
+			s.Logger.Error().Err(err).Msg("Failed to process due escalations")
+			return
+		}
+		if processed > 0 {
+			s.Logger.Info().Int("count", processed).Msg("Processed due escalations")
+		}
+		if processed < escalationBatchSize {
+			return
+		}
+	}
+}
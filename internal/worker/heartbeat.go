@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/service"
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultHeartbeatInterval = 30 * time.Second // how often overdue heartbeats are checked
+	heartbeatBatchSize       = 20
+)
+
+// HeartbeatChecker periodically opens incidents for heartbeats that missed
+// their ping. Every worker replica runs one; claiming in the service keeps
+// them from opening the same incident twice.
+type HeartbeatChecker struct {
+	Service  service.HeartbeatService
+	Logger   zerolog.Logger
+	Interval time.Duration
+}
+
+func NewHeartbeatChecker(svc service.HeartbeatService, logger zerolog.Logger) *HeartbeatChecker {
+	return &HeartbeatChecker{
+		Service:  svc,
+		Logger:   logger,
+		Interval: defaultHeartbeatInterval,
+	}
+}
+
+func (c *HeartbeatChecker) Start(ctx context.Context) {
+	c.Logger.Info().Dur("interval", c.Interval).Msg("Heartbeat checker started")
+
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce works through every heartbeat that is currently overdue
+func (c *HeartbeatChecker) RunOnce(ctx context.Context) {
+	for {
+		processed, err := c.Service.CheckOverdue(ctx, heartbeatBatchSize)
+		if err != nil {
+			c.Logger.Error().Err(err).Msg("Failed to check overdue heartbeats")
+			return
+		}
+		if processed > 0 {
+			c.Logger.Info().Int("count", processed).Msg("Processed overdue heartbeats")
+		}
+		if processed < heartbeatBatchSize {
+			return
+		}
+	}
+}
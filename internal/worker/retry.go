@@ -0,0 +1,66 @@
+package worker
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/repository"
+)
+
+// RetryPolicy controls how often and how quickly a failed job is retried.
+type RetryPolicy struct {
+	MaxAttempts int           // total attempts before the job is dead-lettered
+	BaseDelay   time.Duration // delay before the first retry
+	MaxDelay    time.Duration // upper bound for the exponential delay
+	Jitter      float64       // fraction (0-1) of the delay that is randomised
+}
+
+// DefaultRetryPolicy applies to job types without an explicit policy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   30 * time.Second,
+	MaxDelay:    30 * time.Minute,
+	Jitter:      0.2,
+}
+
+// DefaultRetryPolicies returns the built-in policy per job type.
+func DefaultRetryPolicies() map[string]RetryPolicy {
+	return map[string]RetryPolicy{
+		repository.JobTypeNotification: DefaultRetryPolicy,
+		// subscribers may be down for a while, so deliveries keep trying for about a day
+		repository.JobTypeWebhook: {
+			MaxAttempts: 12,
+			BaseDelay:   time.Minute,
+			MaxDelay:    6 * time.Hour,
+			Jitter:      0.2,
+		},
+	}
+}
+
+// Backoff returns the delay before the next attempt, given how many attempts
+// have already failed. The delay doubles per attempt up to MaxDelay, and up to
+// Jitter of it is randomised so retrying workers do not stampede a provider.
+func (p RetryPolicy) Backoff(failedAttempts int) time.Duration {
+	if failedAttempts < 1 {
+		failedAttempts = 1
+	}
+
+	delay := float64(p.BaseDelay) * math.Pow(2, float64(failedAttempts-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+
+	jitter := math.Min(math.Max(p.Jitter, 0), 1)
+	delay -= delay * jitter * rand.Float64()
+
+	return time.Duration(delay)
+}
+
+// policyFor looks up the retry policy for a job type, falling back to the default.
+func (w *NotificationWorker) policyFor(jobType string) RetryPolicy {
+	if policy, ok := w.RetryPolicies[jobType]; ok {
+		return policy
+	}
+	return DefaultRetryPolicy
+}
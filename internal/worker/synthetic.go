@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/hascho/go-incident-dashboard-api/internal/service"
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultSyntheticCheckInterval = 5 * time.Second // how often due synthetic checks are looked for
+	syntheticCheckBatchSize       = 20
+)
+
+// SyntheticCheckScheduler periodically runs the synthetic checks that are
+// due. Every worker replica runs one; claiming in the service keeps them from
+// running the same check twice.
+type SyntheticCheckScheduler struct {
+	Service  service.SyntheticCheckService
+	Logger   zerolog.Logger
+	Interval time.Duration
+}
+
+func NewSyntheticCheckScheduler(svc service.SyntheticCheckService, logger zerolog.Logger) *SyntheticCheckScheduler {
+	return &SyntheticCheckScheduler{
+		Service:  svc,
+		Logger:   logger,
+		Interval: defaultSyntheticCheckInterval,
+	}
+}
+
+func (s *SyntheticCheckScheduler) Start(ctx context.Context) {
+	s.Logger.Info().Dur("interval", s.Interval).Msg("Synthetic check scheduler started")
+
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce runs every synthetic check that is currently due
+func (s *SyntheticCheckScheduler) RunOnce(ctx context.Context) {
+	for {
+		processed, err := s.Service.RunDue(ctx, syntheticCheckBatchSize)
+		if err != nil {
+			s.Logger.Error().Err(err).Msg("Failed to run due synthetic checks")
+			return
+		}
+		if processed > 0 {
+			s.Logger.Debug().Int("count", processed).Msg("Ran synthetic checks")
+		}
+		if processed < syntheticCheckBatchSize {
+			return
+		}
+	}
+}
@@ -3,29 +3,47 @@ package worker
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/hascho/go-incident-dashboard-api/internal/model"
+	"github.com/hascho/go-incident-dashboard-api/internal/notifier"
 	"github.com/hascho/go-incident-dashboard-api/internal/queue"
 	"github.com/hascho/go-incident-dashboard-api/internal/repository"
 	"github.com/rs/zerolog"
 )
 
+const (
+	defaultLeaseDuration = 2 * time.Minute // how long a claimed job is reserved for this worker
+	reaperInterval       = time.Minute     // how often expired leases are returned to the queue
+)
+
 type NotificationWorker struct {
-	JobRepo      repository.JobRepository
-	IncidentRepo repository.IncidentRepository
-	Queue        queue.TaskQueue
-	Logger       zerolog.Logger
-	ID           string
+	JobRepo       repository.JobRepository
+	IncidentRepo  repository.IncidentRepository
+	EventRepo     repository.EventRepository
+	AttemptRepo   repository.AttemptRepository
+	Notifiers     *notifier.Registry
+	Queue         queue.TaskQueue
+	Logger        zerolog.Logger
+	ID            string
+	LeaseTime     time.Duration
+	RetryPolicies map[string]RetryPolicy // keyed by job type
 }
 
-func NewNotificationWorker(jobRepo repository.JobRepository, incidentRepo repository.IncidentRepository, q queue.TaskQueue, logger zerolog.Logger, id string) *NotificationWorker {
+func NewNotificationWorker(jobRepo repository.JobRepository, incidentRepo repository.IncidentRepository, eventRepo repository.EventRepository, attemptRepo repository.AttemptRepository, notifiers *notifier.Registry, q queue.TaskQueue, logger zerolog.Logger, id string, retryPolicies map[string]RetryPolicy) *NotificationWorker {
 	return &NotificationWorker{
-		JobRepo:      jobRepo,
-		IncidentRepo: incidentRepo,
-		Queue:        q,
-		Logger:       logger,
-		ID:           id,
+		JobRepo:       jobRepo,
+		IncidentRepo:  incidentRepo,
+		EventRepo:     eventRepo,
+		AttemptRepo:   attemptRepo,
+		Notifiers:     notifiers,
+		Queue:         q,
+		Logger:        logger,
+		ID:            id,
+		LeaseTime:     defaultLeaseDuration,
+		RetryPolicies: retryPolicies,
 	}
 }
 
@@ -38,27 +56,34 @@ func (w *NotificationWorker) Start(ctx context.Context) {
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
 
+	// Lease Reaper
+	reaper := time.NewTicker(reaperInterval)
+	defer reaper.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
 			return
 
-		case incidentID := <-redisChan:
-			w.Logger.Info().Str("incident_id", incidentID).Msg("Received instant signal from Redis")
+		case jobID := <-redisChan:
+			w.Logger.Info().Str("job_id", jobID).Msg("Received instant signal from Redis")
 			// When we get a signal, we process everything pending
 			w.ProcessNextBatch(ctx)
 
 		case <-ticker.C:
 			w.Logger.Debug().Msg("Running scheduled safety poll...")
 			w.ProcessNextBatch(ctx)
+
+		case <-reaper.C:
+			w.ReapExpiredLeases(ctx)
 		}
 	}
 }
 
 func (w *NotificationWorker) ProcessNextBatch(ctx context.Context) {
-	jobs, err := w.JobRepo.FetchPendingJobs(ctx, 5)
+	jobs, err := w.JobRepo.ClaimJobs(ctx, w.ID, 5, w.LeaseTime)
 	if err != nil {
-		w.Logger.Error().Err(err).Msg("Worker failed to fetch jobs")
+		w.Logger.Error().Err(err).Msg("Worker failed to claim jobs")
 		return
 	}
 
@@ -66,7 +91,7 @@ func (w *NotificationWorker) ProcessNextBatch(ctx context.Context) {
 		return
 	}
 
-	w.Logger.Info().Int("count", len(jobs)).Msg("Worker found pending jobs")
+	w.Logger.Info().Int("count", len(jobs)).Msg("Worker claimed pending jobs")
 
 	for _, job := range jobs {
 		w.processJob(ctx, job)
@@ -76,46 +101,169 @@ func (w *NotificationWorker) ProcessNextBatch(ctx context.Context) {
 func (w *NotificationWorker) processJob(ctx context.Context, job *repository.Job) {
 	w.Logger.Info().Interface("job_id", job.ID).Msg("Processing job...")
 
-	err := w.sendNotification(job)
+	policy := w.policyFor(job.Type)
+
+	// attempts abandoned by crashed workers are counted by the reaper, so a job
+	// can arrive here with its attempts already used up
+	if job.Retries >= policy.MaxAttempts {
+		w.Logger.Error().Interface("job_id", job.ID).Int("attempts", job.Retries).Msg("Job exhausted its attempts without completing; dead-lettering")
+		if err := w.JobRepo.UpdateJobStatus(ctx, job.ID, w.ID, repository.JobStatusPermanentlyFailed); err != nil {
+			w.Logger.Error().Err(err).Interface("job_id", job.ID).Msg("Failed to dead-letter exhausted job")
+			return
+		}
+		w.markNotificationFailed(ctx, job)
+		return
+	}
+
+	startedAt := time.Now()
+	responseCode, err := w.sendNotification(ctx, job)
+	w.recordAttempt(ctx, job, startedAt, responseCode, err)
 	if err != nil {
 		w.Logger.Warn().Err(err).Interface("job_id", job.ID).Msg("Notification failed, attempting retry logic")
 
 		// This will increment retries and move status to FAILED or PERMANENTLY_FAILED
-		retryErr := w.JobRepo.FailJobWithRetry(ctx, job.ID, 3)
+		maxAttempts := policy.MaxAttempts
+		if errors.Is(err, notifier.ErrPermanent) {
+			maxAttempts = 0 // retrying cannot help, dead-letter on this attempt
+		}
+		nextAttemptAt := time.Now().Add(policy.Backoff(job.Retries + 1))
+		status, retryErr := w.JobRepo.FailJobWithRetry(ctx, job.ID, w.ID, maxAttempts, nextAttemptAt, err.Error())
+		if errors.Is(retryErr, repository.ErrLeaseLost) {
+			w.Logger.Warn().Interface("job_id", job.ID).Msg("Lease expired before failure was recorded; job was returned to the queue")
+			return
+		}
 		if retryErr != nil {
 			w.Logger.Error().Err(retryErr).Msg("Critical: Could not update failure status in database")
 		}
+
+		w.recordOutcome(ctx, job, model.EventNotificationFailed, fmt.Sprintf("Notification attempt %d failed: %v", job.Retries+1, err))
+
+		if status == repository.JobStatusPermanentlyFailed {
+			w.Logger.Error().Interface("job_id", job.ID).Msg("Job exhausted its attempts and was dead-lettered")
+			w.markNotificationFailed(ctx, job)
+		}
 		return
 	}
 
-	err = w.JobRepo.UpdateJobStatus(ctx, job.ID, "SUCCESS")
+	err = w.JobRepo.UpdateJobStatus(ctx, job.ID, w.ID, repository.JobStatusSuccess)
+	if errors.Is(err, repository.ErrLeaseLost) {
+		w.Logger.Warn().Interface("job_id", job.ID).Msg("Lease expired before success was recorded; job may be delivered again")
+		return
+	}
 	if err != nil {
 		w.Logger.Error().Err(err).Interface("job_id", job.ID).Msg("Failed to update job status to SUCCESS")
 		return
 	}
 
-	err = w.IncidentRepo.UpdateNotificationStatus(ctx, job.IncidentID.String(), "sent")
-	if err != nil {
-		w.Logger.Error().Err(err).Msg("Failed to update incident notification status")
+	if reportsToIncident(job) {
+		err = w.IncidentRepo.UpdateNotificationStatus(ctx, job.IncidentID.String(), model.NotificationSent)
+		if err != nil {
+			w.Logger.Error().Err(err).Msg("Failed to update incident notification status")
+		}
 	}
 
+	w.recordOutcome(ctx, job, model.EventNotificationSent, "Notification delivered")
+
 	w.Logger.Info().
 		Interface("job_id", job.ID).
 		Interface("incident_id", job.IncidentID).
 		Msg("Successfully processed notification and updated incident status")
 }
 
-// Helper to simulate real work that might fail
-func (w *NotificationWorker) sendNotification(job *repository.Job) error {
-	// Simulate processing time
-	time.Sleep(500 * time.Millisecond)
+// ReapExpiredLeases returns jobs abandoned by crashed or stalled workers to the queue
+func (w *NotificationWorker) ReapExpiredLeases(ctx context.Context) {
+	released, err := w.JobRepo.ReleaseExpiredLeases(ctx)
+	if err != nil {
+		w.Logger.Error().Err(err).Msg("Worker failed to release expired job leases")
+		return
+	}
+	if released > 0 {
+		w.Logger.Warn().Int64("count", released).Msg("Released expired job leases back to the queue")
+	}
+}
+
+// reportsToIncident is true for jobs whose outcome is reflected on the incident's
+// notification status and timeline. Webhook deliveries are integrations rather
+// than responder notifications, and deletion events outlive their incident.
+func reportsToIncident(job *repository.Job) bool {
+	return job.Type == repository.JobTypeNotification && job.EventType != model.EventTypeIncidentDeleted
+}
+
+// markNotificationFailed reports a dead-lettered job back onto its incident
+func (w *NotificationWorker) markNotificationFailed(ctx context.Context, job *repository.Job) {
+	if !reportsToIncident(job) {
+		return
+	}
+	if err := w.IncidentRepo.UpdateNotificationStatus(ctx, job.IncidentID.String(), model.NotificationFailed); err != nil {
+		w.Logger.Error().Err(err).Interface("job_id", job.ID).Msg("Failed to update incident notification status")
+	}
+}
+
+// recordAttempt writes one row to the per-attempt delivery log
+func (w *NotificationWorker) recordAttempt(ctx context.Context, job *repository.Job, startedAt time.Time, responseCode int, sendErr error) {
+	finishedAt := time.Now()
+	attempt := &model.NotificationAttempt{
+		JobID:      job.ID.String(),
+		IncidentID: job.IncidentID.String(),
+		WorkerID:   w.ID,
+		Channel:    job.Channel,
+		Attempt:    job.Retries + 1,
+		Success:    sendErr == nil,
+		StartedAt:  startedAt,
+		FinishedAt: finishedAt,
+		DurationMS: finishedAt.Sub(startedAt).Milliseconds(),
+	}
+	if sendErr != nil {
+		attempt.ErrorMessage = sendErr.Error()
+	}
+	// 0 means the channel produced no provider response
+	if responseCode != 0 {
+		attempt.ResponseCode = &responseCode
+	}
+
+	if err := w.AttemptRepo.CreateAttempt(ctx, attempt); err != nil {
+		w.Logger.Error().Err(err).Interface("job_id", job.ID).Msg("Failed to record notification attempt")
+	}
+}
+
+// recordOutcome appends the result of a job attempt to the incident timeline
+func (w *NotificationWorker) recordOutcome(ctx context.Context, job *repository.Job, eventType string, message string) {
+	if !reportsToIncident(job) {
+		return
+	}
+	metadata, _ := json.Marshal(map[string]any{"job_id": job.ID, "attempt": job.Retries + 1})
+	event := &model.IncidentEvent{
+		IncidentID: job.IncidentID.String(),
+		Type:       eventType,
+		Actor:      w.ID,
+		Message:    message,
+		Metadata:   metadata,
+	}
+	if err := w.EventRepo.CreateEvent(ctx, event); err != nil {
+		w.Logger.Error().Err(err).Interface("job_id", job.ID).Msg("Failed to record notification outcome on timeline")
+	}
+}
 
-	var payload map[string]interface{}
-	importJsonErr := json.Unmarshal(job.Payload, &payload)
+// sendNotification delivers the job through the notifier registered for its
+// channel. It returns the provider's response code, or 0 when there is none.
+func (w *NotificationWorker) sendNotification(ctx context.Context, job *repository.Job) (int, error) {
+	n, err := w.Notifiers.Get(job.Channel)
+	if err != nil {
+		return 0, err
+	}
 
-	if importJsonErr == nil && payload["title"] == "FAIL" {
-		return fmt.Errorf("simulated provider downtime")
+	incident := &model.Incident{}
+	if err := json.Unmarshal(job.Payload, incident); err != nil {
+		return 0, fmt.Errorf("failed to decode job payload: %w", err)
 	}
 
-	return nil
+	result, err := n.Send(ctx, notifier.Notification{
+		JobID:       job.ID.String(),
+		EventType:   job.EventType,
+		Incident:    incident,
+		Changes:     job.Changes,
+		Destination: job.Destination,
+		CreatedAt:   job.CreatedAt,
+	})
+	return result.StatusCode, err
 }